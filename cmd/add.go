@@ -8,6 +8,7 @@ package cmd
 import (
 	"encoding/json"
 	"fmt"
+	"strings"
 
 	multierror "github.com/hashicorp/go-multierror"
 	"github.com/shadowblip/steam-shortcut-manager/pkg/chimera"
@@ -21,57 +22,100 @@ import (
 var addCmd = &cobra.Command{
 	Use:   "add <name> <exe>",
 	Short: "Add a Steam shortcut to your steam library",
-	Args:  cobra.ExactArgs(2),
-	Long:  `Adds a Steam shortcut to your library`,
+	Args:  cobra.RangeArgs(1, 2),
+	Long: `Adds a Steam shortcut to your library.
+
+With --from-installed, the name, icon, and start directory of the shortcut
+are filled in from the given installed Steam game, so only the executable
+has to be given:
+
+  steam-shortcut-manager add --from-installed 400 /usr/bin/steam`,
 	Run: func(cmd *cobra.Command, args []string) {
 		format := rootCmd.PersistentFlags().Lookup("output").Value.String()
-		name := args[0]
-		exe := args[1]
 		var errors error
 
-		// Fetch all users
-		users, err := steam.GetUsers()
-		if err != nil {
-			ExitError(err, format)
+		// Look up the installed Steam game to prefill the shortcut from
+		var installed *steam.AppManifest
+		if appID, _ := cmd.Flags().GetUint64("from-installed"); appID != 0 {
+			var err error
+			installed, err = steam.GetInstalledApp(appID)
+			if err != nil {
+				ExitError(err, format)
+			}
+			DebugPrintln("Found installed app:", installed.Name, "in", installed.InstallDir)
 		}
 
-		// Check to see if we're fetching for just one user
-		onlyForUser := cmd.Flags().Lookup("user").Value.String()
+		var name, exe string
+		switch {
+		case len(args) == 2:
+			name, exe = args[0], args[1]
+		case installed != nil:
+			name, exe = installed.Name, args[0]
+		default:
+			cmd.Help()
+			ExitError(usageErrorf("a name and executable are required unless --from-installed is given"), format)
+		}
 
-		// Fetch all shortcuts
-		for _, user := range users {
-			if onlyForUser != "all" && onlyForUser != user {
-				continue
+		// Create a SteamGridDB client if we need to download images
+		var client *steamgriddb.Client
+		if download, _ := cmd.Flags().GetBool("download-images"); download {
+			if cmd.Flags().Changed("shortcuts-file") {
+				ExitError(usageErrorf("--download-images cannot be used with --shortcuts-file"), format)
 			}
+			client = newGridDBClient(cmd, format)
+		}
+
+		// Determine what to do with an existing shortcut
+		ifExists, _ := cmd.Flags().GetString("if-exists")
+		switch ifExists {
+		case "skip", "update", "duplicate":
+		default:
+			ExitError(usageErrorf("invalid --if-exists value '%s' (valid values: skip, update, duplicate)", ifExists), format)
+		}
 
-			shortcutsPath, _ := steam.GetShortcutsPath(user)
-			hasShortcuts := steam.HasShortcuts(user)
+		// Fetch all shortcuts
+		for _, target := range getShortcutsTargets(cmd, format, steam.GetUsers) {
+			user, shortcutsPath := target.User, target.Path
 
 			// Load existing shortcuts or create empty one
-			var shortcuts *shortcut.Shortcuts
-			if hasShortcuts {
-				shortcuts, err = shortcut.Load(shortcutsPath)
-				if err != nil {
-					ExitError(err, format)
-				}
-			} else {
-				// Create empty shortcuts structure
-				DebugPrintln("Creating new shortcuts file for user:", user)
-				shortcuts = shortcut.NewShortcuts()
+			shortcuts, err := shortcut.LoadOrNew(shortcutsPath)
+			if err != nil {
+				ExitError(err, format)
 			}
 
 			// Generate a new shortcut from the cli flags
 			newShortcut := newShortcutFromFlags(cmd, name, exe)
-			// Download images for the user if specified
-			if download, _ := cmd.Flags().GetBool("download-images"); download {
-				// Check that we have an API key
-				apiKey, _ := cmd.Flags().GetString("api-key")
-				if apiKey == "" {
-					ExitError(fmt.Errorf("no API key specified"), format)
+			if installed != nil {
+				prefillFromInstalled(cmd, newShortcut, installed)
+			}
+			if force, _ := cmd.Flags().GetBool("force"); !force {
+				if err := newShortcut.Validate(getLibrarySearchDirs()...); err != nil {
+					ExitError(fmt.Errorf("invalid shortcut: %w", err), format)
+				}
+			}
+
+			// Look for an existing shortcut with the same name or app ID
+			var existing *shortcut.Shortcut
+			if ifExists != "duplicate" {
+				if key, exists := shortcuts.FindKey(newShortcut.AppName, newShortcut.Appid); exists {
+					sc := shortcuts.Shortcuts[key]
+					existing = &sc
 				}
+			}
+			if existing != nil && ifExists == "skip" {
+				DebugPrintln("Shortcut already exists for", target.Describe()+", skipping")
+				continue
+			}
+			if existing != nil {
+				// Keep the existing app ID so artwork stays attached
+				newShortcut.Appid = existing.Appid
+			}
+
+			// Download images for the user if specified
+			if client != nil {
 				DebugPrintln("Downloading images for shortcut")
-				client := steamgriddb.NewClient(apiKey)
-				downloaded, err := downloadImages(client, user, newShortcut)
+				refreshMatch, _ := cmd.Flags().GetBool("refresh-match")
+				downloaded, err := downloadImages(client, user, newShortcut, refreshMatch)
 				if err != nil {
 					DebugPrintln("Error downloading images:", err)
 					errors = multierror.Append(errors, err)
@@ -88,13 +132,24 @@ var addCmd = &cobra.Command{
 			}
 
 			// Write the changes
-			DebugPrintln("Adding shortcut")
-			shortcuts.Add(newShortcut)
+			if existing != nil {
+				DebugPrintln("Updating existing shortcut")
+				shortcuts.UpdateByID(existing.Appid, func(sc *shortcut.Shortcut) {
+					lastPlayTime := sc.LastPlayTime
+					*sc = *newShortcut
+					sc.LastPlayTime = lastPlayTime
+				})
+			} else {
+				DebugPrintln("Adding shortcut")
+				shortcuts.Add(newShortcut)
+			}
 			err = shortcut.Save(shortcuts, shortcutsPath)
 			if err != nil {
 				ExitError(err, format)
 			}
 		}
+
+		restartSteamIfRequested(cmd, format)
 	},
 }
 
@@ -112,24 +167,42 @@ func newShortcutFromFlags(cmd *cobra.Command, name, exe string) *shortcut.Shortc
 		s.AllowDesktopConfig = getBool("allow-desktop-config")
 		s.AllowOverlay = getBool("allow-overlay")
 		s.FlatpakAppID = getString("flatpak-id")
-		s.IsHidden = getBool("is-hidden")
+		s.IsHidden = getBool("is-hidden") | getBool("hidden")
 		s.LaunchOptions = getString("launch-options")
 		s.OpenVR = getBool("openvr")
 		s.ShortcutPath = getString("shortcut-path")
 		s.StartDir = getString("start-dir")
-		s.Appid = int64(shortcut.CalculateAppID(exe, name))
+		if s.StartDir == "" {
+			s.StartDir = shortcut.DefaultStartDir(exe)
+		}
+		// Steam computes the app ID from the exe path as it is stored,
+		// including its quotes
+		s.Appid = int64(shortcut.CalculateAppID(shortcut.QuotePath(exe), name))
 		s.Icon = getString("icon")
 
 		s.Tags = map[string]interface{}{}
 		tags, _ := cmd.Flags().GetStringSlice("tags")
-		for key, tag := range tags {
+		extraTags, _ := cmd.Flags().GetStringArray("tag")
+		for key, tag := range append(tags, extraTags...) {
 			s.Tags[fmt.Sprintf("%v", key)] = tag
 		}
 	}
 	shortcut := shortcut.NewShortcut(name, exe, shortcutConfiger)
+	shortcut.NormalizePaths()
 	return shortcut
 }
 
+// prefillFromInstalled will fill in the icon and start directory of the given
+// shortcut from an installed Steam game, unless they were given as flags.
+func prefillFromInstalled(cmd *cobra.Command, sc *shortcut.Shortcut, installed *steam.AppManifest) {
+	if !cmd.Flags().Changed("icon") && installed.Icon != "" {
+		sc.Icon = installed.Icon
+	}
+	if !cmd.Flags().Changed("start-dir") && installed.InstallDir != "" {
+		sc.StartDir = shortcut.QuotePath(installed.InstallDir)
+	}
+}
+
 // chimeraAddCmd represents the add command
 var chimeraAddCmd = &cobra.Command{
 	Use:   "add <name> <exe>",
@@ -160,7 +233,7 @@ var chimeraAddCmd = &cobra.Command{
 		switch platform {
 		case "flathub":
 			if id, _ := cmd.Flags().GetString("flatpak-id"); id == "" {
-				ExitError(fmt.Errorf("flatpak-id required for flathub platform"), format)
+				ExitError(usageErrorf("flatpak-id required for flathub platform"), format)
 			}
 		}
 
@@ -178,14 +251,9 @@ var chimeraAddCmd = &cobra.Command{
 		// Download images for the user if specified
 		if download, _ := cmd.Flags().GetBool("download-images"); download {
 			DebugPrintln("Requested to download images for shortcut")
-			// Check that we have an API key
-			apiKey, _ := cmd.Flags().GetString("api-key")
-			if apiKey == "" {
-				ExitError(fmt.Errorf("no API key specified"), format)
-			}
+			client := newGridDBClient(cmd, format)
 
 			// Download the images
-			client := steamgriddb.NewClient(apiKey)
 			downloaded, err := downloadChimeraImages(cmd.Flags(), client, platform, newShortcut)
 			if err != nil {
 				ExitError(err, format)
@@ -206,6 +274,18 @@ var chimeraAddCmd = &cobra.Command{
 			}
 		}
 
+		// Images given on the command line take precedence over downloads
+		for flag, field := range map[string]*string{
+			"poster":     &newShortcut.Poster,
+			"banner":     &newShortcut.Banner,
+			"background": &newShortcut.Background,
+			"logo":       &newShortcut.Logo,
+		} {
+			if value, _ := cmd.Flags().GetString(flag); value != "" {
+				*field = value
+			}
+		}
+
 		// Save the shortcuts
 		shortcuts = append(shortcuts, newShortcut)
 		err = chimera.SaveShortcuts(shortcutsFile, shortcuts)
@@ -218,6 +298,9 @@ var chimeraAddCmd = &cobra.Command{
 		case "term":
 			fmt.Println(newShortcut.Name)
 			fmt.Println("  Executable:", newShortcut.Cmd)
+			fmt.Println("  Directory:", newShortcut.Dir)
+			fmt.Println("  Hidden:", newShortcut.Hidden)
+			fmt.Println("  Tags:", strings.Join(newShortcut.Tags, ", "))
 			fmt.Println("  Poster:", newShortcut.Poster)
 			fmt.Println("  Banner:", newShortcut.Banner)
 			fmt.Println("  Logo:", newShortcut.Logo)
@@ -229,7 +312,7 @@ var chimeraAddCmd = &cobra.Command{
 			}
 			fmt.Println(string(out))
 		default:
-			panic("unknown output format: " + format)
+			ExitError(usageErrorf("unknown output format: %s", format), format)
 		}
 	},
 }
@@ -246,9 +329,9 @@ func newChimeraShortcutFromFlags(cmd *cobra.Command, name, exe string) *chimera.
 
 		s.Tags = []string{}
 		tags, _ := cmd.Flags().GetStringSlice("tags")
-		for _, tag := range tags {
-			s.Tags = append(s.Tags, tag)
-		}
+		extraTags, _ := cmd.Flags().GetStringArray("tag")
+		s.Tags = append(s.Tags, tags...)
+		s.Tags = append(s.Tags, extraTags...)
 	}
 	shortcut := chimera.NewShortcut(name, exe, shortcutConfiger)
 	return shortcut
@@ -270,25 +353,40 @@ func init() {
 	addCmd.Flags().Bool("allow-desktop-config", true, "Allow desktop config")
 	addCmd.Flags().Bool("allow-overlay", true, "Allow steam overlay")
 	addCmd.Flags().Bool("is-hidden", false, "Whether or not the shortcut is hidden")
+	addCmd.Flags().Bool("hidden", false, "Hide the shortcut from the library (same as --is-hidden)")
 	addCmd.Flags().String("flatpak-id", "", "Flatpak ID of the shortcut")
 	addCmd.Flags().String("launch-options", "", "Launch options for the shortcut")
 	addCmd.Flags().Bool("openvr", false, "Use OpenVR for the shortcut")
 	addCmd.Flags().String("shortcut-path", "", "Path to the shortcut file for this application")
-	addCmd.Flags().String("start-dir", "", "Working directory where the app is started")
+	addCmd.Flags().String("start-dir", "", "Working directory where the app is started (default is the exe's directory)")
 	addCmd.Flags().String("icon", "", "Path to the icon to use for this application")
 	addCmd.Flags().StringSlice("tags", []string{}, "Comma-separated list of tags")
-	addCmd.Flags().String("user", "all", "Steam user ID to add the shortcut for")
+	addCmd.Flags().StringArray("tag", []string{}, "Tag to add to the shortcut (can be repeated)")
+	addCmd.Flags().String("user", "all", "Steam user ID to add the shortcut for (\"all\", \"current\", or an ID)")
+	addShortcutsFileFlag(addCmd)
+	addCmd.Flags().Uint64("from-installed", 0, "Steam app ID of an installed game to fill in the shortcut's name, icon, and start directory from")
+	addCmd.Flags().Bool("force", false, "Skip validation of the shortcut fields")
+	addCmd.Flags().String("if-exists", "update", "What to do when a shortcut with the same name or app ID exists (skip, update, duplicate)")
+	addRestartFlag(addCmd)
 	addCmd.Flags().StringP("chimera-shortcut", "c", "~/.local/share/chimera/shortcuts/chimera.flathub.yaml", "Optional path to Chimera shortcut config")
 
-	addCmd.Flags().StringP("api-key", "k", "", "SteamGridDB API Key")
+	addCmd.Flags().StringP("api-key", "k", "", "SteamGridDB API Key (default is $"+steamgriddb.APIKeyEnv+")")
+	addCmd.Flags().String("strategy", "first", strategyFlagUsage)
 	addCmd.Flags().BoolP("download-images", "i", false, "Auto-download artwork from SteamGridDB for shortcut (requires SteamGridDB API Key)")
+	addCmd.Flags().Bool("refresh-match", false, "Search SteamGridDB again instead of reusing the stored game match")
 
 	// Chimera add flags
 	chimeraAddCmd.Flags().String("start-dir", "~", "Working directory where the app is started")
 	chimeraAddCmd.Flags().Bool("is-hidden", false, "Whether or not the shortcut is hidden")
 	chimeraAddCmd.Flags().StringSlice("tags", []string{}, "Comma-separated list of tags")
+	chimeraAddCmd.Flags().StringArray("tag", []string{}, "Tag to add to the shortcut (can be repeated)")
 	chimeraAddCmd.Flags().String("flatpak-id", "", "Flatpak ID of the shortcut (if platform 'flathub')")
+	chimeraAddCmd.Flags().String("poster", "", "Path to the poster image")
+	chimeraAddCmd.Flags().String("banner", "", "Path to the banner image")
+	chimeraAddCmd.Flags().String("background", "", "Path to the background image")
+	chimeraAddCmd.Flags().String("logo", "", "Path to the logo image")
 
-	chimeraAddCmd.Flags().StringP("api-key", "k", "", "SteamGridDB API Key")
+	chimeraAddCmd.Flags().StringP("api-key", "k", "", "SteamGridDB API Key (default is $"+steamgriddb.APIKeyEnv+")")
+	chimeraAddCmd.Flags().String("strategy", "first", strategyFlagUsage)
 	chimeraAddCmd.Flags().BoolP("download-images", "i", false, "Auto-download artwork from SteamGridDB for shortcut (requires SteamGridDB API Key)")
 }
@@ -0,0 +1,662 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/shadowblip/steam-shortcut-manager/pkg/logger"
+	"github.com/shadowblip/steam-shortcut-manager/pkg/shortcut"
+	"github.com/shadowblip/steam-shortcut-manager/pkg/steam"
+	"github.com/shadowblip/steam-shortcut-manager/pkg/steamgriddb"
+	"github.com/spf13/cobra"
+	"golang.org/x/sync/errgroup"
+	"gopkg.in/yaml.v3"
+)
+
+// ArtworkManifestEntry is a single entry of an artwork manifest. The target
+// shortcut is given by name or app ID. Artwork is fetched from the given
+// SteamGridDB game, and any explicit image sources override it.
+type ArtworkManifestEntry struct {
+	Name          string `yaml:"name" json:"name"`
+	AppID         uint64 `yaml:"app_id" json:"app_id"`
+	GameID        string `yaml:"game_id" json:"game_id"`
+	SteamAppID    uint64 `yaml:"steam_app_id" json:"steam_app_id"`
+	GridPortrait  string `yaml:"grid_portrait" json:"grid_portrait"`
+	GridLandscape string `yaml:"grid_landscape" json:"grid_landscape"`
+	Hero          string `yaml:"hero" json:"hero"`
+	Logo          string `yaml:"logo" json:"logo"`
+	Icon          string `yaml:"icon" json:"icon"`
+	GridLegacy    string `yaml:"grid_legacy" json:"grid_legacy"`
+}
+
+// target will return a description of the entry's target shortcut
+func (e *ArtworkManifestEntry) target() string {
+	if e.Name != "" {
+		return e.Name
+	}
+	return fmt.Sprintf("%d", e.AppID)
+}
+
+// ArtworkManifestResult is the outcome of applying a single manifest entry
+type ArtworkManifestResult struct {
+	Entry  string              `json:"entry"`
+	AppID  uint64              `json:"appid,omitempty"`
+	Status string              `json:"status"`
+	Error  string              `json:"error,omitempty"`
+	Assets []steam.AssetResult `json:"assets,omitempty"`
+}
+
+// artworkCmd represents the artwork command
+var artworkCmd = &cobra.Command{
+	Use:   "artwork",
+	Short: "Manage Steam shortcut artwork",
+	Long:  `Manage Steam shortcut artwork`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cmd.Help()
+	},
+}
+
+// artworkApplyCmd represents the artwork apply command
+var artworkApplyCmd = &cobra.Command{
+	Use:   "apply --manifest <file>",
+	Short: "Apply artwork to many shortcuts from a manifest",
+	Long: `Applies artwork to every shortcut listed in a YAML or JSON manifest. Each entry
+names its shortcut by "name" or "app_id" and gives a SteamGridDB "game_id" or
+"steam_app_id", explicit image sources ("grid_portrait", "grid_landscape",
+"hero", "logo", "icon", "grid_legacy"), or both.
+
+With --resume, the shortcuts that were applied are recorded in a state file
+kept per manifest, and are skipped when the same manifest is applied again, so
+an interrupted apply picks up where it stopped. Use --force to apply every
+entry again.
+
+Example manifest:
+  - name: Hollow Knight
+    game_id: "1234"
+  - app_id: 3663241086
+    hero: https://cdn2.steamgriddb.com/hero/xxx.png`,
+	Run: func(cmd *cobra.Command, args []string) {
+		format := rootCmd.PersistentFlags().Lookup("output").Value.String()
+
+		// Read the manifest. YAML is a superset of JSON, so both are handled
+		// by the YAML decoder.
+		manifestPath, _ := cmd.Flags().GetString("manifest")
+		if manifestPath == "" {
+			ExitError(usageErrorf("--manifest is required"), format)
+		}
+		data, err := os.ReadFile(manifestPath)
+		if err != nil {
+			ExitError(err, format)
+		}
+		entries := []ArtworkManifestEntry{}
+		if err := yaml.Unmarshal(data, &entries); err != nil {
+			ExitError(usageErrorf("unable to parse manifest %s: %v", manifestPath, err), format)
+		}
+
+		// Index the shortcuts of every user
+		shortcuts, err := loadAllShortcuts()
+		if err != nil {
+			ExitError(err, format)
+		}
+
+		// Load the progress of previous runs of this manifest
+		var state *steam.ApplyState
+		resume, _ := cmd.Flags().GetBool("resume")
+		force, _ := cmd.Flags().GetBool("force")
+		if resume {
+			statePath, err := steam.GetApplyStatePath(data)
+			if err != nil {
+				ExitError(err, format)
+			}
+			state, err = steam.LoadApplyState(statePath)
+			if err != nil {
+				ExitError(fmt.Errorf("unable to load apply state %s: %w", statePath, err), format)
+			}
+			state.Manifest = manifestPath
+			DebugPrintln("Using apply state:", statePath)
+		}
+
+		// Only create a SteamGridDB client if an entry needs one
+		var client *steamgriddb.Client
+		for _, entry := range entries {
+			if entry.GameID != "" || entry.SteamAppID != 0 {
+				client = newGridDBClient(cmd, format)
+				break
+			}
+		}
+
+		// Get artwork options
+		opts := &steam.ArtworkOptions{}
+		opts.UpdateShortcutIcon, _ = cmd.Flags().GetBool("set-icon")
+		opts.StrictDimensions, _ = cmd.Flags().GetBool("strict-dimensions")
+		opts.Method = getArtworkMethodFlag(cmd, format)
+		concurrency, _ := cmd.Flags().GetInt("concurrency")
+		if concurrency <= 0 {
+			concurrency = steam.DefaultConcurrency
+		}
+
+		// Apply the entries in parallel
+		results := make([]ArtworkManifestResult, len(entries))
+		var mu sync.Mutex
+		failed := false
+		group := new(errgroup.Group)
+		group.SetLimit(concurrency)
+		for i, entry := range entries {
+			i, entry := i, entry
+
+			// Skip the entries that a previous run already applied
+			if state != nil && !force {
+				target := findManifestTarget(shortcuts, &entry)
+				if target != nil && state.IsCompleted(uint64(uint32(target.Shortcut.Appid))) {
+					results[i] = ArtworkManifestResult{
+						Entry:  entry.target(),
+						AppID:  uint64(uint32(target.Shortcut.Appid)),
+						Status: "skipped",
+					}
+					continue
+				}
+			}
+
+			group.Go(func() error {
+				result := applyManifestEntry(cmd, client, shortcuts, &entry, opts)
+				mu.Lock()
+				defer mu.Unlock()
+				results[i] = result
+				if result.Status == "failed" {
+					failed = true
+				}
+
+				// Record the progress right away, so it survives an
+				// interrupted run
+				if state != nil && result.Status == "ok" {
+					state.MarkCompleted(result.AppID)
+					if err := state.Save(); err != nil {
+						logger.Warningf("Unable to save apply state %s: %v", state.Path(), err)
+					}
+				}
+				return nil
+			})
+		}
+		group.Wait()
+		var failedErr error
+		if failed {
+			failedErr = fmt.Errorf("one or more manifest entries failed")
+		}
+
+		// Print the output
+		switch format {
+		case "term":
+			for _, result := range results {
+				if result.Error != "" {
+					fmt.Printf("%v: %v: %v\n", result.Entry, result.Status, result.Error)
+				} else {
+					fmt.Printf("%v: %v\n", result.Entry, result.Status)
+				}
+				for _, asset := range result.Assets {
+					if asset.Err != nil {
+						fmt.Printf("  %s: FAILED: %v\n", asset.AssetType, asset.Err)
+						continue
+					}
+					fmt.Printf("  %s: applied via %s\n", asset.AssetType, asset.Method)
+				}
+			}
+			if state != nil {
+				counts := map[string]int{}
+				for _, result := range results {
+					counts[result.Status]++
+				}
+				fmt.Printf("Applied %d, skipped %d applied by a previous run, %d not applied\n",
+					counts["ok"], counts["skipped"], len(results)-counts["ok"]-counts["skipped"])
+			}
+		case "json":
+			out, err := marshalJSONResults(results, failedErr)
+			if err != nil {
+				ExitError(err, format)
+			}
+			fmt.Println(string(out))
+		case "yaml":
+			out, err := marshalYAML(results)
+			if err != nil {
+				ExitError(err, format)
+			}
+			fmt.Print(string(out))
+		default:
+			ExitError(usageErrorf("unknown output format: %s", format), format)
+		}
+
+		if failedErr != nil {
+			exitReportedError(failedErr, format)
+		}
+		restartSteamIfRequested(cmd, format)
+	},
+}
+
+// artworkSetCmd represents the artwork set command
+var artworkSetCmd = &cobra.Command{
+	Use:   "set <name>",
+	Short: "Apply artwork to a shortcut by name",
+	Long: `Applies artwork to the shortcut with the given name. The shortcut is looked up
+across all Steam users and its artwork ID is computed automatically.
+
+Artwork is searched for on SteamGridDB by the shortcut's name, unless explicit
+image sources are given with --grid, --grid-landscape, --hero, --logo or --icon.
+Sources may be URLs or local files.
+
+If more than one shortcut has the given name, use --user or --app-id to pick
+one.
+
+Examples:
+  steam-shortcut-manager artwork set "Hollow Knight"
+  steam-shortcut-manager artwork set "Hollow Knight" --hero ~/hero.png`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		format := rootCmd.PersistentFlags().Lookup("output").Value.String()
+		name := args[0]
+
+		// Find the shortcut
+		onlyForUser := getUserFlag(cmd, format)
+		appID := getAppIDFlag(cmd, format)
+		matches, err := findShortcutsByName(name, onlyForUser, uint64(appID))
+		if err != nil {
+			ExitError(err, format)
+		}
+		if len(matches) == 0 {
+			ExitError(fmt.Errorf("no shortcut found with name '%s'", name), format)
+		}
+		if len(matches) > 1 {
+			found := []string{}
+			for _, match := range matches {
+				found = append(found, fmt.Sprintf("app ID %d of user %s", uint32(match.Shortcut.Appid), match.User))
+			}
+			ExitError(usageErrorf("found %d shortcuts named '%s' (%s), use --user or --app-id to pick one", len(matches), name, strings.Join(found, ", ")), format)
+		}
+		target := matches[0]
+		gridAppID := uint64(uint32(target.Shortcut.Appid))
+
+		// Get artwork options
+		opts := &steam.ArtworkOptions{User: target.User}
+		opts.UpdateShortcutIcon, _ = cmd.Flags().GetBool("set-icon")
+		opts.StrictDimensions, _ = cmd.Flags().GetBool("strict-dimensions")
+		opts.Method = getArtworkMethodFlag(cmd, format)
+
+		// Use the explicit sources if given, otherwise search SteamGridDB
+		artwork := &steam.ArtworkConfig{}
+		artwork.GridPortrait, _ = cmd.Flags().GetString("grid")
+		artwork.GridLandscape, _ = cmd.Flags().GetString("grid-landscape")
+		artwork.HeroImage, _ = cmd.Flags().GetString("hero")
+		artwork.LogoImage, _ = cmd.Flags().GetString("logo")
+		artwork.IconImage, _ = cmd.Flags().GetString("icon")
+		if *artwork == (steam.ArtworkConfig{}) {
+			client := newGridDBClient(cmd, format)
+			refreshMatch, _ := cmd.Flags().GetBool("refresh-match")
+			steamAppID, _ := cmd.Flags().GetUint64("steam-app-id")
+			gameID, err := resolveGameID(client, name, fmt.Sprintf("%d", gridAppID), steamAppID, refreshMatch)
+			if err != nil {
+				ExitError(err, format)
+			}
+			DebugPrintln("Using SteamGridDB game", gameID, "for", name)
+			artwork, err = client.FetchArtworkConfigContext(cmd.Context(), gameID)
+			checkFetchError(artwork, err, format)
+		}
+
+		if format == "term" {
+			fmt.Printf("Applying artwork for %s (AppID %d) of user %s...\n", name, gridAppID, target.User)
+		}
+		applyArtworkAndReport(cmd.Context(), gridAppID, artwork, opts, format)
+		restartSteamIfRequested(cmd, format)
+	},
+}
+
+// artworkInspectCmd represents the artwork inspect command
+var artworkInspectCmd = &cobra.Command{
+	Use:   "inspect <file>...",
+	Short: "Show the format, size and animation of artwork images",
+	Long: `Shows the format, dimensions, frame count and file size of the given artwork
+images, so animated artwork can be checked before it is applied. Images larger
+than Steam accepts are flagged.`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		format := rootCmd.PersistentFlags().Lookup("output").Value.String()
+
+		results := map[string]*steam.ImageInfo{}
+		for _, path := range args {
+			info, err := steam.InspectImage(path)
+			if err != nil {
+				ExitError(fmt.Errorf("unable to inspect %s: %w", path, err), format)
+			}
+			results[path] = info
+		}
+
+		// Print the output
+		switch format {
+		case "term":
+			for _, path := range args {
+				info := results[path]
+				animation := "static"
+				if info.Animated {
+					animation = fmt.Sprintf("animated, %d frames", info.Frames)
+				}
+				fmt.Printf("%s: %s %dx%d, %s, %d bytes\n", path, info.Format, info.Width, info.Height, animation, info.Size)
+				if info.TooLarge() {
+					fmt.Printf("  Larger than Steam's %d byte limit\n", steam.MaxArtworkSize)
+				}
+			}
+		case "json":
+			out, err := json.MarshalIndent(results, "", "  ")
+			if err != nil {
+				ExitError(err, format)
+			}
+			fmt.Println(string(out))
+		case "yaml":
+			out, err := marshalYAML(results)
+			if err != nil {
+				ExitError(err, format)
+			}
+			fmt.Print(string(out))
+		default:
+			ExitError(usageErrorf("unknown output format: %s", format), format)
+		}
+	},
+}
+
+// artworkStatusCmd represents the artwork status command
+var artworkStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show which artwork each shortcut has",
+	Long: `Shows which artwork (portrait, landscape, hero, logo and icon) exists in the
+grid folder for each shortcut, along with the size of each image. Use
+--missing to only list the shortcuts that are missing artwork.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		format := rootCmd.PersistentFlags().Lookup("output").Value.String()
+		onlyForUser := getUserFlag(cmd, format)
+		onlyMissing, _ := cmd.Flags().GetBool("missing")
+
+		users, err := steam.GetUsersWithShortcuts()
+		if err != nil {
+			ExitError(err, format)
+		}
+
+		// Collect the artwork status of every user
+		results := map[string]map[string]steam.AssetPresence{}
+		for _, user := range users {
+			if onlyForUser != "all" && onlyForUser != user {
+				continue
+			}
+			status, err := steam.ArtworkStatus(user)
+			if err != nil {
+				ExitError(err, format)
+			}
+			if onlyMissing {
+				for appID, presence := range status {
+					if len(presence.Missing()) == 0 {
+						delete(status, appID)
+					}
+				}
+			}
+			results[user] = status
+		}
+
+		// Print the output
+		switch format {
+		case "term":
+			sortedUsers := make([]string, 0, len(results))
+			for user := range results {
+				sortedUsers = append(sortedUsers, user)
+			}
+			sort.Strings(sortedUsers)
+			for _, user := range sortedUsers {
+				status := results[user]
+				fmt.Println("User:", user)
+				appIDs := []string{}
+				for appID := range status {
+					appIDs = append(appIDs, appID)
+				}
+				sort.Strings(appIDs)
+				for _, appID := range appIDs {
+					presence := status[appID]
+					fmt.Printf("  %v (%v)\n", presence.AppName, appID)
+					assets := []struct {
+						name string
+						info steam.AssetInfo
+					}{
+						{"Portrait", presence.Portrait},
+						{"Landscape", presence.Landscape},
+						{"Hero", presence.Hero},
+						{"Logo", presence.Logo},
+						{"Icon", presence.Icon},
+					}
+					for _, asset := range assets {
+						fmt.Printf("    %-10s %v\n", asset.name+":", describeAsset(asset.info))
+					}
+				}
+			}
+		case "json":
+			out, err := json.MarshalIndent(results, "", "  ")
+			if err != nil {
+				ExitError(err, format)
+			}
+			fmt.Println(string(out))
+		case "yaml":
+			out, err := marshalYAML(results)
+			if err != nil {
+				ExitError(err, format)
+			}
+			fmt.Print(string(out))
+		default:
+			ExitError(usageErrorf("unknown output format: %s", format), format)
+		}
+	},
+}
+
+// describeAsset will return a short description of the given artwork image
+func describeAsset(info steam.AssetInfo) string {
+	switch {
+	case !info.Present:
+		return "missing"
+	case info.Corrupt:
+		return "corrupt (" + info.Path + ")"
+	case info.Width > 0:
+		return fmt.Sprintf("%dx%d (%s)", info.Width, info.Height, info.Path)
+	}
+	return info.Path
+}
+
+// UserShortcut is a shortcut along with the Steam user that owns it
+type UserShortcut struct {
+	User     string
+	Shortcut shortcut.Shortcut
+}
+
+// findShortcutsByName will return every shortcut with the given name. Only
+// the shortcuts of the given user are searched, unless the user is "all". If
+// the given app ID is not zero, only the shortcut with that app ID matches.
+func findShortcutsByName(name, onlyForUser string, appID uint64) ([]UserShortcut, error) {
+	users, err := steam.GetUsersWithShortcuts()
+	if err != nil {
+		return nil, err
+	}
+
+	matches := []UserShortcut{}
+	for _, user := range users {
+		if onlyForUser != "all" && onlyForUser != user {
+			continue
+		}
+		shortcutsPath, _ := steam.GetShortcutsPath(user)
+		shortcuts, err := shortcut.Load(shortcutsPath)
+		if err != nil {
+			return nil, fmt.Errorf("unable to load shortcuts for user %v: %w", user, err)
+		}
+		for _, key := range shortcuts.Keys() {
+			sc := shortcuts.Shortcuts[key]
+			if sc.AppName != name {
+				continue
+			}
+			if appID != 0 && uint64(uint32(sc.Appid)) != appID {
+				continue
+			}
+			matches = append(matches, UserShortcut{User: user, Shortcut: sc})
+		}
+	}
+
+	return matches, nil
+}
+
+// loadAllShortcuts will return the shortcuts of every Steam user
+func loadAllShortcuts() ([]UserShortcut, error) {
+	users, err := steam.GetUsersWithShortcuts()
+	if err != nil {
+		return nil, err
+	}
+
+	all := []UserShortcut{}
+	for _, user := range users {
+		shortcutsPath, _ := steam.GetShortcutsPath(user)
+		shortcuts, err := shortcut.Load(shortcutsPath)
+		if err != nil {
+			return nil, fmt.Errorf("unable to load shortcuts for user %v: %w", user, err)
+		}
+		for _, key := range shortcuts.Keys() {
+			all = append(all, UserShortcut{User: user, Shortcut: shortcuts.Shortcuts[key]})
+		}
+	}
+
+	return all, nil
+}
+
+// findManifestTarget will return the shortcut the given manifest entry
+// applies to, or nil if there is none
+func findManifestTarget(shortcuts []UserShortcut, entry *ArtworkManifestEntry) *UserShortcut {
+	for i := range shortcuts {
+		sc := &shortcuts[i].Shortcut
+		if (entry.Name != "" && sc.AppName == entry.Name) || (entry.AppID != 0 && uint64(uint32(sc.Appid)) == entry.AppID) {
+			return &shortcuts[i]
+		}
+	}
+	return nil
+}
+
+// applyManifestEntry will resolve the shortcut of the given manifest entry and
+// apply its artwork
+func applyManifestEntry(cmd *cobra.Command, client *steamgriddb.Client, shortcuts []UserShortcut, entry *ArtworkManifestEntry, opts *steam.ArtworkOptions) ArtworkManifestResult {
+	result := ArtworkManifestResult{Entry: entry.target()}
+
+	// Find the target shortcut
+	target := findManifestTarget(shortcuts, entry)
+	if target == nil {
+		result.Status = "not found"
+		result.Error = "no shortcut found"
+		return result
+	}
+	result.AppID = uint64(uint32(target.Shortcut.Appid))
+
+	// Write to the grid folder of the user that owns the shortcut
+	entryOpts := *opts
+	entryOpts.User = target.User
+
+	// Fetch the SteamGridDB artwork, if any. The artwork that was found is
+	// applied even if some lookups failed, but the entry is not ok.
+	artwork := &steam.ArtworkConfig{}
+	var fetchErr error
+	if client != nil && (entry.GameID != "" || entry.SteamAppID != 0) {
+		gameID := entry.GameID
+		if gameID == "" {
+			game, err := client.GetGameBySteamAppID(entry.SteamAppID)
+			if err != nil {
+				result.Status = "failed"
+				result.Error = err.Error()
+				return result
+			}
+			gameID = strconv.Itoa(game.ID)
+		}
+		fetched, err := client.FetchArtworkConfigContext(cmd.Context(), gameID)
+		if fetched == nil {
+			result.Status = "failed"
+			result.Error = err.Error()
+			return result
+		}
+		artwork = fetched
+		fetchErr = err
+	}
+
+	// Explicit sources override the fetched artwork
+	overrides := []struct {
+		source string
+		field  *string
+	}{
+		{entry.GridPortrait, &artwork.GridPortrait},
+		{entry.GridLandscape, &artwork.GridLandscape},
+		{entry.Hero, &artwork.HeroImage},
+		{entry.Logo, &artwork.LogoImage},
+		{entry.Icon, &artwork.IconImage},
+		{entry.GridLegacy, &artwork.GridLegacy},
+	}
+	for _, override := range overrides {
+		if override.source != "" {
+			*override.field = override.source
+		}
+	}
+
+	assets, err := steam.SetArtworkDetailedContext(cmd.Context(), result.AppID, artwork, &entryOpts)
+	if err != nil {
+		result.Status = "failed"
+		result.Error = err.Error()
+		return result
+	}
+	result.Assets = assets
+	result.Status = "ok"
+	for _, asset := range assets {
+		if asset.Err != nil {
+			result.Status = "failed"
+		}
+	}
+	if fetchErr != nil {
+		result.Status = "failed"
+		result.Error = fetchErr.Error()
+	}
+
+	return result
+}
+
+func init() {
+	rootCmd.AddCommand(artworkCmd)
+	artworkCmd.AddCommand(artworkApplyCmd)
+
+	artworkApplyCmd.Flags().String("manifest", "", "YAML or JSON manifest of the artwork to apply (required)")
+	artworkApplyCmd.Flags().StringP("api-key", "k", "", "SteamGridDB API Key (default is $"+steamgriddb.APIKeyEnv+")")
+	artworkApplyCmd.Flags().String("strategy", "first", strategyFlagUsage)
+	artworkApplyCmd.Flags().Int("concurrency", steam.DefaultConcurrency, "Maximum number of manifest entries applied at the same time")
+	artworkApplyCmd.Flags().Bool("resume", false, "Record applied shortcuts and skip the ones a previous run of the same manifest applied")
+	artworkApplyCmd.Flags().Bool("force", false, "With --resume, apply every entry again, including ones that were already applied")
+	artworkApplyCmd.Flags().Bool("set-icon", false, "Also set the shortcut's icon to the applied icon image")
+	artworkApplyCmd.Flags().Bool("strict-dimensions", false, "Refuse to apply grid and hero images with the wrong aspect ratio")
+	addArtworkMethodFlag(artworkApplyCmd)
+	addRestartFlag(artworkApplyCmd)
+
+	artworkCmd.AddCommand(artworkInspectCmd)
+
+	artworkCmd.AddCommand(artworkStatusCmd)
+	artworkStatusCmd.Flags().String("user", "all", "Steam user ID to show the artwork of (\"all\", \"current\", or an ID)")
+	artworkStatusCmd.Flags().Bool("missing", false, "Only list shortcuts that are missing artwork")
+
+	artworkCmd.AddCommand(artworkSetCmd)
+	artworkSetCmd.Flags().String("user", "all", "Steam user ID of the shortcut (\"all\", \"current\", or an ID)")
+	artworkSetCmd.Flags().String("app-id", "", "App ID of the shortcut, if more than one shortcut has the name")
+	artworkSetCmd.Flags().StringP("api-key", "k", "", "SteamGridDB API Key (default is $"+steamgriddb.APIKeyEnv+")")
+	artworkSetCmd.Flags().String("strategy", "first", strategyFlagUsage)
+	artworkSetCmd.Flags().Uint64("steam-app-id", 0, "Steam App ID of the game the shortcut wraps, used for an exact SteamGridDB match")
+	artworkSetCmd.Flags().Bool("refresh-match", false, "Search SteamGridDB again instead of reusing the stored game match")
+	artworkSetCmd.Flags().String("grid", "", "URL or local file for portrait grid image (600x900)")
+	artworkSetCmd.Flags().String("grid-landscape", "", "URL or local file for landscape grid image (920x430)")
+	artworkSetCmd.Flags().String("hero", "", "URL or local file for hero image (1920x620)")
+	artworkSetCmd.Flags().String("logo", "", "URL or local file for logo image")
+	artworkSetCmd.Flags().String("icon", "", "URL or local file for icon image")
+	artworkSetCmd.Flags().Bool("set-icon", false, "Also set the shortcut's icon to the applied icon image")
+	artworkSetCmd.Flags().Bool("strict-dimensions", false, "Refuse to apply grid and hero images with the wrong aspect ratio")
+	addArtworkMethodFlag(artworkSetCmd)
+	addRestartFlag(artworkSetCmd)
+}
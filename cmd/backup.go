@@ -0,0 +1,186 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/shadowblip/steam-shortcut-manager/pkg/steam"
+	"github.com/spf13/cobra"
+)
+
+// RestoreResult is the result of restoring a backup archive
+type RestoreResult struct {
+	Manifest *steam.ArchiveManifest `json:"manifest"`
+	Restored []string               `json:"restored"`
+}
+
+// backupCmd represents the backup command
+var backupCmd = &cobra.Command{
+	Use:   "backup --out <archive.tar.gz>",
+	Short: "Back up the shortcuts and artwork of every user to an archive",
+	Long: `Writes the shortcuts file and grid artwork of each Steam user to a gzipped
+tar archive, along with a manifest of the users and shortcuts it contains.
+Files are stored relative to the Steam directory, so the archive can be
+restored on another machine with the restore command.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		format := rootCmd.PersistentFlags().Lookup("output").Value.String()
+		out, _ := cmd.Flags().GetString("out")
+		if out == "" {
+			ExitError(usageErrorf("--out is required"), format)
+		}
+
+		// Fetch all users
+		users, err := steam.GetUsers()
+		if err != nil {
+			ExitError(err, format)
+		}
+
+		// Check to see if we're backing up just one user
+		onlyForUser := getUserFlag(cmd, format)
+		if onlyForUser != "all" {
+			if !contains(users, onlyForUser) {
+				ExitError(fmt.Errorf("no Steam user found with ID %v", onlyForUser), format)
+			}
+			users = []string{onlyForUser}
+		}
+
+		// Write the archive. A partial archive is removed on failure.
+		file, err := os.Create(out)
+		if err != nil {
+			ExitError(err, format)
+		}
+		manifest, err := steam.WriteArchive(file, users)
+		if err == nil {
+			err = file.Close()
+		}
+		if err != nil {
+			file.Close()
+			os.Remove(out)
+			ExitError(err, format)
+		}
+
+		// Print the output
+		switch format {
+		case "term":
+			fmt.Println("Wrote backup to", out)
+			printArchiveManifest(manifest)
+		case "json":
+			out, err := json.MarshalIndent(manifest, "", "  ")
+			if err != nil {
+				ExitError(err, format)
+			}
+			fmt.Println(string(out))
+		default:
+			ExitError(usageErrorf("unknown output format: %s", format), format)
+		}
+	},
+}
+
+// restoreCmd represents the restore command
+var restoreCmd = &cobra.Command{
+	Use:   "restore <archive.tar.gz>",
+	Short: "Restore the shortcuts and artwork of users from a backup archive",
+	Long: `Restores the shortcuts files and grid artwork stored in an archive written
+by the backup command. Existing shortcuts files are backed up before they
+are replaced.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		format := rootCmd.PersistentFlags().Lookup("output").Value.String()
+		archive := args[0]
+
+		// Read the manifest to show what will be restored
+		manifest := readArchiveManifest(archive, format)
+		onlyForUser := getUserFlag(cmd, format)
+		affected := []string{}
+		for _, user := range manifest.Users {
+			if onlyForUser != "all" && onlyForUser != user.ID {
+				continue
+			}
+			affected = append(affected, fmt.Sprintf("user %s: %d shortcut(s), %d image(s)", user.ID, len(user.Shortcuts), user.Images))
+		}
+		if len(affected) == 0 {
+			ExitError(fmt.Errorf("no matching users found in %s", archive), format)
+		}
+
+		// Restore the archive unless this is a dry run
+		result := &RestoreResult{Manifest: manifest, Restored: []string{}}
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		if !dryRun {
+			confirmChanges(cmd, format, "restore the following from "+archive, affected)
+
+			file, err := os.Open(archive)
+			if err != nil {
+				ExitError(err, format)
+			}
+			defer file.Close()
+			users := []string{}
+			if onlyForUser != "all" {
+				users = append(users, onlyForUser)
+			}
+			result.Restored, err = steam.RestoreArchive(file, users...)
+			if err != nil {
+				ExitError(err, format)
+			}
+		}
+
+		// Print the output
+		switch format {
+		case "term":
+			printArchiveManifest(manifest)
+			if !dryRun {
+				fmt.Printf("Restored %d file(s)\n", len(result.Restored))
+			}
+		case "json":
+			out, err := json.MarshalIndent(result, "", "  ")
+			if err != nil {
+				ExitError(err, format)
+			}
+			fmt.Println(string(out))
+		default:
+			ExitError(usageErrorf("unknown output format: %s", format), format)
+		}
+
+		if !dryRun {
+			restartSteamIfRequested(cmd, format)
+		}
+	},
+}
+
+// readArchiveManifest will read the manifest of the given backup archive
+func readArchiveManifest(archive, format string) *steam.ArchiveManifest {
+	file, err := os.Open(archive)
+	if err != nil {
+		ExitError(err, format)
+	}
+	defer file.Close()
+	manifest, err := steam.ReadArchiveManifest(file)
+	if err != nil {
+		ExitError(err, format)
+	}
+	return manifest
+}
+
+// printArchiveManifest will print the users and shortcuts in the given
+// backup archive manifest
+func printArchiveManifest(manifest *steam.ArchiveManifest) {
+	for _, user := range manifest.Users {
+		fmt.Printf("User %v: %d image(s)\n", user.ID, user.Images)
+		for _, sc := range user.Shortcuts {
+			fmt.Printf("   %v (%v)\n", sc.AppName, sc.AppID)
+		}
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(backupCmd)
+	rootCmd.AddCommand(restoreCmd)
+
+	backupCmd.Flags().String("out", "", "Path of the archive to write (required)")
+	backupCmd.Flags().String("user", "all", "Steam user ID to back up (\"all\", \"current\", or an ID)")
+
+	restoreCmd.Flags().String("user", "all", "Steam user ID to restore (\"all\", \"current\", or an ID)")
+	restoreCmd.Flags().Bool("dry-run", false, "Only list the contents of the archive without restoring it")
+	addYesFlag(restoreCmd)
+	addRestartFlag(restoreCmd)
+}
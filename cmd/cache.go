@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/shadowblip/steam-shortcut-manager/pkg/steam"
+	"github.com/shadowblip/steam-shortcut-manager/pkg/steamgriddb"
+	"github.com/spf13/cobra"
+)
+
+// CacheDirs holds the directories the caches are stored in
+type CacheDirs struct {
+	API    string `json:"api"`
+	Images string `json:"images"`
+}
+
+// cacheCmd represents the cache command
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Manage the SteamGridDB response and artwork caches",
+	Long: `SteamGridDB API responses and downloaded artwork are cached on disk so that
+re-applying the same artwork does not download it again. Use --no-cache on
+any command to bypass the caches.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cmd.Help()
+	},
+}
+
+// cacheDirCmd represents the cache dir command
+var cacheDirCmd = &cobra.Command{
+	Use:   "dir",
+	Short: "Print the cache directories",
+	Long:  `Prints the directories SteamGridDB responses and artwork are cached in`,
+	Run: func(cmd *cobra.Command, args []string) {
+		format := rootCmd.PersistentFlags().Lookup("output").Value.String()
+		dirs := getCacheDirs(format)
+
+		// Print the output
+		switch format {
+		case "term":
+			fmt.Println("API responses:", dirs.API)
+			fmt.Println("Images:       ", dirs.Images)
+		case "json":
+			out, err := json.MarshalIndent(dirs, "", "  ")
+			if err != nil {
+				ExitError(err, format)
+			}
+			fmt.Println(string(out))
+		default:
+			ExitError(usageErrorf("unknown output format: %s", format), format)
+		}
+	},
+}
+
+// cacheClearCmd represents the cache clear command
+var cacheClearCmd = &cobra.Command{
+	Use:   "clear",
+	Short: "Remove all cached SteamGridDB responses and artwork",
+	Long:  `Removes all cached SteamGridDB responses and downloaded artwork`,
+	Run: func(cmd *cobra.Command, args []string) {
+		format := rootCmd.PersistentFlags().Lookup("output").Value.String()
+		dirs := getCacheDirs(format)
+
+		if err := os.RemoveAll(dirs.API); err != nil {
+			ExitError(err, format)
+		}
+		removed, err := steam.ClearImageCache()
+		if err != nil {
+			ExitError(err, format)
+		}
+
+		// Print the output
+		switch format {
+		case "term":
+			fmt.Printf("Cleared the caches (%d images removed)\n", removed)
+		case "json":
+			out, err := json.MarshalIndent(map[string]int{"images": removed}, "", "  ")
+			if err != nil {
+				ExitError(err, format)
+			}
+			fmt.Println(string(out))
+		default:
+			ExitError(usageErrorf("unknown output format: %s", format), format)
+		}
+	},
+}
+
+// getCacheDirs will return the directories the caches are stored in
+func getCacheDirs(format string) *CacheDirs {
+	apiDir, err := steamgriddb.DefaultCacheDir()
+	if err != nil {
+		ExitError(err, format)
+	}
+	imageDir, err := steam.GetImageCacheDir()
+	if err != nil {
+		ExitError(err, format)
+	}
+	return &CacheDirs{API: apiDir, Images: imageDir}
+}
+
+func init() {
+	rootCmd.AddCommand(cacheCmd)
+	cacheCmd.AddCommand(cacheDirCmd)
+	cacheCmd.AddCommand(cacheClearCmd)
+}
@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+// addYesFlag will add the --yes flag used to skip confirmation prompts to the
+// given command.
+func addYesFlag(cmd *cobra.Command) {
+	cmd.Flags().BoolP("yes", "y", false, "Do not ask for confirmation before making destructive changes")
+}
+
+// confirmChanges will ask the user to confirm a destructive change, listing
+// what will be affected, and exit if they decline. The prompt is skipped if
+// --yes was given. Without a terminal to ask on, --yes is required.
+func confirmChanges(cmd *cobra.Command, format, action string, affected []string) {
+	if yes, _ := cmd.Flags().GetBool("yes"); yes {
+		return
+	}
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		ExitError(usageErrorf("refusing to %s without confirmation, use --yes to continue", action), format)
+	}
+
+	fmt.Fprintf(os.Stderr, "This will %s:\n", action)
+	for _, item := range affected {
+		fmt.Fprintln(os.Stderr, "  "+item)
+	}
+	fmt.Fprint(os.Stderr, "Continue? [y/N] ")
+
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return
+	}
+	ExitError(fmt.Errorf("aborted"), format)
+}
@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/shadowblip/steam-shortcut-manager/pkg/shortcut"
+	"github.com/shadowblip/steam-shortcut-manager/pkg/steam"
+	"github.com/spf13/cobra"
+)
+
+// DedupeResult is the result of removing duplicate shortcuts for one user
+type DedupeResult struct {
+	Removed int    `json:"removed"`
+	Backup  string `json:"backup,omitempty"`
+}
+
+// dedupeCmd represents the dedupe command
+var dedupeCmd = &cobra.Command{
+	Use:   "dedupe",
+	Short: "Remove duplicate Steam shortcuts",
+	Long: `Removes shortcuts with the same executable and name, or the same app ID.
+Of each set of duplicates, the shortcut with the most artwork and tags is kept.
+The shortcuts file is backed up before it is changed.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		format := rootCmd.PersistentFlags().Lookup("output").Value.String()
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+		// Fetch all users
+		users, err := steam.GetUsersWithShortcuts()
+		if err != nil {
+			ExitError(err, format)
+		}
+
+		// Check to see if we're fetching for just one user
+		onlyForUser := getUserFlag(cmd, format)
+
+		results := map[string]*DedupeResult{}
+		for _, user := range users {
+			if onlyForUser != "all" && onlyForUser != user {
+				continue
+			}
+
+			shortcutsPath, _ := steam.GetShortcutsPath(user)
+			shortcuts, err := shortcut.Load(shortcutsPath)
+			if err != nil {
+				ExitError(err, format)
+			}
+
+			// Look up the grid artwork of every shortcut, so the duplicate
+			// that owns the artwork is the one that is kept
+			for key, sc := range shortcuts.Shortcuts {
+				sc.Images, _, err = steam.ResolveImages(user, fmt.Sprintf("%v", sc.Appid))
+				if err != nil {
+					ExitError(err, format)
+				}
+				shortcuts.Shortcuts[key] = sc
+			}
+
+			result := &DedupeResult{Removed: shortcuts.Dedupe()}
+			results[user] = result
+			if result.Removed == 0 || dryRun {
+				continue
+			}
+
+			// Back up the shortcuts before writing the changes
+			result.Backup, err = shortcut.Backup(shortcutsPath)
+			if err != nil {
+				ExitError(err, format)
+			}
+			if err := shortcut.Save(shortcuts, shortcutsPath); err != nil {
+				ExitError(err, format)
+			}
+		}
+
+		// Print the output
+		switch format {
+		case "term":
+			for user, result := range results {
+				if dryRun {
+					fmt.Printf("User %v: %d duplicate(s) found\n", user, result.Removed)
+					continue
+				}
+				fmt.Printf("User %v: %d duplicate(s) removed\n", user, result.Removed)
+				if result.Backup != "" {
+					fmt.Println("  Backup:", result.Backup)
+				}
+			}
+		case "json":
+			out, err := json.MarshalIndent(results, "", "  ")
+			if err != nil {
+				ExitError(err, format)
+			}
+			fmt.Println(string(out))
+		default:
+			ExitError(usageErrorf("unknown output format: %s", format), format)
+		}
+
+		if !dryRun {
+			restartSteamIfRequested(cmd, format)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(dedupeCmd)
+
+	dedupeCmd.Flags().String("user", "all", "Steam user ID to remove the duplicates for (\"all\", \"current\", or an ID)")
+	dedupeCmd.Flags().Bool("dry-run", false, "Only report the number of duplicates without removing them")
+	addRestartFlag(dedupeCmd)
+}
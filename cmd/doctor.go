@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/shadowblip/steam-shortcut-manager/pkg/steam"
+	"github.com/spf13/cobra"
+)
+
+// doctorCmd represents the doctor command
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Diagnose problems finding your Steam installation",
+	Long: `Shows which Steam directory is used, which other directories Steam is
+commonly installed to, and which Steam users were found. If no users can be
+found, the likely cause and how to fix it are printed, such as using
+--steam-root for a Flatpak install.
+
+Exits with a non-zero code if a problem was found.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		format := rootCmd.PersistentFlags().Lookup("output").Value.String()
+
+		diag, err := steam.Diagnose()
+		if err != nil {
+			ExitError(err, format)
+		}
+
+		// Print the output
+		switch format {
+		case "term":
+			baseDir := diag.BaseDir
+			if diag.BaseDirError != "" {
+				baseDir = "not found"
+			} else if diag.Override {
+				baseDir += " (from --steam-root or $" + steam.SteamRootEnv + ")"
+			}
+			fmt.Println("Steam directory:", baseDir)
+			if diag.UserDataDir != "" {
+				fmt.Printf("User data:       %v (%v)\n", diag.UserDataDir, existsString(diag.UserDataExists))
+			}
+			fmt.Println("Users:          ", len(diag.Users), diag.Users)
+			fmt.Println("With shortcuts: ", len(diag.UsersWithShortcuts), diag.UsersWithShortcuts)
+			fmt.Println("Checked directories:")
+			for _, candidate := range diag.Candidates {
+				status := existsString(candidate.Exists)
+				if candidate.HasUserData {
+					status = "has userdata"
+				}
+				fmt.Printf("  %v: %v (%v)\n", candidate.Source, candidate.Path, status)
+			}
+			if len(diag.Problems) == 0 {
+				fmt.Println("No problems found")
+			}
+			for _, problem := range diag.Problems {
+				fmt.Println("Problem:", problem)
+			}
+		case "json":
+			out, err := json.MarshalIndent(diag, "", "  ")
+			if err != nil {
+				ExitError(err, format)
+			}
+			fmt.Println(string(out))
+		case "yaml":
+			out, err := marshalYAML(diag)
+			if err != nil {
+				ExitError(err, format)
+			}
+			fmt.Print(string(out))
+		default:
+			ExitError(usageErrorf("unknown output format: %s", format), format)
+		}
+
+		if len(diag.Problems) > 0 {
+			os.Exit(ExitCodeNoSteam)
+		}
+	},
+}
+
+// existsString will describe whether or not a directory exists
+func existsString(exists bool) string {
+	if exists {
+		return "exists"
+	}
+	return "missing"
+}
+
+func init() {
+	rootCmd.AddCommand(doctorCmd)
+}
@@ -24,6 +24,7 @@ SOFTWARE.
 package cmd
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"path"
@@ -31,7 +32,6 @@ import (
 
 	multierror "github.com/hashicorp/go-multierror"
 	"github.com/shadowblip/steam-shortcut-manager/pkg/chimera"
-	"github.com/shadowblip/steam-shortcut-manager/pkg/image/kitty"
 	"github.com/shadowblip/steam-shortcut-manager/pkg/shortcut"
 	"github.com/shadowblip/steam-shortcut-manager/pkg/steam"
 	"github.com/shadowblip/steam-shortcut-manager/pkg/steamgriddb"
@@ -75,18 +75,11 @@ var downloadCmd = &cobra.Command{
 	Run: func(cmd *cobra.Command, args []string) {
 		format := rootCmd.PersistentFlags().Lookup("output").Value.String()
 
-		// Ensure we have a SteamGridDB API Key
-		apiKey, _ := cmd.Flags().GetString("api-key")
-		if apiKey == "" {
-			cmd.Help()
-			ExitError(fmt.Errorf("API key is required"), format)
-		}
-
 		// Create a SteamGridDB client
-		client := steamgriddb.NewClient(apiKey)
+		client := newGridDBClient(cmd, format)
 
 		// Get all steam users
-		users, err := steam.GetUsers()
+		users, err := steam.GetUsersWithShortcuts()
 		if err != nil {
 			ExitError(err, format)
 		}
@@ -99,16 +92,13 @@ var downloadCmd = &cobra.Command{
 			toDownload := []*shortcut.Shortcut{}
 
 			// Load the user's shotcuts
-			if !steam.HasShortcuts(user) {
-				continue
-			}
 			shortcutsPath, _ := steam.GetShortcutsPath(user)
 			shortcuts, err := shortcut.Load(shortcutsPath)
 			if err != nil {
 				errors = multierror.Append(errors, err)
 				continue
 			}
-			appId, _ := cmd.Flags().GetInt("app-id")
+			appId := getAppIDFlag(cmd, format)
 
 			// If a shortcut name was specified, use that.
 			// NOTE: This is awful. Please forgive me
@@ -136,7 +126,8 @@ var downloadCmd = &cobra.Command{
 					toDownload = append(toDownload, sc)
 				} else {
 					// Otherwise download for all shortcuts
-					for _, sc := range shortcuts.Shortcuts {
+					for _, key := range shortcuts.Keys() {
+						sc := shortcuts.Shortcuts[key]
 						toDownload = append(toDownload, &sc)
 					}
 				}
@@ -144,13 +135,9 @@ var downloadCmd = &cobra.Command{
 
 			// TODO: Cache and symlink instead of downloading for each user
 			results[user] = map[string]map[string]string{}
+			refreshMatch, _ := cmd.Flags().GetBool("refresh-match")
 			for _, sc := range toDownload {
-				// Check that we have an API key
-				apiKey, _ := cmd.Flags().GetString("api-key")
-				if apiKey == "" {
-					ExitError(fmt.Errorf("no API key specified"), format)
-				}
-				downloaded, err := downloadImages(client, user, sc)
+				downloaded, err := downloadImages(client, user, sc, refreshMatch)
 				if err != nil {
 					DebugPrintln("Error downloading images:", err)
 					errors = multierror.Append(errors, err)
@@ -171,7 +158,7 @@ var downloadCmd = &cobra.Command{
 					fmt.Println("  Shortcut ID:", appId)
 					for kind, path := range downloads {
 						fmt.Printf("    %v: %v\n", kind, path)
-						kitty.Display(path)
+						displayImage(path, format)
 					}
 				}
 			}
@@ -182,15 +169,16 @@ var downloadCmd = &cobra.Command{
 			}
 			fmt.Println(string(out))
 		default:
-			panic("unknown output format: " + format)
+			ExitError(usageErrorf("unknown output format: %s", format), format)
 		}
 
+		restartSteamIfRequested(cmd, format)
 	},
 }
 
 // downloadImages will download images for the given shortcut
 // TODO: Handle errors better
-func downloadImages(client *steamgriddb.Client, user string, sc *shortcut.Shortcut) (map[string]string, error) {
+func downloadImages(client *steamgriddb.Client, user string, sc *shortcut.Shortcut, refreshMatch bool) (map[string]string, error) {
 	DebugPrintln("Downloading images for:", sc.AppName)
 	// This map will contain the paths to our downloaded images
 	downloaded := map[string]string{}
@@ -203,21 +191,12 @@ func downloadImages(client *steamgriddb.Client, user string, sc *shortcut.Shortc
 	}
 	DebugPrintln("Discovered images dir:", gridDir)
 
-	// Search for the app images
-	results, err := client.Search(sc.AppName)
+	// Find the SteamGridDB game for the app
+	steamAppID := fmt.Sprintf("%v", sc.Appid)
+	gameID, err := resolveGameID(client, sc.AppName, steamAppID, 0, refreshMatch)
 	if err != nil {
 		return nil, err
 	}
-	// TODO: Log or return no image results
-	if len(results.Data) == 0 {
-		return nil, fmt.Errorf("no results found for %v", sc.AppName)
-	}
-	DebugPrintln(fmt.Sprintf("Found %v results for %s", len(results.Data), sc.AppName))
-
-	// Get the first result
-	// TODO: Enable showing different results?
-	gameID := fmt.Sprintf("%v", results.Data[0].ID)
-	steamAppID := fmt.Sprintf("%v", sc.Appid)
 
 	// Download the grid images. Steam uses a portrait and landscape image
 	// that is displays in the library.
@@ -300,7 +279,7 @@ func downloadImages(client *steamgriddb.Client, user string, sc *shortcut.Shortc
 	}
 	for _, data := range icons.Data {
 		ext := filepath.Ext(data.URL)
-		imgFile := path.Join(gridDir, fmt.Sprintf("%s-icon%s", steamAppID, ext))
+		imgFile := path.Join(gridDir, fmt.Sprintf("%s_icon%s", steamAppID, ext))
 		err := client.CachedDownload(data.URL, imgFile)
 		if err != nil {
 			errors = multierror.Append(errors, err)
@@ -425,18 +404,31 @@ func init() {
 
 	// Cobra supports Persistent Flags which will work for this command
 	// and all subcommands, e.g.:
-	downloadCmd.Flags().IntP("app-id", "i", 0, "Steam App ID to download images for")
+	downloadCmd.Flags().StringP("app-id", "i", "", "Steam App ID to download images for")
+	addRestartFlag(downloadCmd)
+	downloadCmd.Flags().Bool("refresh-match", false, "Search SteamGridDB again instead of reusing the stored game match")
 
 	// Apply command flags
-	applyCmd.Flags().IntP("app-id", "i", 0, "Steam App ID to apply images for (required)")
+	applyCmd.Flags().StringP("app-id", "i", "", "Steam App ID to apply images for (required)")
+	applyCmd.Flags().String("user", "", "Steam user ID whose grid folder to write to (\"current\" or an ID, default is the user that owns the shortcut)")
 	applyCmd.MarkFlagRequired("app-id")
-
-	// Direct URL flags for apply command (optional, bypasses SteamGridDB search)
-	applyCmd.Flags().String("grid-portrait", "", "Direct URL for portrait grid image (600x900)")
-	applyCmd.Flags().String("grid-landscape", "", "Direct URL for landscape grid image (920x430)")
-	applyCmd.Flags().String("hero", "", "Direct URL for hero image (1920x620)")
-	applyCmd.Flags().String("logo", "", "Direct URL for logo image")
-	applyCmd.Flags().String("icon", "", "Direct URL for icon image")
+	applyCmd.Flags().Uint64("steam-app-id", 0, "Steam App ID of the game the shortcut wraps, used for an exact SteamGridDB match")
+	applyCmd.Flags().Bool("refresh-match", false, "Search SteamGridDB again instead of reusing the stored game match")
+
+	// Direct URL or file flags for apply command (optional, bypasses SteamGridDB search)
+	applyCmd.Flags().String("grid-portrait", "", "URL or local file for portrait grid image (600x900)")
+	applyCmd.Flags().String("grid-landscape", "", "URL or local file for landscape grid image (920x430)")
+	applyCmd.Flags().String("hero", "", "URL or local file for hero image (1920x620)")
+	applyCmd.Flags().String("logo", "", "URL or local file for logo image")
+	applyCmd.Flags().String("icon", "", "URL or local file for icon image")
+	applyCmd.Flags().String("grid-legacy", "", "URL or local file for legacy Big Picture grid (460x215)")
+	addRestartFlag(applyCmd)
+	applyCmd.Flags().StringSlice("only", []string{}, "Only fetch and apply these asset types from SteamGridDB (grid-portrait, grid-landscape, hero, logo, icon)")
+	applyCmd.Flags().Bool("prefer-animated", false, "Prefer animated SteamGridDB artwork when available")
+	applyCmd.Flags().Bool("set-icon", false, "Also set the shortcut's icon to the applied icon image")
+	applyCmd.Flags().Bool("strict-dimensions", false, "Refuse to apply grid and hero images with the wrong aspect ratio")
+	applyCmd.Flags().Int("concurrency", steam.DefaultConcurrency, "Maximum number of artwork images to fetch and apply at the same time")
+	addArtworkMethodFlag(applyCmd)
 
 	// Cobra supports local flags which will only run when this command
 	// is called directly, e.g.:
@@ -452,7 +444,7 @@ This method supports animated WebP and GIF images, unlike the filesystem method.
 
 Two modes of operation:
 1. Search mode: Provide --api-key and game name to search SteamGridDB
-2. Direct URL mode: Provide image URLs directly (no API key needed)
+2. Direct URL mode: Provide image URLs or local files directly (no API key needed)
 
 Examples:
   # Search mode - search SteamGridDB by name
@@ -472,84 +464,188 @@ Examples:
 		hero, _ := cmd.Flags().GetString("hero")
 		logo, _ := cmd.Flags().GetString("logo")
 		icon, _ := cmd.Flags().GetString("icon")
+		gridLegacy, _ := cmd.Flags().GetString("grid-legacy")
 
 		// Check if we have any direct URLs
-		hasDirectURLs := gridPortrait != "" || gridLandscape != "" || hero != "" || logo != "" || icon != ""
+		hasDirectURLs := gridPortrait != "" || gridLandscape != "" || hero != "" || logo != "" || icon != "" || gridLegacy != ""
 
 		// Get app ID
-		appID, _ := cmd.Flags().GetInt("app-id")
+		appID := getAppIDFlag(cmd, format)
 		if appID == 0 {
-			ExitError(fmt.Errorf("app-id is required"), format)
+			ExitError(usageErrorf("app-id is required"), format)
+		}
+
+		// Get artwork options
+		opts := &steam.ArtworkOptions{}
+		if cmd.Flags().Changed("user") {
+			opts.User = getUserFlag(cmd, format)
 		}
+		opts.UpdateShortcutIcon, _ = cmd.Flags().GetBool("set-icon")
+		opts.StrictDimensions, _ = cmd.Flags().GetBool("strict-dimensions")
+		opts.Method = getArtworkMethodFlag(cmd, format)
+		opts.Concurrency, _ = cmd.Flags().GetInt("concurrency")
 
 		if hasDirectURLs {
 			// Direct URL mode - use provided URLs
-			fmt.Println("Using direct URLs for artwork...")
+			termPrintf(format, "Using direct URLs for artwork...\n")
 			artwork := &steam.ArtworkConfig{
 				GridPortrait:  gridPortrait,
 				GridLandscape: gridLandscape,
 				HeroImage:     hero,
 				LogoImage:     logo,
 				IconImage:     icon,
+				GridLegacy:    gridLegacy,
 			}
 
-			fmt.Printf("Applying artwork for AppID %d...\n", appID)
+			termPrintf(format, "Applying artwork for AppID %d...\n", appID)
 			if gridPortrait != "" {
-				fmt.Printf("  Grid Portrait: %s\n", gridPortrait)
+				termPrintf(format, "  Grid Portrait: %s\n", gridPortrait)
 			}
 			if gridLandscape != "" {
-				fmt.Printf("  Grid Landscape: %s\n", gridLandscape)
+				termPrintf(format, "  Grid Landscape: %s\n", gridLandscape)
 			}
 			if hero != "" {
-				fmt.Printf("  Hero: %s\n", hero)
+				termPrintf(format, "  Hero: %s\n", hero)
 			}
 			if logo != "" {
-				fmt.Printf("  Logo: %s\n", logo)
+				termPrintf(format, "  Logo: %s\n", logo)
 			}
 			if icon != "" {
-				fmt.Printf("  Icon: %s\n", icon)
+				termPrintf(format, "  Icon: %s\n", icon)
 			}
-
-			err := steam.SetArtwork(uint64(appID), artwork)
-			if err != nil {
-				ExitError(err, format)
+			if gridLegacy != "" {
+				termPrintf(format, "  Legacy Grid: %s\n", gridLegacy)
 			}
+
+			applyArtworkAndReport(cmd.Context(), uint64(appID), artwork, opts, format)
 		} else {
 			// Search mode - need API key and game name
 			if len(args) == 0 {
 				cmd.Help()
-				ExitError(fmt.Errorf("game name is required when not using direct URLs"), format)
+				ExitError(usageErrorf("game name is required when not using direct URLs"), format)
 			}
 			gameName := args[0]
-
-			apiKey, _ := cmd.Flags().GetString("api-key")
-			if apiKey == "" {
-				cmd.Help()
-				ExitError(fmt.Errorf("API key is required when not using direct URLs"), format)
-			}
+			types := getAssetTypesFlag(cmd, format)
 
 			// Create SteamGridDB client and apply artwork
-			sgdbClient := steamgriddb.NewClient(apiKey)
+			sgdbClient := newGridDBClient(cmd, format, steamgriddb.WithConcurrency(opts.Concurrency))
 
-			fmt.Printf("Searching SteamGridDB for '%s'...\n", gameName)
-			results, err := sgdbClient.Search(gameName)
+			termPrintf(format, "Searching SteamGridDB for '%s'...\n", gameName)
+			refreshMatch, _ := cmd.Flags().GetBool("refresh-match")
+			steamAppID, _ := cmd.Flags().GetUint64("steam-app-id")
+			gameID, err := resolveGameID(sgdbClient, gameName, fmt.Sprintf("%d", appID), steamAppID, refreshMatch)
 			if err != nil {
 				ExitError(err, format)
 			}
-			if len(results.Data) == 0 {
-				ExitError(fmt.Errorf("no games found for '%s'", gameName), format)
-			}
-
-			gameID := fmt.Sprintf("%d", results.Data[0].ID)
-			fmt.Printf("Found: %s (ID: %s)\n", results.Data[0].Name, gameID)
+			termPrintf(format, "Found: %s (ID: %s)\n", gameName, gameID)
 
-			fmt.Println("Fetching and applying artwork...")
-			err = sgdbClient.ApplyArtwork(gameID, uint64(appID))
+			termPrintf(format, "Fetching artwork...\n")
+			preferAnimated, _ := cmd.Flags().GetBool("prefer-animated")
+			pref := steamgriddb.ArtworkPreference{PreferAnimated: preferAnimated}
+			candidates, err := sgdbClient.FetchArtworkCandidatesFor(gameID, types...)
 			if err != nil {
 				ExitError(err, format)
 			}
+			selection, animated := candidates.Select(pref)
+			artwork := candidates.Config(selection)
+			checkFetchError(artwork, candidates.Err(), format)
+			for _, assetType := range animated {
+				termPrintf(format, "  Animated: %s\n", assetType)
+			}
+
+			termPrintf(format, "Applying artwork...\n")
+			applyArtworkAndReport(cmd.Context(), uint64(appID), artwork, opts, format)
 		}
 
-		fmt.Println("Artwork applied successfully!")
+		termPrintf(format, "Artwork applied successfully!\n")
+		restartSteamIfRequested(cmd, format)
 	},
 }
+
+// addArtworkMethodFlag will add the --method flag to the given command
+func addArtworkMethodFlag(cmd *cobra.Command) {
+	cmd.Flags().String("method", "auto", "How to apply artwork: auto (CEF API with filesystem fallback), cef (fail if the CEF API is unavailable), or filesystem")
+}
+
+// getArtworkMethodFlag will return the artwork method given with the --method
+// flag
+func getArtworkMethodFlag(cmd *cobra.Command, format string) steam.ArtworkMethod {
+	name, _ := cmd.Flags().GetString("method")
+	method, err := steam.ParseArtworkMethod(name)
+	if err != nil {
+		ExitError(usageErrorf("%v", err), format)
+	}
+	return method
+}
+
+// getAssetTypesFlag will return the asset types given with the --only flag,
+// or every SteamGridDB asset type if it was not given.
+func getAssetTypesFlag(cmd *cobra.Command, format string) []steam.AssetType {
+	names, _ := cmd.Flags().GetStringSlice("only")
+	if len(names) == 0 {
+		return steam.AllAssetTypes
+	}
+	types := []steam.AssetType{}
+	for _, name := range names {
+		assetType, err := steam.ParseAssetType(name)
+		if err != nil {
+			ExitError(usageErrorf("%v", err), format)
+		}
+		types = append(types, assetType)
+	}
+	return types
+}
+
+// termPrintf will print the given progress message if the output format is
+// term, so it never ends up in machine-readable output
+func termPrintf(format, msg string, a ...interface{}) {
+	if format == "term" {
+		fmt.Printf(msg, a...)
+	}
+}
+
+// applyArtworkAndReport will apply the given artwork and print how each asset
+// was applied. Exits with an error if any asset failed.
+func applyArtworkAndReport(ctx context.Context, appID uint64, artwork *steam.ArtworkConfig, opts *steam.ArtworkOptions, format string) {
+	results, err := steam.SetArtworkDetailedContext(ctx, appID, artwork, opts)
+	if err != nil {
+		ExitError(err, format)
+	}
+	if err := printArtworkResults(results, format); err != nil {
+		exitReportedError(err, format)
+	}
+}
+
+// printArtworkResults will print how each asset was applied. Returns the
+// errors of the assets that failed. In JSON mode the results and errors are
+// printed as one document.
+func printArtworkResults(results []steam.AssetResult, format string) error {
+	var errs error
+	for _, result := range results {
+		if result.Err != nil {
+			errs = multierror.Append(errs, &steam.AssetError{AssetType: result.AssetType, Err: result.Err})
+		}
+	}
+
+	// Print the output
+	switch format {
+	case "term":
+		for _, result := range results {
+			if result.Err != nil {
+				fmt.Printf("  %s: FAILED: %v\n", result.AssetType, result.Err)
+				continue
+			}
+			fmt.Printf("  %s: applied via %s\n", result.AssetType, result.Method)
+		}
+	case "json":
+		out, err := marshalJSONResults(results, errs)
+		if err != nil {
+			ExitError(err, format)
+		}
+		fmt.Println(string(out))
+	default:
+		ExitError(usageErrorf("unknown output format: %s", format), format)
+	}
+
+	return errs
+}
@@ -2,11 +2,14 @@ package cmd
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"strings"
 
 	multierror "github.com/hashicorp/go-multierror"
+	"github.com/shadowblip/steam-shortcut-manager/pkg/logger"
+	"github.com/shadowblip/steam-shortcut-manager/pkg/steam"
 )
 
 type FatalError struct {
@@ -31,22 +34,96 @@ func (m *MultiError) Error() string {
 	return strings.Join(report, "; ")
 }
 
-// ExitError will print an error and exit depending on the output format
+// Exit codes used by the CLI
+const (
+	// ExitCodeGeneric is used for any error without a more specific code
+	ExitCodeGeneric = 1
+	// ExitCodeUsage is used for invalid arguments, flags, or output formats
+	ExitCodeUsage = 2
+	// ExitCodeNoSteam is used when no Steam installation could be found
+	ExitCodeNoSteam = 3
+)
+
+// ExitCodeError is an error with a specific exit code
+type ExitCodeError struct {
+	Err  error
+	Code int
+}
+
+func (e *ExitCodeError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ExitCodeError) Unwrap() error {
+	return e.Err
+}
+
+// usageErrorf will return an error for invalid command usage
+func usageErrorf(format string, a ...interface{}) error {
+	return &ExitCodeError{Err: fmt.Errorf(format, a...), Code: ExitCodeUsage}
+}
+
+// exitCode will return the exit code to use for the given error
+func exitCode(err error) int {
+	var codeErr *ExitCodeError
+	switch {
+	case errors.As(err, &codeErr):
+		return codeErr.Code
+	case errors.Is(err, steam.ErrNoSteamDir), errors.Is(err, steam.ErrNoUsers), errors.Is(err, steam.ErrSteamNotFound):
+		return ExitCodeNoSteam
+	}
+	return ExitCodeGeneric
+}
+
+// ExitError will print an error and exit depending on the output format. In
+// JSON mode, the error is printed as {"error": "...", "code": N}.
 func ExitError(err error, format string) {
+	os.Exit(printError(err, format))
+}
+
+// printError will print the given error depending on the output format and
+// return the exit code for it. In JSON mode, the error is part of the
+// machine-readable output on stdout. Otherwise it is printed to stderr, so it
+// never ends up in piped output.
+func printError(err error, format string) int {
+	code := exitCode(err)
 	switch format {
 	case "json":
-		out, _ := json.Marshal(map[string]string{"errors": err.Error()})
+		out, _ := json.Marshal(map[string]interface{}{"error": err.Error(), "code": code})
 		fmt.Println(string(out))
-		os.Exit(1)
 	default:
-		fmt.Printf("Error: %v\n", err)
-		os.Exit(1)
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		if errors.Is(err, steam.ErrNoSteamDir) || errors.Is(err, steam.ErrNoUsers) {
+			fmt.Fprintf(os.Stderr, "Run '%s doctor' to find out why\n", rootCmd.Name())
+		}
 	}
+	return code
+}
+
+// exitReportedError will exit with the exit code of the given error. In JSON
+// mode the error is expected to be part of the document that was already
+// printed, see marshalJSONResults, so it is not printed again.
+func exitReportedError(err error, format string) {
+	if format == "json" {
+		os.Exit(exitCode(err))
+	}
+	ExitError(err, format)
+}
+
+// marshalJSONResults will encode the given results along with the error that
+// occurred while producing them as a single JSON document:
+// {"results": ..., "error": "...", "code": N}. The error and code are left out
+// if err is nil.
+func marshalJSONResults(results interface{}, err error) ([]byte, error) {
+	out := map[string]interface{}{"results": results}
+	if err != nil {
+		out["error"] = err.Error()
+		out["code"] = exitCode(err)
+	}
+	return json.MarshalIndent(out, "", "  ")
 }
 
 // Print debug messages if debug is enabled
 func DebugPrintln(s ...interface{}) {
-	if os.Getenv("DEBUG") != "" {
-		fmt.Println(s...)
-	}
+	logger.DebugPrintln(s...)
 }
@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/shadowblip/steam-shortcut-manager/pkg/steam"
+)
+
+func TestPrintErrorTermWritesToStderr(t *testing.T) {
+	err := fmt.Errorf("unable to list users: %w", steam.ErrNoUsers)
+
+	var code int
+	var stderr string
+	stdout := captureStdout(t, func() {
+		stderr = captureOutput(t, &os.Stderr, func() {
+			code = printError(err, "term")
+		})
+	})
+	if stdout != "" {
+		t.Errorf("stdout = %q, want nothing", stdout)
+	}
+	if !strings.Contains(stderr, "Error: unable to list users") {
+		t.Errorf("stderr = %q, want the error", stderr)
+	}
+	if !strings.Contains(stderr, "doctor' to find out why") {
+		t.Errorf("stderr = %q, want the doctor hint", stderr)
+	}
+	if code != ExitCodeNoSteam {
+		t.Errorf("printError() = %d, want %d", code, ExitCodeNoSteam)
+	}
+}
+
+func TestPrintErrorJSONWritesToStdout(t *testing.T) {
+	var stderr string
+	stdout := captureStdout(t, func() {
+		stderr = captureOutput(t, &os.Stderr, func() {
+			printError(usageErrorf("invalid app ID '0'"), "json")
+		})
+	})
+	if stderr != "" {
+		t.Errorf("stderr = %q, want nothing", stderr)
+	}
+	var out struct {
+		Error string `json:"error"`
+		Code  int    `json:"code"`
+	}
+	if err := json.Unmarshal([]byte(stdout), &out); err != nil {
+		t.Fatalf("stdout is not JSON: %q", stdout)
+	}
+	if out.Error != "invalid app ID '0'" || out.Code != ExitCodeUsage {
+		t.Errorf("stdout = %+v, want the usage error", out)
+	}
+}
+
+func TestPrintArtworkResultsJSONIsOneDocument(t *testing.T) {
+	results := []steam.AssetResult{
+		{AssetType: steam.AssetTypeHero, Method: steam.ArtworkMethodFilesystem, Path: "/tmp/hero.png"},
+		{AssetType: steam.AssetTypeLogo, Err: fmt.Errorf("download failed")},
+	}
+
+	var errs error
+	stdout := captureStdout(t, func() {
+		errs = printArtworkResults(results, "json")
+	})
+	if errs == nil {
+		t.Fatal("printArtworkResults() = nil, want the logo error")
+	}
+
+	dec := json.NewDecoder(strings.NewReader(stdout))
+	var out struct {
+		Results []map[string]interface{} `json:"results"`
+		Error   string                   `json:"error"`
+		Code    int                      `json:"code"`
+	}
+	if err := dec.Decode(&out); err != nil {
+		t.Fatalf("stdout is not JSON: %q", stdout)
+	}
+	if dec.More() {
+		t.Errorf("stdout = %q, want a single JSON document", stdout)
+	}
+	if len(out.Results) != 2 {
+		t.Errorf("results = %v, want 2 assets", out.Results)
+	}
+	if !strings.Contains(out.Error, "download failed") || out.Code != ExitCodeGeneric {
+		t.Errorf("stdout = %+v, want the asset error", out)
+	}
+}
@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/shadowblip/steam-shortcut-manager/pkg/shortcut"
+	"github.com/shadowblip/steam-shortcut-manager/pkg/steam"
+	"github.com/spf13/cobra"
+)
+
+// exportCmd represents the export command
+var exportCmd = &cobra.Command{
+	Use:   "export [<file>]",
+	Short: "Export Steam shortcuts to a JSON file",
+	Long: `Export the Steam shortcuts of a user to a portable JSON file that can be
+version controlled or moved to another machine and read back with the import
+command. If no file is given, or the file is "-", the JSON is written to
+stdout.`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		format := rootCmd.PersistentFlags().Lookup("output").Value.String()
+
+		// Fetch all users with shortcuts
+		users, err := steam.GetUsers()
+		if err != nil {
+			ExitError(err, format)
+		}
+		withShortcuts := []string{}
+		for _, user := range users {
+			if steam.HasShortcuts(user) {
+				withShortcuts = append(withShortcuts, user)
+			}
+		}
+
+		// Determine which user to export
+		user := getUserFlag(cmd, format)
+		if user == "" {
+			if len(withShortcuts) != 1 {
+				ExitError(usageErrorf("%v users have shortcuts, use --user to choose one", len(withShortcuts)), format)
+			}
+			user = withShortcuts[0]
+		}
+		if !contains(withShortcuts, user) {
+			ExitError(fmt.Errorf("no shortcuts found for user %v", user), format)
+		}
+
+		shortcutsPath, _ := steam.GetShortcutsPath(user)
+		shortcuts, err := shortcut.Load(shortcutsPath)
+		if err != nil {
+			ExitError(err, format)
+		}
+
+		// Write to stdout if no file was given
+		if len(args) == 0 || args[0] == "-" {
+			out, err := shortcut.MarshalJSON(shortcuts)
+			if err != nil {
+				ExitError(err, format)
+			}
+			fmt.Println(string(out))
+			return
+		}
+
+		if err := shortcut.SaveJSON(shortcuts, args[0]); err != nil {
+			ExitError(err, format)
+		}
+		fmt.Fprintf(os.Stderr, "Exported %v shortcuts for user %v to %v\n", len(shortcuts.Shortcuts), user, args[0])
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(exportCmd)
+
+	exportCmd.Flags().String("user", "", "Steam user ID to export the shortcuts of, or \"current\" (required if several users have shortcuts)")
+}
@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// GameResult is a single game found on SteamGridDB
+type GameResult struct {
+	ID       int    `json:"id"`
+	Name     string `json:"name"`
+	Year     int    `json:"year,omitempty"`
+	Verified bool   `json:"verified"`
+}
+
+// gamesCmd represents the games command
+var gamesCmd = &cobra.Command{
+	Use:   "games --api-key <key> <name>",
+	Short: "Search SteamGridDB for games",
+	Long: `Search SteamGridDB for games matching the given name and print their
+SteamGridDB ID, name, and release year. Unlike search, no artwork is fetched,
+which makes this a quick way to find the right game ID to use with the grids
+and apply commands.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		format := rootCmd.PersistentFlags().Lookup("output").Value.String()
+
+		// Create a SteamGridDB Client
+		client := newGridDBClient(cmd, format)
+		results, err := client.Search(args[0])
+		if err != nil {
+			ExitError(err, format)
+		}
+		if !results.Success {
+			ExitError(fmt.Errorf("%v", results.Errors), format)
+		}
+
+		// Limit the number of results
+		games := []GameResult{}
+		maxResults, _ := cmd.Flags().GetInt("max-results")
+		for i, result := range results.Data {
+			if maxResults > 0 && i >= maxResults {
+				break
+			}
+			game := GameResult{ID: result.ID, Name: result.Name, Verified: result.Verified}
+			if result.ReleaseDate > 0 {
+				game.Year = time.Unix(result.ReleaseDate, 0).UTC().Year()
+			}
+			games = append(games, game)
+		}
+
+		// Print the output
+		switch format {
+		case "term":
+			if len(games) == 0 {
+				fmt.Println("No games found for", args[0])
+			}
+			for _, game := range games {
+				year := "unknown"
+				if game.Year > 0 {
+					year = fmt.Sprintf("%v", game.Year)
+				}
+				fmt.Printf("%v\t%v (%v)\n", game.ID, game.Name, year)
+			}
+		case "json":
+			out, err := json.MarshalIndent(games, "", "  ")
+			if err != nil {
+				ExitError(err, format)
+			}
+			fmt.Println(string(out))
+		default:
+			ExitError(usageErrorf("unknown output format: %s", format), format)
+		}
+	},
+}
+
+func init() {
+	steamgriddbCmd.AddCommand(gamesCmd)
+	gamesCmd.Flags().IntP("max-results", "n", 10, "Number of games to return (0 returns all)")
+}
@@ -0,0 +1,117 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/shadowblip/steam-shortcut-manager/pkg/steamgriddb"
+	"github.com/spf13/cobra"
+)
+
+// GridResult is a single grid image available on SteamGridDB
+type GridResult struct {
+	ID     int    `json:"id"`
+	Style  string `json:"style"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+	Mime   string `json:"mime"`
+	Author string `json:"author"`
+	URL    string `json:"url"`
+}
+
+// GridsOutput is the output of the grids command
+type GridsOutput struct {
+	GameID  string       `json:"game_id"`
+	Page    int          `json:"page"`
+	Total   int          `json:"total"`
+	HasMore bool         `json:"has_more"`
+	Grids   []GridResult `json:"grids"`
+}
+
+// gridsCmd represents the grids command
+var gridsCmd = &cobra.Command{
+	Use:   "grids --api-key <key> <game id>",
+	Short: "List the grid images available for a SteamGridDB game",
+	Long: `List the grid images available for the game with the given SteamGridDB ID.
+Use the games command to find the ID of a game. The printed URLs can be passed
+to the apply command to use a specific image.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		format := rootCmd.PersistentFlags().Lookup("output").Value.String()
+		gameID := args[0]
+		if _, err := strconv.ParseUint(gameID, 10, 64); err != nil {
+			ExitError(usageErrorf("invalid SteamGridDB game ID: %s", gameID), format)
+		}
+
+		// Add any requested filters
+		query := []steamgriddb.QueryFilter{}
+		if page, _ := cmd.Flags().GetInt("page"); page > 0 {
+			query = append(query, steamgriddb.FilterPage(page))
+		}
+		if styles, _ := cmd.Flags().GetStringSlice("style"); len(styles) > 0 {
+			query = append(query, steamgriddb.FilterStyle(styles...))
+		}
+		if dimensions, _ := cmd.Flags().GetStringSlice("dimensions"); len(dimensions) > 0 {
+			query = append(query, steamgriddb.FilterDimensions(dimensions...))
+		}
+
+		// Fetch the grids
+		client := newGridDBClient(cmd, format)
+		grids, err := client.GetGridsWithQuery(gameID, query)
+		if err != nil {
+			ExitError(err, format)
+		}
+		if !grids.Success {
+			ExitError(fmt.Errorf("%v", grids.Errors), format)
+		}
+
+		output := GridsOutput{
+			GameID:  gameID,
+			Page:    grids.Page,
+			Total:   grids.Total,
+			HasMore: grids.HasMore(),
+			Grids:   []GridResult{},
+		}
+		for _, grid := range grids.Data {
+			output.Grids = append(output.Grids, GridResult{
+				ID:     grid.ID,
+				Style:  grid.Style,
+				Width:  grid.Width,
+				Height: grid.Height,
+				Mime:   grid.Mime,
+				Author: grid.Author.Name,
+				URL:    grid.URL,
+			})
+		}
+
+		// Print the output
+		switch format {
+		case "term":
+			if len(output.Grids) == 0 {
+				fmt.Println("No grids found for game", gameID)
+			}
+			for _, grid := range output.Grids {
+				fmt.Printf("%v\t%vx%v\t%v\t%v\n", grid.ID, grid.Width, grid.Height, grid.Style, grid.URL)
+			}
+			if output.HasMore {
+				fmt.Printf("More grids available, use --page %v to see the next page\n", output.Page+1)
+			}
+		case "json":
+			out, err := json.MarshalIndent(output, "", "  ")
+			if err != nil {
+				ExitError(err, format)
+			}
+			fmt.Println(string(out))
+		default:
+			ExitError(usageErrorf("unknown output format: %s", format), format)
+		}
+	},
+}
+
+func init() {
+	steamgriddbCmd.AddCommand(gridsCmd)
+	gridsCmd.Flags().Int("page", 0, "Zero-based page of grid results to return")
+	gridsCmd.Flags().StringSlice("style", nil, `Only include grids with the given styles ("alternate" "blurred" "white_logo" "material" "no_logo")`)
+	gridsCmd.Flags().StringSlice("dimensions", nil, `Only include grids with the given dimensions (e.g. "600x900")`)
+}
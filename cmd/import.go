@@ -0,0 +1,291 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/shadowblip/steam-shortcut-manager/pkg/shortcut"
+	"github.com/shadowblip/steam-shortcut-manager/pkg/steam"
+	"github.com/shadowblip/steam-shortcut-manager/pkg/steamgriddb"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// ImportValidation is the validation result of a single imported shortcut
+type ImportValidation struct {
+	Key     string `json:"key"`
+	AppName string `json:"AppName"`
+	Valid   bool   `json:"valid"`
+	Error   string `json:"error,omitempty"`
+}
+
+// ImportResult is the action taken for a single imported shortcut
+type ImportResult struct {
+	AppName string `json:"AppName"`
+	Appid   int64  `json:"appid"`
+	Action  string `json:"action"`
+}
+
+// importConflictPolicies are the valid values for --on-conflict
+var importConflictPolicies = []string{"skip", "overwrite", "rename"}
+
+// importCmd represents the import command
+var importCmd = &cobra.Command{
+	Use:   "import <file>",
+	Short: "Import Steam shortcuts from a JSON file",
+	Long: `Import Steam shortcuts from a JSON file into your library. The file uses
+the same layout as the JSON output of the export command.
+
+By default the shortcuts are merged into the existing library. Shortcuts that
+match an existing one by name or app ID are handled with --on-conflict. Use
+--replace to replace the whole library with the contents of the file instead.
+
+Use --validate-only to check that the file parses and every shortcut is
+well-formed without making any changes.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		format := rootCmd.PersistentFlags().Lookup("output").Value.String()
+
+		replace, err := importReplaceFlag(cmd.Flags())
+		if err != nil {
+			ExitError(err, format)
+		}
+
+		// Parse the import file
+		imported, err := shortcut.LoadJSON(args[0])
+		if err != nil {
+			ExitError(err, format)
+		}
+
+		// Validate every shortcut in the file
+		validations, valid := validateImport(imported)
+
+		// Only report the validation results if requested
+		if validateOnly, _ := cmd.Flags().GetBool("validate-only"); validateOnly {
+			switch format {
+			case "term":
+				for _, validation := range validations {
+					if validation.Valid {
+						fmt.Printf("[%v] %v: OK\n", validation.Key, validation.AppName)
+						continue
+					}
+					fmt.Printf("[%v] %v: INVALID: %v\n", validation.Key, validation.AppName, validation.Error)
+				}
+			case "json":
+				out, err := json.MarshalIndent(validations, "", "  ")
+				if err != nil {
+					ExitError(err, format)
+				}
+				fmt.Println(string(out))
+			default:
+				ExitError(usageErrorf("unknown output format: %s", format), format)
+			}
+			if !valid {
+				ExitError(fmt.Errorf("import file contains invalid shortcuts"), format)
+			}
+			return
+		}
+		if !valid {
+			for _, validation := range validations {
+				if !validation.Valid {
+					ExitError(fmt.Errorf("invalid shortcut '%v': %v", validation.AppName, validation.Error), format)
+				}
+			}
+		}
+
+		// Check the conflict policy
+		onConflict, _ := cmd.Flags().GetString("on-conflict")
+		if !contains(importConflictPolicies, onConflict) {
+			ExitError(usageErrorf("invalid --on-conflict value '%s' (must be skip, overwrite, or rename)", onConflict), format)
+		}
+
+		// Create a SteamGridDB client if we need to download images
+		var client *steamgriddb.Client
+		if download, _ := cmd.Flags().GetBool("download-images"); download {
+			client = newGridDBClient(cmd, format)
+		}
+		refreshMatch, _ := cmd.Flags().GetBool("refresh-match")
+
+		// Fetch all users
+		users, err := steam.GetUsers()
+		if err != nil {
+			ExitError(err, format)
+		}
+
+		// Check to see if we're importing for just one user
+		onlyForUser := getUserFlag(cmd, format)
+
+		// Make sure the user wants to replace their existing shortcuts
+		if replace {
+			affected := []string{}
+			for _, user := range users {
+				if onlyForUser != "all" && onlyForUser != user {
+					continue
+				}
+				if steam.HasShortcuts(user) {
+					affected = append(affected, "all shortcuts of user "+user)
+				}
+			}
+			if len(affected) > 0 {
+				confirmChanges(cmd, format, "replace the following shortcuts", affected)
+			}
+		}
+
+		// Import the shortcuts for each user
+		results := map[string][]ImportResult{}
+		for _, user := range users {
+			if onlyForUser != "all" && onlyForUser != user {
+				continue
+			}
+
+			shortcutsPath, _ := steam.GetShortcutsPath(user)
+			shortcuts := shortcut.NewShortcuts()
+			if steam.HasShortcuts(user) && !replace {
+				shortcuts, err = shortcut.Load(shortcutsPath)
+				if err != nil {
+					ExitError(err, format)
+				}
+			}
+
+			results[user] = []ImportResult{}
+			for _, key := range imported.Keys() {
+				sc := imported.Shortcuts[key]
+				if sc.Appid == 0 {
+					sc.Appid = int64(shortcut.CalculateAppID(sc.Exe, sc.AppName))
+				}
+				result, err := importShortcut(shortcuts, sc, onConflict)
+				if err != nil {
+					ExitError(err, format)
+				}
+				results[user] = append(results[user], result)
+
+				// Fetch the artwork of new and changed shortcuts
+				if client == nil || result.Action == "skipped" {
+					continue
+				}
+				key, _ := shortcuts.FindKey(result.AppName, result.Appid)
+				imported := shortcuts.Shortcuts[key]
+				downloaded, err := downloadImages(client, user, &imported, refreshMatch)
+				if err != nil {
+					DebugPrintln("Error downloading images:", err)
+				}
+				if icon, ok := downloaded["icon"]; ok {
+					imported.Icon = icon
+					shortcuts.Shortcuts[key] = imported
+				}
+			}
+
+			// Write the changes
+			err = shortcut.Save(shortcuts, shortcutsPath)
+			if err != nil {
+				ExitError(err, format)
+			}
+		}
+		restartSteamIfRequested(cmd, format)
+
+		// Print the output
+		switch format {
+		case "term":
+			for user, userResults := range results {
+				fmt.Println("User:", user)
+				for _, result := range userResults {
+					fmt.Printf("  %v: %v (%v)\n", result.Action, result.AppName, result.Appid)
+				}
+			}
+		case "json":
+			out, err := json.MarshalIndent(results, "", "  ")
+			if err != nil {
+				ExitError(err, format)
+			}
+			fmt.Println(string(out))
+		default:
+			ExitError(usageErrorf("unknown output format: %s", format), format)
+		}
+	},
+}
+
+// importShortcut will add the given shortcut to the library, resolving any
+// existing shortcut with the same name or app ID using the given conflict
+// policy.
+func importShortcut(shortcuts *shortcut.Shortcuts, sc shortcut.Shortcut, onConflict string) (ImportResult, error) {
+	key, exists := shortcuts.FindKey(sc.AppName, sc.Appid)
+	if !exists {
+		err := shortcuts.Add(&sc)
+		return ImportResult{AppName: sc.AppName, Appid: sc.Appid, Action: "added"}, err
+	}
+
+	switch onConflict {
+	case "overwrite":
+		// Replace the fields of the existing shortcut, but keep its app ID so
+		// its artwork stays attached.
+		sc.Appid = shortcuts.Shortcuts[key].Appid
+		shortcuts.Shortcuts[key] = sc
+		return ImportResult{AppName: sc.AppName, Appid: sc.Appid, Action: "overwritten"}, nil
+	case "rename":
+		// Find a free name and give the shortcut a matching app ID
+		baseName := sc.AppName
+		for i := 2; ; i++ {
+			sc.AppName = fmt.Sprintf("%s (%d)", baseName, i)
+			sc.Appid = int64(shortcut.CalculateAppID(sc.Exe, sc.AppName))
+			if _, taken := shortcuts.FindKey(sc.AppName, sc.Appid); !taken {
+				break
+			}
+		}
+		err := shortcuts.Add(&sc)
+		return ImportResult{AppName: sc.AppName, Appid: sc.Appid, Action: "renamed"}, err
+	default:
+		existing := shortcuts.Shortcuts[key]
+		return ImportResult{AppName: existing.AppName, Appid: existing.Appid, Action: "skipped"}, nil
+	}
+}
+
+// importReplaceFlag will return whether the library should be replaced
+// instead of merged into. Merging is the default, so --merge cannot be given
+// with --replace and --merge=false requires it.
+func importReplaceFlag(flags *pflag.FlagSet) (bool, error) {
+	replace, _ := flags.GetBool("replace")
+	merge, _ := flags.GetBool("merge")
+	if replace && merge && flags.Changed("merge") {
+		return false, usageErrorf("--merge and --replace cannot be used together")
+	}
+	if !merge && !replace {
+		return false, usageErrorf("--merge=false requires --replace")
+	}
+	return replace, nil
+}
+
+// validateImport will check that every shortcut in the given import is
+// well-formed. Returns the result of each shortcut in key order and whether
+// all of them are valid.
+func validateImport(imported *shortcut.Shortcuts) ([]ImportValidation, bool) {
+	validations := []ImportValidation{}
+	valid := true
+	for _, key := range imported.Keys() {
+		sc := imported.Shortcuts[key]
+		validation := ImportValidation{Key: key, AppName: sc.AppName, Valid: true}
+		if err := sc.ValidateFields(); err != nil {
+			validation.Valid = false
+			validation.Error = err.Error()
+			valid = false
+		}
+		validations = append(validations, validation)
+	}
+
+	return validations, valid
+}
+
+func init() {
+	rootCmd.AddCommand(importCmd)
+
+	importCmd.Flags().Bool("validate-only", false, "Only parse and validate the import file without making any changes")
+	importCmd.Flags().String("user", "all", "Steam user ID to import the shortcuts for (\"all\", \"current\", or an ID)")
+	importCmd.Flags().Bool("merge", true, "Merge the shortcuts into the existing library")
+	importCmd.Flags().Bool("replace", false, "Replace the existing library with the imported shortcuts")
+	addYesFlag(importCmd)
+	importCmd.Flags().StringP("api-key", "k", "", "SteamGridDB API Key (default is $"+steamgriddb.APIKeyEnv+")")
+	importCmd.Flags().String("strategy", "first", strategyFlagUsage)
+	importCmd.Flags().BoolP("download-images", "i", false, "Download artwork from SteamGridDB for imported shortcuts (requires SteamGridDB API Key)")
+	importCmd.Flags().Bool("refresh-match", false, "Search SteamGridDB again instead of reusing the stored game match")
+	addRestartFlag(importCmd)
+	importCmd.Flags().String("on-conflict", "skip", "What to do when a shortcut with the same name or app ID exists (skip, overwrite, rename)")
+}
@@ -0,0 +1,215 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/shadowblip/steam-shortcut-manager/pkg/shortcut"
+	"github.com/spf13/pflag"
+)
+
+// writeImportFile will write the given JSON to an import file and return its
+// path
+func writeImportFile(t *testing.T, data string) string {
+	t.Helper()
+	file := filepath.Join(t.TempDir(), "import.json")
+	if err := os.WriteFile(file, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return file
+}
+
+func TestValidateImportValidFile(t *testing.T) {
+	file := writeImportFile(t, `{"shortcuts": {
+		"0": {"AppName": "Game", "Exe": "\"/usr/bin/game\"", "StartDir": "\"/usr/bin/\""},
+		"1": {"AppName": "Other Game", "Exe": "/usr/bin/other"}
+	}}`)
+
+	imported, err := shortcut.LoadJSON(file)
+	if err != nil {
+		t.Fatalf("LoadJSON() error = %v", err)
+	}
+	validations, valid := validateImport(imported)
+	if !valid {
+		t.Errorf("validateImport() = invalid, want valid: %+v", validations)
+	}
+	if len(validations) != 2 {
+		t.Fatalf("validateImport() returned %d results, want 2", len(validations))
+	}
+	for _, validation := range validations {
+		if !validation.Valid || validation.Error != "" {
+			t.Errorf("shortcut %v is invalid: %v", validation.Key, validation.Error)
+		}
+	}
+}
+
+func TestValidateImportInvalidFile(t *testing.T) {
+	file := writeImportFile(t, `{"shortcuts": {
+		"0": {"AppName": "Game", "Exe": "/usr/bin/game"},
+		"1": {"AppName": "", "Exe": ""}
+	}}`)
+
+	imported, err := shortcut.LoadJSON(file)
+	if err != nil {
+		t.Fatalf("LoadJSON() error = %v", err)
+	}
+	validations, valid := validateImport(imported)
+	if valid {
+		t.Fatal("validateImport() = valid, want invalid")
+	}
+	if len(validations) != 2 || !validations[0].Valid || validations[1].Valid {
+		t.Fatalf("validateImport() = %+v, want only shortcut 1 invalid", validations)
+	}
+	for _, problem := range []string{"app name is empty", "exe is empty"} {
+		if !strings.Contains(validations[1].Error, problem) {
+			t.Errorf("shortcut 1 error %q does not report %q", validations[1].Error, problem)
+		}
+	}
+}
+
+func TestValidateImportUnparsableFile(t *testing.T) {
+	file := writeImportFile(t, `{"shortcuts": [`)
+
+	if _, err := shortcut.LoadJSON(file); err == nil {
+		t.Fatal("LoadJSON() of a truncated file succeeded")
+	}
+}
+
+// newImportLibrary will return a library with one existing shortcut
+func newImportLibrary(t *testing.T) (*shortcut.Shortcuts, shortcut.Shortcut) {
+	t.Helper()
+	existing := shortcut.Shortcut{
+		AppName:       "Game",
+		Exe:           "/usr/bin/game",
+		LaunchOptions: "--old",
+		Appid:         int64(shortcut.CalculateAppID("/usr/bin/game", "Game")),
+	}
+	shortcuts := shortcut.NewShortcuts()
+	if err := shortcuts.Add(&existing); err != nil {
+		t.Fatal(err)
+	}
+	return shortcuts, existing
+}
+
+func TestImportShortcutConflictPolicies(t *testing.T) {
+	incoming := shortcut.Shortcut{
+		AppName:       "Game",
+		Exe:           "/opt/game/run",
+		LaunchOptions: "--new",
+	}
+	incoming.Appid = int64(shortcut.CalculateAppID(incoming.Exe, incoming.AppName))
+
+	t.Run("skip", func(t *testing.T) {
+		shortcuts, existing := newImportLibrary(t)
+		result, err := importShortcut(shortcuts, incoming, "skip")
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := ImportResult{AppName: "Game", Appid: existing.Appid, Action: "skipped"}
+		if result != want {
+			t.Errorf("importShortcut() = %+v, want %+v", result, want)
+		}
+		if len(shortcuts.Shortcuts) != 1 {
+			t.Fatalf("library has %d shortcuts, want 1", len(shortcuts.Shortcuts))
+		}
+		if sc := shortcuts.Shortcuts["0"]; sc.Exe != existing.Exe || sc.LaunchOptions != "--old" {
+			t.Errorf("existing shortcut was changed: %+v", sc)
+		}
+	})
+
+	t.Run("overwrite", func(t *testing.T) {
+		shortcuts, existing := newImportLibrary(t)
+		result, err := importShortcut(shortcuts, incoming, "overwrite")
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := ImportResult{AppName: "Game", Appid: existing.Appid, Action: "overwritten"}
+		if result != want {
+			t.Errorf("importShortcut() = %+v, want %+v", result, want)
+		}
+		if len(shortcuts.Shortcuts) != 1 {
+			t.Fatalf("library has %d shortcuts, want 1", len(shortcuts.Shortcuts))
+		}
+		sc := shortcuts.Shortcuts["0"]
+		if sc.Exe != incoming.Exe || sc.LaunchOptions != "--new" {
+			t.Errorf("existing shortcut fields were not replaced: %+v", sc)
+		}
+		if sc.Appid != existing.Appid {
+			t.Errorf("overwritten shortcut app ID = %v, want the existing %v", sc.Appid, existing.Appid)
+		}
+	})
+
+	t.Run("rename", func(t *testing.T) {
+		shortcuts, existing := newImportLibrary(t)
+		result, err := importShortcut(shortcuts, incoming, "rename")
+		if err != nil {
+			t.Fatal(err)
+		}
+		wantAppid := int64(shortcut.CalculateAppID(incoming.Exe, "Game (2)"))
+		want := ImportResult{AppName: "Game (2)", Appid: wantAppid, Action: "renamed"}
+		if result != want {
+			t.Errorf("importShortcut() = %+v, want %+v", result, want)
+		}
+		if wantAppid == existing.Appid || wantAppid == incoming.Appid {
+			t.Errorf("renamed shortcut did not get a new app ID: %v", wantAppid)
+		}
+		if len(shortcuts.Shortcuts) != 2 {
+			t.Fatalf("library has %d shortcuts, want 2", len(shortcuts.Shortcuts))
+		}
+		if sc := shortcuts.Shortcuts["0"]; sc.AppName != "Game" || sc.LaunchOptions != "--old" {
+			t.Errorf("existing shortcut was changed: %+v", sc)
+		}
+		if sc := shortcuts.Shortcuts["1"]; sc.AppName != "Game (2)" || sc.Appid != wantAppid {
+			t.Errorf("renamed shortcut = %+v, want Game (2) with app ID %v", sc, wantAppid)
+		}
+	})
+}
+
+func TestImportShortcutWithoutConflict(t *testing.T) {
+	shortcuts, _ := newImportLibrary(t)
+	incoming := shortcut.Shortcut{AppName: "New Game", Exe: "/usr/bin/new", Appid: 1234}
+	result, err := importShortcut(shortcuts, incoming, "skip")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := ImportResult{AppName: "New Game", Appid: 1234, Action: "added"}
+	if result != want {
+		t.Errorf("importShortcut() = %+v, want %+v", result, want)
+	}
+	if len(shortcuts.Shortcuts) != 2 {
+		t.Errorf("library has %d shortcuts, want 2", len(shortcuts.Shortcuts))
+	}
+}
+
+func TestImportReplaceFlag(t *testing.T) {
+	tests := []struct {
+		args    []string
+		replace bool
+		wantErr bool
+	}{
+		{args: nil, replace: false},
+		{args: []string{"--merge"}, replace: false},
+		{args: []string{"--replace"}, replace: true},
+		{args: []string{"--merge=false", "--replace"}, replace: true},
+		{args: []string{"--merge=false"}, wantErr: true},
+		{args: []string{"--merge", "--replace"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		flags := pflag.NewFlagSet("import", pflag.ContinueOnError)
+		flags.Bool("merge", true, "")
+		flags.Bool("replace", false, "")
+		if err := flags.Parse(tt.args); err != nil {
+			t.Fatal(err)
+		}
+		replace, err := importReplaceFlag(flags)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("importReplaceFlag(%v) error = %v, wantErr %v", tt.args, err, tt.wantErr)
+			continue
+		}
+		if err == nil && replace != tt.replace {
+			t.Errorf("importReplaceFlag(%v) = %v, want %v", tt.args, replace, tt.replace)
+		}
+	}
+}
@@ -6,16 +6,118 @@ Copyright © 2022 William Edwards <shadowapex at gmail.com>
 package cmd
 
 import (
+	"bytes"
+	"encoding/csv"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"os"
+	"sort"
+	"sync"
 
 	"github.com/shadowblip/steam-shortcut-manager/pkg/chimera"
-	"github.com/shadowblip/steam-shortcut-manager/pkg/image/kitty"
+	"github.com/shadowblip/steam-shortcut-manager/pkg/image"
 	"github.com/shadowblip/steam-shortcut-manager/pkg/shortcut"
 	"github.com/shadowblip/steam-shortcut-manager/pkg/steam"
 	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
 )
 
+// ListUserResult holds the shortcuts of a single user along with the user's
+// display name. The JSON and YAML output use ListOutput instead.
+type ListUserResult struct {
+	Persona string `json:"persona"`
+	*shortcut.Shortcuts
+}
+
+// ListSchemaVersion is the version of the JSON and YAML output of the list
+// command. It is increased whenever the shape of the output changes.
+const ListSchemaVersion = 1
+
+// ListOutput is the JSON and YAML output of the list command
+type ListOutput struct {
+	SchemaVersion int                  `json:"schema_version"`
+	Users         map[string]*ListUser `json:"users"`
+}
+
+// ListUser is a user in the output of the list command
+type ListUser struct {
+	ID        string         `json:"id"`
+	Persona   string         `json:"persona"`
+	Shortcuts []ListShortcut `json:"shortcuts"`
+}
+
+// ListShortcut is a shortcut in the output of the list command
+type ListShortcut struct {
+	AppID              int64      `json:"appid"`
+	Name               string     `json:"name"`
+	Exe                string     `json:"exe"`
+	StartDir           string     `json:"start_dir"`
+	LaunchOptions      string     `json:"launch_options"`
+	Icon               string     `json:"icon"`
+	ShortcutPath       string     `json:"shortcut_path"`
+	FlatpakAppID       string     `json:"flatpak_app_id"`
+	Hidden             bool       `json:"hidden"`
+	AllowDesktopConfig bool       `json:"allow_desktop_config"`
+	AllowOverlay       bool       `json:"allow_overlay"`
+	OpenVR             bool       `json:"openvr"`
+	LastPlayTime       int        `json:"last_play_time"`
+	Tags               []string   `json:"tags"`
+	Images             ListImages `json:"images"`
+}
+
+// ListImages holds the resolved artwork paths of a shortcut in the output of
+// the list command. Empty paths mean the image was not found.
+type ListImages struct {
+	Portrait  string   `json:"portrait"`
+	Landscape string   `json:"landscape"`
+	Hero      string   `json:"hero"`
+	Logo      string   `json:"logo"`
+	Icon      string   `json:"icon"`
+	Corrupt   []string `json:"corrupt"`
+}
+
+// newListOutput will convert the shortcuts of each user into the versioned
+// output of the list command
+func newListOutput(results map[string]*ListUserResult) *ListOutput {
+	output := &ListOutput{SchemaVersion: ListSchemaVersion, Users: map[string]*ListUser{}}
+	for user, result := range results {
+		listUser := &ListUser{ID: user, Persona: result.Persona, Shortcuts: []ListShortcut{}}
+		for _, key := range result.Keys() {
+			sc := result.Shortcuts.Shortcuts[key]
+			listShortcut := ListShortcut{
+				AppID:              sc.Appid,
+				Name:               sc.AppName,
+				Exe:                sc.Exe,
+				StartDir:           sc.StartDir,
+				LaunchOptions:      sc.LaunchOptions,
+				Icon:               sc.Icon,
+				ShortcutPath:       sc.ShortcutPath,
+				FlatpakAppID:       sc.FlatpakAppID,
+				Hidden:             sc.IsHidden != 0,
+				AllowDesktopConfig: sc.AllowDesktopConfig != 0,
+				AllowOverlay:       sc.AllowOverlay != 0,
+				OpenVR:             sc.OpenVR != 0,
+				LastPlayTime:       sc.LastPlayTime,
+				Tags:               sc.TagList(),
+				Images:             ListImages{Corrupt: []string{}},
+			}
+			if sc.Images != nil {
+				listShortcut.Images.Portrait = sc.Images.Portrait
+				listShortcut.Images.Landscape = sc.Images.Landscape
+				listShortcut.Images.Hero = sc.Images.Hero
+				listShortcut.Images.Logo = sc.Images.Logo
+				listShortcut.Images.Icon = sc.Images.Icon
+				listShortcut.Images.Corrupt = append(listShortcut.Images.Corrupt, sc.Images.Corrupt...)
+			}
+			listUser.Shortcuts = append(listUser.Shortcuts, listShortcut)
+		}
+		output.Users[user] = listUser
+	}
+	return output
+}
+
 // listCmd represents the list command
 var listCmd = &cobra.Command{
 	Use:   "list",
@@ -23,102 +125,302 @@ var listCmd = &cobra.Command{
 	Long:  `Lists all of the shortcuts registered in Steam`,
 	Run: func(cmd *cobra.Command, args []string) {
 		format := rootCmd.PersistentFlags().Lookup("output").Value.String()
+		image.PreviewWidth, _ = cmd.Flags().GetInt("preview-size")
 
-		// Get users
-		users, err := steam.GetUsers()
-		if err != nil {
-			ExitError(err, format)
-		}
-
-		// Fetch all shortcuts
-		results := map[string]*shortcut.Shortcuts{}
-		for _, user := range users {
-			if !steam.HasShortcuts(user) {
-				continue
-			}
-
-			shortcutsPath, _ := steam.GetShortcutsPath(user)
-			shortcuts, err := shortcut.Load(shortcutsPath)
+		// Get the shortcuts files of the users that have them
+		targets := getShortcutsTargets(cmd, format, func() ([]string, error) {
+			users, err := steam.GetUsers()
 			if err != nil {
-				ExitError(err, format)
+				return nil, err
 			}
 
-			// Optionally Filter by app id
-			if appId, _ := cmd.Flags().GetString("app-id"); appId != "all" {
-				newShortcuts := shortcut.NewShortcuts()
-				for _, sc := range shortcuts.Shortcuts {
-					idStr := fmt.Sprintf("%v", sc.Appid)
-					if idStr != appId {
-						continue
-					}
-					newShortcuts.Add(&sc)
+			// Check to see if we're listing for just one user
+			onlyForUser := getUserFlag(cmd, format)
+			if onlyForUser != "all" {
+				if !contains(users, onlyForUser) {
+					return nil, fmt.Errorf("no Steam user found with ID %v", onlyForUser)
+				}
+				if !steam.HasShortcuts(onlyForUser) {
+					return nil, fmt.Errorf("user %v has no shortcuts", onlyForUser)
 				}
-				shortcuts = newShortcuts
 			}
 
-			// Discover the image paths for the shortcut
-			newShortcuts := shortcut.NewShortcuts()
-			for _, sc := range shortcuts.Shortcuts {
-				idStr := fmt.Sprintf("%v", sc.Appid)
-				images := &shortcut.Images{}
-				images.Logo, _ = steam.GetImageLogo(user, idStr)
-				images.Portrait, _ = steam.GetImagePortrait(user, idStr)
-				images.Landscape, _ = steam.GetImageLandscape(user, idStr)
-				images.Hero, _ = steam.GetImageHero(user, idStr)
-				sc.Images = images
-				newShortcuts.Add(&sc)
+			withShortcuts := []string{}
+			for _, user := range users {
+				if steam.HasShortcuts(user) {
+					withShortcuts = append(withShortcuts, user)
+				}
 			}
+			return withShortcuts, nil
+		})
 
-			results[user] = newShortcuts
+		// Fetch all shortcuts in parallel. Users whose shortcuts fail to load
+		// are reported at the end so the healthy users can still be listed.
+		var appId uint32
+		if appIdFlag, _ := cmd.Flags().GetString("app-id"); appIdFlag != "all" {
+			appId = getAppIDFlag(cmd, format)
 		}
+		results, errs := listTargetsShortcuts(targets, appId)
 
 		// Print the output
 		switch format {
 		case "term":
-			for user, shortcuts := range results {
-				if shortcuts.Shortcuts == nil || len(shortcuts.Shortcuts) == 0 {
+			sortedUsers := make([]string, 0, len(results))
+			for user := range results {
+				sortedUsers = append(sortedUsers, user)
+			}
+			sort.Strings(sortedUsers)
+			for _, user := range sortedUsers {
+				shortcuts := results[user]
+				if shortcuts.Shortcuts == nil || len(shortcuts.Shortcuts.Shortcuts) == 0 {
 					continue
 				}
-				fmt.Println("User:", user)
-				for _, sc := range shortcuts.Shortcuts {
+				switch shortcuts.Persona {
+				case "":
+					fmt.Println("File:", user)
+				case user:
+					fmt.Println("User:", user)
+				default:
+					fmt.Printf("User: %v (%v)\n", shortcuts.Persona, user)
+				}
+				for _, key := range shortcuts.Keys() {
+					sc := shortcuts.Shortcuts.Shortcuts[key]
 					fmt.Println("  ", sc.AppName)
 					fmt.Println("    AppId:         ", sc.Appid)
 					fmt.Println("    Executable:    ", sc.Exe)
 					fmt.Println("    Launch Options:", sc.LaunchOptions)
-					fmt.Println("    Logo Image:    ", sc.Images.Logo)
-					if sc.Images.Logo != "" {
-						kitty.Display(sc.Images.Logo)
-					}
-					fmt.Println("    Portrait Image:", sc.Images.Portrait)
-					if sc.Images.Portrait != "" {
-						kitty.Display(sc.Images.Portrait)
-					}
-					fmt.Println("    Landscape Image:", sc.Images.Landscape)
-					if sc.Images.Landscape != "" {
-						kitty.Display(sc.Images.Landscape)
-					}
-					fmt.Println("    Hero Image:     ", sc.Images.Hero)
-					if sc.Images.Hero != "" {
-						kitty.Display(sc.Images.Hero)
-					}
-					fmt.Println("    Icon Image:     ", sc.Icon)
-					if sc.Icon != "" {
-						kitty.Display(sc.Icon)
-					}
+					printImage("    Logo Image:    ", sc.Images.Logo, sc.Images, format)
+					printImage("    Portrait Image:", sc.Images.Portrait, sc.Images, format)
+					printImage("    Landscape Image:", sc.Images.Landscape, sc.Images, format)
+					printImage("    Hero Image:     ", sc.Images.Hero, sc.Images, format)
+					printImage("    Icon Image:     ", sc.Icon, sc.Images, format)
 				}
 			}
 		case "json":
-			out, err := json.MarshalIndent(results, "", "  ")
+			out, err := json.MarshalIndent(newListOutput(results), "", "  ")
 			if err != nil {
 				ExitError(err, format)
 			}
 			fmt.Println(string(out))
+		case "yaml":
+			out, err := marshalYAML(newListOutput(results))
+			if err != nil {
+				ExitError(err, format)
+			}
+			fmt.Print(string(out))
+		case "csv":
+			if err := writeShortcutsCSV(os.Stdout, results); err != nil {
+				ExitError(err, format)
+			}
 		default:
-			panic("unknown output format: " + format)
+			ExitError(usageErrorf("unknown output format: %s", format), format)
+		}
+
+		if errs != nil {
+			ExitError(errs, format)
 		}
 	},
 }
 
+// listTargetsShortcuts will load the shortcuts of the given targets in
+// parallel, keyed by target name. Targets that fail to load are left out of
+// the results and their errors are returned together.
+func listTargetsShortcuts(targets []shortcutsTarget, appId uint32) (map[string]*ListUserResult, error) {
+	names := []string{}
+	targetsByName := map[string]shortcutsTarget{}
+	for _, target := range targets {
+		names = append(names, target.Name())
+		targetsByName[target.Name()] = target
+	}
+	var mu sync.Mutex
+	results := map[string]*ListUserResult{}
+	errs := forEachUser(names, func(name string) error {
+		result, err := listUserShortcuts(targetsByName[name], appId)
+		if err != nil {
+			return err
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		results[name] = result
+		return nil
+	})
+
+	return results, errs
+}
+
+// listUserShortcuts will load the shortcuts of the given target along with
+// the paths of their images. Images are only looked up for targets that
+// belong to a Steam user. Unless appId is 0, only the shortcut with that app
+// ID is returned.
+func listUserShortcuts(target shortcutsTarget, appId uint32) (*ListUserResult, error) {
+	shortcuts, err := shortcut.Load(target.Path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to load shortcuts for %v: %w", target.Describe(), err)
+	}
+
+	// Optionally Filter by app id
+	if appId != 0 {
+		newShortcuts := shortcut.NewShortcuts()
+		for _, key := range shortcuts.Keys() {
+			sc := shortcuts.Shortcuts[key]
+			if uint32(sc.Appid) != appId {
+				continue
+			}
+			newShortcuts.Add(&sc)
+		}
+		shortcuts = newShortcuts
+	}
+
+	// Discover the image paths for the shortcut. A file that belongs to no
+	// user has no grid folder to look in.
+	newShortcuts := shortcut.NewShortcuts()
+	for _, key := range shortcuts.Keys() {
+		sc := shortcuts.Shortcuts[key]
+		images := &shortcut.Images{}
+		if target.User != "" {
+			images, _, err = steam.ResolveImages(target.User, fmt.Sprintf("%v", sc.Appid))
+			if err != nil {
+				return nil, fmt.Errorf("unable to find images for user %v: %w", target.User, err)
+			}
+		}
+		if sc.Icon != "" {
+			if err := steam.ValidateImage(sc.Icon); errors.Is(err, steam.ErrImageCorrupt) {
+				images.Corrupt = append(images.Corrupt, sc.Icon)
+			}
+		}
+		sc.Images = images
+		newShortcuts.Add(&sc)
+	}
+
+	if target.User == "" {
+		return &ListUserResult{Shortcuts: newShortcuts}, nil
+	}
+	return &ListUserResult{Persona: getUserPersona(target.User), Shortcuts: newShortcuts}, nil
+}
+
+// listCSVHeader is the header row of the list command's CSV output
+var listCSVHeader = []string{"User", "AppName", "AppId", "Exe", "LaunchOptions", "Portrait", "Landscape", "Hero", "Logo", "Icon"}
+
+// writeShortcutsCSV will write the given shortcuts of each user as CSV, one
+// row per shortcut.
+func writeShortcutsCSV(w io.Writer, results map[string]*ListUserResult) error {
+	users := make([]string, 0, len(results))
+	for user := range results {
+		users = append(users, user)
+	}
+	sort.Strings(users)
+
+	out := csv.NewWriter(w)
+	if err := out.Write(listCSVHeader); err != nil {
+		return err
+	}
+	for _, user := range users {
+		shortcuts := results[user]
+		for _, key := range shortcuts.Keys() {
+			sc := shortcuts.Shortcuts.Shortcuts[key]
+			images := sc.Images
+			if images == nil {
+				images = &shortcut.Images{}
+			}
+			row := []string{
+				user,
+				sc.AppName,
+				fmt.Sprintf("%v", sc.Appid),
+				sc.Exe,
+				sc.LaunchOptions,
+				images.Portrait,
+				images.Landscape,
+				images.Hero,
+				images.Logo,
+				sc.Icon,
+			}
+			if err := out.Write(row); err != nil {
+				return err
+			}
+		}
+	}
+	out.Flush()
+	return out.Error()
+}
+
+// marshalYAML will encode the given value as YAML, using the same field names
+// as the JSON output. Numbers are kept as they are, so AppIDs are not turned
+// into floats.
+func marshalYAML(v interface{}) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var generic interface{}
+	if err := dec.Decode(&generic); err != nil {
+		return nil, err
+	}
+	return yaml.Marshal(yamlNumbers(generic))
+}
+
+// yamlNumbers will replace the JSON numbers in the given decoded value with
+// integers, or floats if they have a fraction, so they are not quoted as
+// strings in the YAML output.
+func yamlNumbers(v interface{}) interface{} {
+	switch v := v.(type) {
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i
+		}
+		if f, err := v.Float64(); err == nil {
+			return f
+		}
+		return v.String()
+	case map[string]interface{}:
+		for key, value := range v {
+			v[key] = yamlNumbers(value)
+		}
+	case []interface{}:
+		for i, value := range v {
+			v[i] = yamlNumbers(value)
+		}
+	}
+	return v
+}
+
+// getUserPersona will return the display name of the given user, falling back
+// to the user ID if it cannot be found.
+func getUserPersona(user string) string {
+	persona, err := steam.GetUserPersona(user)
+	if err != nil {
+		DebugPrintln("Unable to find persona for user", user+":", err)
+		return user
+	}
+	return persona
+}
+
+// printImage will print the given image path and render it to the terminal.
+// Corrupt images are flagged instead of rendered.
+func printImage(label, imgPath string, images *shortcut.Images, format string) {
+	if imgPath != "" && images.IsCorrupt(imgPath) {
+		fmt.Println(label, imgPath, "(corrupt)")
+		return
+	}
+	fmt.Println(label, imgPath)
+	if imgPath != "" {
+		displayImage(imgPath, format)
+	}
+}
+
+// displayImage will render the given image to the terminal if the terminal
+// supports a graphics protocol and the output format is term. Otherwise
+// nothing is printed.
+func displayImage(imgPath, format string) {
+	if !image.ShouldRender(format) {
+		return
+	}
+	if err := image.Display(imgPath); err != nil {
+		DebugPrintln("Unable to display image:", err)
+	}
+}
+
 // chimeraListCmd represents the list command
 var chimeraListCmd = &cobra.Command{
 	Use:   "list",
@@ -159,7 +461,7 @@ var chimeraListCmd = &cobra.Command{
 			}
 			fmt.Println(string(out))
 		default:
-			panic("unknown output format: " + format)
+			ExitError(usageErrorf("unknown output format: %s", format), format)
 		}
 
 	},
@@ -170,4 +472,7 @@ func init() {
 	chimeraCmd.AddCommand(chimeraListCmd)
 
 	listCmd.Flags().StringP("app-id", "i", "all", "Only list the given Steam app ID")
+	listCmd.Flags().Int("preview-size", image.DefaultPreviewWidth, "Maximum width in pixels of artwork previews (0 to disable downscaling)")
+	listCmd.Flags().String("user", "all", "Steam user ID to list the shortcuts for (\"all\", \"current\", or an ID)")
+	addShortcutsFileFlag(listCmd)
 }
@@ -0,0 +1,202 @@
+package cmd
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/shadowblip/steam-shortcut-manager/pkg/image"
+	"github.com/shadowblip/steam-shortcut-manager/pkg/shortcut"
+	"github.com/shadowblip/steam-shortcut-manager/pkg/steam"
+)
+
+// captureStdout will return everything the given function prints to stdout
+func captureStdout(t *testing.T, fn func()) string {
+	t.Helper()
+	return captureOutput(t, &os.Stdout, fn)
+}
+
+// captureOutput will return everything the given function writes to the
+// given file, such as os.Stdout or os.Stderr
+func captureOutput(t *testing.T, file **os.File, fn func()) string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	original := *file
+	*file = w
+	defer func() { *file = original }()
+
+	done := make(chan string)
+	go func() {
+		out, _ := io.ReadAll(r)
+		done <- string(out)
+	}()
+	fn()
+	w.Close()
+	return <-done
+}
+
+// fakeDisplay will make images look displayable for the rest of the test and
+// return the images that were rendered
+func fakeDisplay(t *testing.T) *[]string {
+	t.Helper()
+	display, canDisplay := image.Display, image.CanDisplay
+	t.Cleanup(func() { image.Display, image.CanDisplay = display, canDisplay })
+
+	rendered := []string{}
+	image.CanDisplay = true
+	image.Display = func(filename string) error {
+		rendered = append(rendered, filename)
+		return nil
+	}
+	return &rendered
+}
+
+func TestPrintImageFlagsCorruptImage(t *testing.T) {
+	rendered := fakeDisplay(t)
+	images := &shortcut.Images{
+		Portrait: "/grid/1p.png",
+		Hero:     "/grid/1_hero.png",
+		Corrupt:  []string{"/grid/1p.png"},
+	}
+
+	out := captureStdout(t, func() {
+		printImage("Portrait:", images.Portrait, images, "term")
+		printImage("Hero:", images.Hero, images, "term")
+	})
+	if !strings.Contains(out, "/grid/1p.png (corrupt)") {
+		t.Errorf("corrupt image is not flagged in output: %q", out)
+	}
+	if len(*rendered) != 1 || (*rendered)[0] != "/grid/1_hero.png" {
+		t.Errorf("rendered %v, want only the valid hero image", *rendered)
+	}
+}
+
+func TestPrintImageOnlyRendersForTermOutput(t *testing.T) {
+	rendered := fakeDisplay(t)
+	images := &shortcut.Images{Hero: "/grid/1_hero.png"}
+
+	for _, format := range []string{"json", "yaml", "csv"} {
+		out := captureStdout(t, func() {
+			printImage("Hero:", images.Hero, images, format)
+		})
+		if !strings.Contains(out, "/grid/1_hero.png") {
+			t.Errorf("%s output is missing the image path: %q", format, out)
+		}
+	}
+	if len(*rendered) != 0 {
+		t.Errorf("rendered %v for non-term output, want nothing", *rendered)
+	}
+
+	captureStdout(t, func() {
+		printImage("Hero:", images.Hero, images, "term")
+	})
+	if len(*rendered) != 1 {
+		t.Errorf("rendered %v for term output, want the hero image", *rendered)
+	}
+}
+
+func TestListTargetsShortcutsSkipsCorruptUser(t *testing.T) {
+	// One user with a corrupt shortcuts.vdf and one healthy user
+	root := t.TempDir()
+	targets := []shortcutsTarget{}
+	for _, user := range []string{"111", "222"} {
+		configDir := filepath.Join(root, "userdata", user, "config")
+		if err := os.MkdirAll(filepath.Join(configDir, "grid"), 0755); err != nil {
+			t.Fatal(err)
+		}
+		targets = append(targets, shortcutsTarget{User: user, Path: filepath.Join(configDir, "shortcuts.vdf")})
+	}
+	if err := steam.SetBaseDir(root); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { steam.SetBaseDir("") })
+	if err := os.WriteFile(targets[0].Path, []byte("\x00shortcuts\x00\x00garbage"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	shortcuts := shortcut.NewShortcuts()
+	if err := shortcuts.Add(&shortcut.Shortcut{AppName: "Healthy Game", Exe: "/bin/sh"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := shortcut.Save(shortcuts, targets[1].Path); err != nil {
+		t.Fatal(err)
+	}
+
+	results, err := listTargetsShortcuts(targets, 0)
+	if err == nil || !strings.Contains(err.Error(), "user 111") {
+		t.Errorf("listTargetsShortcuts() error = %v, want an error for user 111", err)
+	}
+	if _, ok := results["111"]; ok {
+		t.Error("corrupt user 111 is in the results")
+	}
+	healthy, ok := results["222"]
+	if !ok {
+		t.Fatalf("healthy user 222 is missing from the results: %v", results)
+	}
+	if healthy.Shortcuts == nil || len(healthy.Shortcuts.Shortcuts) != 1 {
+		t.Fatalf("user 222 shortcuts = %+v, want 1 shortcut", healthy.Shortcuts)
+	}
+
+	// The healthy user is still printed
+	out, err := json.Marshal(newListOutput(results))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(out), "Healthy Game") {
+		t.Errorf("list output is missing the healthy user: %s", out)
+	}
+}
+
+func TestListUserShortcutsAppIDAboveInt32(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "shortcuts.vdf")
+	shortcuts := shortcut.NewShortcuts()
+	shortcuts.Add(&shortcut.Shortcut{AppName: "Small", Exe: "/bin/true", Appid: 1234})
+	shortcuts.Add(&shortcut.Shortcut{AppName: "Large", Exe: "/bin/sh", Appid: 3663241086})
+	if err := shortcut.Save(shortcuts, file); err != nil {
+		t.Fatal(err)
+	}
+
+	// Every way of writing the ID selects the same shortcut
+	for _, value := range []string{"3663241086", "-631726210", "3.663241086e+09"} {
+		appId, err := parseAppIDFlag(value)
+		if err != nil {
+			t.Fatalf("parseAppIDFlag(%q) error = %v", value, err)
+		}
+		result, err := listUserShortcuts(shortcutsTarget{Path: file}, appId)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(result.Shortcuts.Shortcuts) != 1 {
+			t.Fatalf("--app-id %v listed %d shortcuts, want 1", value, len(result.Shortcuts.Shortcuts))
+		}
+		if sc := result.Shortcuts.Shortcuts["0"]; sc.AppName != "Large" {
+			t.Errorf("--app-id %v listed %v, want Large", value, sc.AppName)
+		}
+	}
+}
+
+func TestMarshalYAMLKeepsLargeAppIDs(t *testing.T) {
+	shortcuts := shortcut.NewShortcuts()
+	shortcuts.Add(&shortcut.Shortcut{AppName: "Large", Exe: "/bin/sh", Appid: 3663241086})
+
+	out, err := marshalYAML(shortcuts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(out), "appid: 3663241086\n") {
+		t.Errorf("YAML output does not keep the AppID as an integer:\n%s", out)
+	}
+}
+
+func TestParseAppIDFlagRejectsZero(t *testing.T) {
+	for _, value := range []string{"0", "", "all", "-"} {
+		if _, err := parseAppIDFlag(value); err == nil {
+			t.Errorf("parseAppIDFlag(%q) succeeded, want an error", value)
+		}
+	}
+}
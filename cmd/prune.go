@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/shadowblip/steam-shortcut-manager/pkg/steam"
+	"github.com/spf13/cobra"
+)
+
+// pruneArtworkCmd represents the prune-artwork command
+var pruneArtworkCmd = &cobra.Command{
+	Use:   "prune-artwork",
+	Short: "Remove grid artwork that no longer belongs to any shortcut",
+	Long: `Removes grid artwork files left behind for shortcuts that were renamed or
+removed. Artwork for regular Steam games is never touched.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		format := rootCmd.PersistentFlags().Lookup("output").Value.String()
+
+		// Fetch all users
+		users, err := steam.GetUsers()
+		if err != nil {
+			ExitError(err, format)
+		}
+
+		// Check to see if we're pruning for just one user
+		onlyForUser := getUserFlag(cmd, format)
+
+		// Find the orphaned artwork of every user
+		orphaned := map[string][]string{}
+		affected := []string{}
+		for _, user := range users {
+			if onlyForUser != "all" && onlyForUser != user {
+				continue
+			}
+			files, err := steam.FindOrphanedArtwork(user)
+			if err != nil {
+				ExitError(err, format)
+			}
+			if len(files) == 0 {
+				continue
+			}
+			orphaned[user] = files
+			affected = append(affected, files...)
+		}
+
+		// Remove the artwork unless this is a dry run
+		results := orphaned
+		if dryRun, _ := cmd.Flags().GetBool("dry-run"); !dryRun && len(affected) > 0 {
+			confirmChanges(cmd, format, "remove the following artwork", affected)
+
+			results = map[string][]string{}
+			for user := range orphaned {
+				removed, err := steam.RemoveArtworkFiles(orphaned[user])
+				results[user] = removed
+				if err != nil {
+					ExitError(err, format)
+				}
+			}
+		}
+
+		// Print the output
+		switch format {
+		case "term":
+			if len(affected) == 0 {
+				fmt.Println("No orphaned artwork found")
+			}
+			for user, files := range results {
+				fmt.Println("User:", user)
+				for _, file := range files {
+					fmt.Println("  ", file)
+				}
+			}
+		case "json":
+			out, err := json.MarshalIndent(results, "", "  ")
+			if err != nil {
+				ExitError(err, format)
+			}
+			fmt.Println(string(out))
+		default:
+			ExitError(usageErrorf("unknown output format: %s", format), format)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(pruneArtworkCmd)
+
+	pruneArtworkCmd.Flags().String("user", "all", "Steam user ID to prune the artwork for (\"all\", \"current\", or an ID)")
+	pruneArtworkCmd.Flags().Bool("dry-run", false, "Only list the orphaned artwork without removing it")
+	addYesFlag(pruneArtworkCmd)
+}
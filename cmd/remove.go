@@ -7,7 +7,10 @@ package cmd
 
 import (
 	"fmt"
+	"sort"
+	"sync"
 
+	multierror "github.com/hashicorp/go-multierror"
 	"github.com/shadowblip/steam-shortcut-manager/pkg/chimera"
 	"github.com/shadowblip/steam-shortcut-manager/pkg/shortcut"
 	"github.com/shadowblip/steam-shortcut-manager/pkg/steam"
@@ -24,38 +27,41 @@ var removeCmd = &cobra.Command{
 		name := args[0]
 		format := rootCmd.PersistentFlags().Lookup("output").Value.String()
 
-		// Fetch all users
-		users, err := steam.GetUsers()
-		if err != nil {
-			ExitError(err, format)
+		// Get the shortcuts files to remove the shortcut from
+		targets := getShortcutsTargets(cmd, format, steam.GetUsersWithShortcuts)
+		names := []string{}
+		targetsByName := map[string]shortcutsTarget{}
+		for _, target := range targets {
+			names = append(names, target.Name())
+			targetsByName[target.Name()] = target
 		}
 
-		// Check to see if we're fetching for just one user
-		onlyForUser := cmd.Flags().Lookup("user").Value.String()
-
-		// Fetch all shortcuts
-		for _, user := range users {
-			if !steam.HasShortcuts(user) {
-				continue
-			}
-			if onlyForUser != "all" && onlyForUser != user {
-				continue
-			}
-
-			shortcutsPath, _ := steam.GetShortcutsPath(user)
-			shortcuts, err := shortcut.Load(shortcutsPath)
+		// Fetch all shortcuts in parallel. Users whose shortcuts fail to load
+		// are reported at the end without stopping the removal for the others.
+		var mu sync.Mutex
+		changes := map[string]*shortcut.Shortcuts{}
+		affectedByUser := map[string][]string{}
+		errs := forEachUser(names, func(targetName string) error {
+			target := targetsByName[targetName]
+			shortcuts, err := shortcut.Load(target.Path)
 			if err != nil {
-				ExitError(err, format)
+				return fmt.Errorf("unable to load shortcuts for %v: %w", target.Describe(), err)
 			}
 
 			// Find the shortcut to remove by name
+			affected := []string{}
 			shortcutsList := []shortcut.Shortcut{}
-			for _, sc := range shortcuts.Shortcuts {
+			for _, key := range shortcuts.Keys() {
+				sc := shortcuts.Shortcuts[key]
 				if sc.AppName == name {
+					affected = append(affected, fmt.Sprintf("%v (%v) for %v", sc.AppName, sc.Appid, target.Describe()))
 					continue
 				}
 				shortcutsList = append(shortcutsList, sc)
 			}
+			if len(shortcutsList) == len(shortcuts.Shortcuts) {
+				return nil
+			}
 
 			// Create a new shortcuts object that we will save
 			newShortcuts := &shortcut.Shortcuts{
@@ -64,13 +70,49 @@ var removeCmd = &cobra.Command{
 			for key, sc := range shortcutsList {
 				newShortcuts.Shortcuts[fmt.Sprintf("%v", key)] = sc
 			}
+			mu.Lock()
+			defer mu.Unlock()
+			changes[targetName] = newShortcuts
+			affectedByUser[targetName] = affected
+			return nil
+		})
+		if len(changes) == 0 {
+			if errs != nil {
+				ExitError(errs, format)
+			}
+			ExitError(fmt.Errorf("no shortcut found with name: %v", name), format)
+		}
 
-			// Write the changes
-			err = shortcut.Save(newShortcuts, shortcutsPath)
-			if err != nil {
-				ExitError(err, format)
+		// List the affected shortcuts in a stable order
+		changedUsers := make([]string, 0, len(changes))
+		for user := range changes {
+			changedUsers = append(changedUsers, user)
+		}
+		sort.Strings(changedUsers)
+		affected := []string{}
+		for _, user := range changedUsers {
+			affected = append(affected, affectedByUser[user]...)
+		}
+
+		// Make sure the user wants to remove the shortcuts
+		confirmChanges(cmd, format, "remove the following shortcuts", affected)
+
+		// Write the changes
+		saveErrs := forEachUser(changedUsers, func(targetName string) error {
+			target := targetsByName[targetName]
+			if err := shortcut.Save(changes[targetName], target.Path); err != nil {
+				return fmt.Errorf("unable to save shortcuts for %v: %w", target.Describe(), err)
 			}
+			return nil
+		})
+		if saveErrs != nil {
+			errs = multierror.Append(errs, saveErrs)
 		}
+		if errs != nil {
+			ExitError(errs, format)
+		}
+
+		restartSteamIfRequested(cmd, format)
 	},
 }
 
@@ -124,5 +166,8 @@ func init() {
 	rootCmd.AddCommand(removeCmd)
 	chimeraCmd.AddCommand(chimeraRemoveCmd)
 
-	removeCmd.Flags().String("user", "all", "Steam user ID to remove the shortcut for")
+	removeCmd.Flags().String("user", "all", "Steam user ID to remove the shortcut for (\"all\", \"current\", or an ID)")
+	addShortcutsFileFlag(removeCmd)
+	addYesFlag(removeCmd)
+	addRestartFlag(removeCmd)
 }
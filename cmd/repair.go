@@ -0,0 +1,135 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/shadowblip/steam-shortcut-manager/pkg/shortcut"
+	"github.com/shadowblip/steam-shortcut-manager/pkg/steam"
+	"github.com/spf13/cobra"
+)
+
+// RepairResult is the result of checking and repairing the shortcuts file of
+// one user
+type RepairResult struct {
+	Status  string `json:"status"`
+	Error   string `json:"error,omitempty"`
+	Backup  string `json:"backup,omitempty"`
+	Corrupt string `json:"corrupt,omitempty"`
+}
+
+// repairCmd represents the repair command
+var repairCmd = &cobra.Command{
+	Use:   "repair",
+	Short: "Restore corrupt shortcuts files from a backup",
+	Long: `Checks the shortcuts file of each user and restores corrupt files from the
+newest backup that can still be read. The corrupt file is kept next to the
+original with a ".corrupt" suffix.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		format := rootCmd.PersistentFlags().Lookup("output").Value.String()
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+		// Fetch all users
+		users, err := steam.GetUsersWithShortcuts()
+		if err != nil {
+			ExitError(err, format)
+		}
+
+		// Check to see if we're repairing for just one user
+		onlyForUser := getUserFlag(cmd, format)
+
+		// Check the shortcuts file of every user
+		results := map[string]*RepairResult{}
+		restores := map[string]*shortcut.Shortcuts{}
+		affected := []string{}
+		failed := false
+		for _, user := range users {
+			if onlyForUser != "all" && onlyForUser != user {
+				continue
+			}
+
+			shortcutsPath, _ := steam.GetShortcutsPath(user)
+			_, err := shortcut.Load(shortcutsPath)
+			if err == nil {
+				results[user] = &RepairResult{Status: "ok"}
+				continue
+			}
+			if !errors.Is(err, shortcut.ErrCorruptVDF) {
+				ExitError(err, format)
+			}
+
+			result := &RepairResult{Status: "corrupt", Error: err.Error()}
+			results[user] = result
+			shortcuts, backup, err := shortcut.LoadSafe(shortcutsPath)
+			if err != nil {
+				result.Status = "unrecoverable"
+				failed = true
+				continue
+			}
+			result.Backup = backup
+			restores[user] = shortcuts
+			affected = append(affected, fmt.Sprintf("%s (from %s)", shortcutsPath, backup))
+		}
+
+		// Restore the backups unless this is a dry run
+		if !dryRun && len(affected) > 0 {
+			confirmChanges(cmd, format, "restore the following shortcuts files", affected)
+
+			for user, shortcuts := range restores {
+				shortcutsPath, _ := steam.GetShortcutsPath(user)
+				corruptPath := shortcutsPath + ".corrupt"
+				if err := os.Rename(shortcutsPath, corruptPath); err != nil {
+					ExitError(err, format)
+				}
+				if err := shortcut.Save(shortcuts, shortcutsPath); err != nil {
+					ExitError(err, format)
+				}
+				results[user].Status = "repaired"
+				results[user].Corrupt = corruptPath
+			}
+		}
+
+		// Print the output
+		switch format {
+		case "term":
+			for user, result := range results {
+				fmt.Printf("User %v: %s\n", user, result.Status)
+				if result.Error != "" {
+					fmt.Println("  Error: ", result.Error)
+				}
+				if result.Backup != "" {
+					fmt.Println("  Backup:", result.Backup)
+				}
+				if result.Corrupt != "" {
+					fmt.Println("  Corrupt file kept at:", result.Corrupt)
+				}
+			}
+		case "json":
+			out, err := json.MarshalIndent(results, "", "  ")
+			if err != nil {
+				ExitError(err, format)
+			}
+			fmt.Println(string(out))
+		default:
+			ExitError(usageErrorf("unknown output format: %s", format), format)
+		}
+
+		if failed {
+			ExitError(fmt.Errorf("one or more shortcuts files could not be repaired"), format)
+		}
+		if !dryRun && len(restores) > 0 {
+			restartSteamIfRequested(cmd, format)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(repairCmd)
+
+	repairCmd.Flags().String("user", "all", "Steam user ID to repair the shortcuts for (\"all\", \"current\", or an ID)")
+	repairCmd.Flags().Bool("dry-run", false, "Only report corrupt shortcuts files without restoring them")
+	addYesFlag(repairCmd)
+	addRestartFlag(repairCmd)
+}
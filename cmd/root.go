@@ -24,26 +24,49 @@ SOFTWARE.
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
 
+	"github.com/shadowblip/steam-shortcut-manager/pkg/fsutil"
+	"github.com/shadowblip/steam-shortcut-manager/pkg/httpclient"
+	"github.com/shadowblip/steam-shortcut-manager/pkg/logger"
+	"github.com/shadowblip/steam-shortcut-manager/pkg/shortcut"
+	"github.com/shadowblip/steam-shortcut-manager/pkg/steam"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
 
 var cfgFile string
 
+// Version is the version of the program. It is set at build time with
+// -ldflags "-X github.com/shadowblip/steam-shortcut-manager/cmd.Version=<version>".
+var Version = "dev"
+
 // rootCmd represents the base command when called without any subcommands
 var rootCmd = &cobra.Command{
-	Use:   "steam-shortcut-manager",
-	Short: "Manage Steam shortcuts from the CLI",
-	Long:  `Command-line utility for managing your Steam shortcuts`,
+	Use:     "steam-shortcut-manager",
+	Short:   "Manage Steam shortcuts from the CLI",
+	Long:    `Command-line utility for managing your Steam shortcuts`,
+	Version: Version,
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
+// Interrupting the program cancels the context passed to the commands, so long
+// running operations can stop cleanly.
 func Execute() {
-	cobra.CheckErr(rootCmd.Execute())
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := rootCmd.ExecuteContext(ctx); err != nil {
+		stop()
+		os.Exit(ExitCodeUsage)
+	}
 }
 
 func contains(s []string, str string) bool {
@@ -56,15 +79,186 @@ func contains(s []string, str string) bool {
 	return false
 }
 
+// getUserFlag will return the value of the --user flag. The "current"
+// sentinel is resolved to the user that most recently logged into Steam.
+func getUserFlag(cmd *cobra.Command, format string) string {
+	user := cmd.Flags().Lookup("user").Value.String()
+	if user != "current" {
+		return user
+	}
+	current, err := steam.GetMostRecentUser()
+	if err != nil {
+		ExitError(fmt.Errorf("unable to determine the current Steam user: %w", err), format)
+	}
+	DebugPrintln("Resolved current Steam user:", current)
+	return current
+}
+
+// getAppIDFlag will return the shortcut app ID given with the --app-id flag,
+// or 0 if the flag was not given. The ID may be signed or unsigned. Exits
+// with a usage error if the ID is not a valid app ID.
+func getAppIDFlag(cmd *cobra.Command, format string) uint32 {
+	if !cmd.Flags().Changed("app-id") {
+		return 0
+	}
+	value, _ := cmd.Flags().GetString("app-id")
+	appId, err := parseAppIDFlag(value)
+	if err != nil {
+		ExitError(err, format)
+	}
+	return appId
+}
+
+// parseAppIDFlag will parse the given --app-id value. No shortcut has the
+// app ID 0, so it is rejected too.
+func parseAppIDFlag(value string) (uint32, error) {
+	appId, ok := shortcut.ParseAppID(value)
+	if !ok || appId == 0 {
+		return 0, usageErrorf("invalid app ID '%s'", value)
+	}
+	return appId, nil
+}
+
+// shortcutsTarget is a shortcuts file a command operates on. User is the
+// Steam user the file belongs to, and is empty for a file given with
+// --shortcuts-file.
+type shortcutsTarget struct {
+	User string
+	Path string
+}
+
+// Name will return the user of the target, or the path of its file if it
+// belongs to no user
+func (t shortcutsTarget) Name() string {
+	if t.User == "" {
+		return t.Path
+	}
+	return t.User
+}
+
+// Describe will return a description of the target for messages, such as
+// "user 123" or "file shortcuts.vdf"
+func (t shortcutsTarget) Describe() string {
+	if t.User == "" {
+		return "file " + t.Path
+	}
+	return "user " + t.User
+}
+
+// addShortcutsFileFlag will add the --shortcuts-file flag to the given
+// command, which must already have a --user flag
+func addShortcutsFileFlag(cmd *cobra.Command) {
+	cmd.Flags().String("shortcuts-file", "", "Use the given shortcuts.vdf file instead of the files of the Steam users")
+	cmd.MarkFlagsMutuallyExclusive("user", "shortcuts-file")
+}
+
+// getShortcutsTargets will return the shortcuts files the command operates
+// on. With --shortcuts-file, that file is the only target and no Steam users
+// are looked up. Otherwise every user returned by listUsers is a target,
+// unless --user selects just one.
+func getShortcutsTargets(cmd *cobra.Command, format string, listUsers func() ([]string, error)) []shortcutsTarget {
+	if shortcutsFile, _ := cmd.Flags().GetString("shortcuts-file"); shortcutsFile != "" {
+		DebugPrintln("Using shortcuts file:", shortcutsFile)
+		return []shortcutsTarget{{Path: shortcutsFile}}
+	}
+
+	users, err := listUsers()
+	if err != nil {
+		ExitError(err, format)
+	}
+	onlyForUser := getUserFlag(cmd, format)
+	targets := []shortcutsTarget{}
+	for _, user := range users {
+		if onlyForUser != "all" && onlyForUser != user {
+			continue
+		}
+		shortcutsPath, err := steam.GetShortcutsPath(user)
+		if err != nil {
+			ExitError(err, format)
+		}
+		targets = append(targets, shortcutsTarget{User: user, Path: shortcutsPath})
+	}
+	return targets
+}
+
+// parseOwner will parse a file owner given as "uid[:gid]". A missing group
+// ID is returned as -1.
+func parseOwner(owner string) (int, int, error) {
+	uidStr, gidStr, hasGID := strings.Cut(owner, ":")
+	uid, err := strconv.Atoi(uidStr)
+	if err != nil || uid < 0 {
+		return 0, 0, fmt.Errorf("invalid owner '%s' (must be \"uid[:gid]\")", owner)
+	}
+	gid := -1
+	if hasGID {
+		gid, err = strconv.Atoi(gidStr)
+		if err != nil || gid < 0 {
+			return 0, 0, fmt.Errorf("invalid owner '%s' (must be \"uid[:gid]\")", owner)
+		}
+	}
+	return uid, gid, nil
+}
+
+// addRestartFlag will add the --restart-steam flag to the given command
+func addRestartFlag(cmd *cobra.Command) {
+	cmd.Flags().Bool("restart-steam", false, "Restart Steam after making changes so they are picked up")
+}
+
+// restartSteamIfRequested will restart Steam if the --restart-steam flag was
+// given.
+func restartSteamIfRequested(cmd *cobra.Command, format string) {
+	if restart, _ := cmd.Flags().GetBool("restart-steam"); !restart {
+		return
+	}
+	DebugPrintln("Restarting Steam")
+	if err := steam.Restart(); err != nil {
+		ExitError(err, format)
+	}
+}
+
 func init() {
 	cobra.OnInitialize(initConfig)
 
-	rootCmd.PersistentFlags().StringP("output", "o", "term", "Output format (json, term)")
+	rootCmd.PersistentFlags().StringP("output", "o", "term", "Output format (json, term, yaml, csv)")
+	rootCmd.PersistentFlags().BoolP("verbose", "v", false, "Print debug messages")
+	rootCmd.PersistentFlags().BoolP("quiet", "q", false, "Only print errors")
+	rootCmd.MarkFlagsMutuallyExclusive("verbose", "quiet")
+	rootCmd.PersistentFlags().Bool("no-cache", false, "Always query SteamGridDB and download artwork instead of using the caches")
+	rootCmd.PersistentFlags().Duration("timeout", httpclient.DefaultTimeout, "Timeout for each network request")
+	rootCmd.PersistentFlags().String("steam-root", "", "Steam directory to use instead of detecting it (default is $"+steam.SteamRootEnv+")")
+	rootCmd.PersistentFlags().String("chown", "", "User and group ID to give written files, as \"uid[:gid]\" (default is the owner of the file or folder being written to)")
+	rootCmd.PersistentFlags().String("user-agent", "", "User-Agent to send with network requests (default is "+httpclient.DefaultUserAgent+"/<version>)")
+	rootCmd.PersistentFlags().StringArray("header", nil, `Extra header to send with network requests, as "Name: value" (can be repeated)`)
+	rootCmd.PersistentFlags().Bool("offline", false, "Never make network requests, only use local artwork files")
+	rootCmd.PersistentFlags().String("proxy", "", "Proxy URL for all network requests (default is $HTTPS_PROXY or $HTTP_PROXY)")
+	viper.BindPFlag("proxy", rootCmd.PersistentFlags().Lookup("proxy"))
 	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.steam-shortcut-manager.yaml)")
 }
 
 // initConfig reads in config file and ENV variables if set.
 func initConfig() {
+	timeout, _ := rootCmd.PersistentFlags().GetDuration("timeout")
+	httpclient.SetTimeout(timeout)
+	httpclient.Offline, _ = rootCmd.PersistentFlags().GetBool("offline")
+	if noCache, _ := rootCmd.PersistentFlags().GetBool("no-cache"); noCache {
+		steam.UseImageCache = false
+	}
+	steamRoot, _ := rootCmd.PersistentFlags().GetString("steam-root")
+	if steamRoot == "" {
+		steamRoot = os.Getenv(steam.SteamRootEnv)
+	}
+	if err := steam.SetBaseDir(steamRoot); err != nil {
+		format := rootCmd.PersistentFlags().Lookup("output").Value.String()
+		ExitError(usageErrorf("invalid Steam directory: %v", err), format)
+	}
+
+	if verbose, _ := rootCmd.PersistentFlags().GetBool("verbose"); verbose {
+		logger.SetLevel(logger.LevelDebug)
+	}
+	if quiet, _ := rootCmd.PersistentFlags().GetBool("quiet"); quiet {
+		logger.SetLevel(logger.LevelError)
+	}
+
 	if cfgFile != "" {
 		// Use config file from the flag.
 		viper.SetConfigFile(cfgFile)
@@ -85,4 +279,36 @@ func initConfig() {
 	if err := viper.ReadInConfig(); err == nil {
 		fmt.Fprintln(os.Stderr, "Using config file:", viper.ConfigFileUsed())
 	}
+
+	// Set the owner of written files
+	if owner, _ := rootCmd.PersistentFlags().GetString("chown"); owner != "" {
+		uid, gid, err := parseOwner(owner)
+		if err != nil {
+			format := rootCmd.PersistentFlags().Lookup("output").Value.String()
+			ExitError(usageErrorf("%v", err), format)
+		}
+		fsutil.SetOwner(uid, gid)
+	}
+
+	// Set the User-Agent and extra headers of network requests
+	userAgent, _ := rootCmd.PersistentFlags().GetString("user-agent")
+	if userAgent == "" {
+		userAgent = httpclient.DefaultUserAgent + "/" + Version
+	}
+	httpclient.SetUserAgent(userAgent)
+	headers, _ := rootCmd.PersistentFlags().GetStringArray("header")
+	for _, header := range headers {
+		name, value, ok := strings.Cut(header, ":")
+		if !ok || strings.TrimSpace(name) == "" {
+			format := rootCmd.PersistentFlags().Lookup("output").Value.String()
+			ExitError(usageErrorf("invalid header '%s' (must be \"Name: value\")", header), format)
+		}
+		httpclient.SetHeader(strings.TrimSpace(name), strings.TrimSpace(value))
+	}
+
+	// Use the proxy from the flag or config file over the environment
+	if err := httpclient.SetProxy(viper.GetString("proxy")); err != nil {
+		format := rootCmd.PersistentFlags().Lookup("output").Value.String()
+		ExitError(usageErrorf("%v", err), format)
+	}
 }
@@ -26,7 +26,9 @@ package cmd
 import (
 	"encoding/json"
 	"fmt"
+	"os"
 	"path"
+	"path/filepath"
 
 	"github.com/shadowblip/steam-shortcut-manager/pkg/image"
 	"github.com/shadowblip/steam-shortcut-manager/pkg/steamgriddb"
@@ -57,14 +59,16 @@ type SearchOutput struct {
 	Icons   []steamgriddb.ImageResponseData `json:"icons"`
 }
 
-// Prints the search output to the terminal
-func (s *SearchOutput) Print(client *steamgriddb.Client) {
+// Prints the search output to the terminal. Thumbnails are only rendered if
+// the output format is term.
+func (s *SearchOutput) Print(client *steamgriddb.Client, format string) {
+	render := image.ShouldRender(format)
 	fmt.Println(s.Details.Name)
 	fmt.Println("  App ID:", s.Details.ID)
 	for _, data := range s.Grids {
-		filename := path.Base(data.Thumb)
-		if image.CanDisplay {
-			err := client.CachedDownload(data.Thumb, fmt.Sprintf("/tmp/%v", filename))
+		thumbFile := thumbnailPath(data.Thumb)
+		if render {
+			err := client.CachedDownload(data.Thumb, thumbFile)
 			if err != nil {
 				continue
 			}
@@ -74,14 +78,14 @@ func (s *SearchOutput) Print(client *steamgriddb.Client) {
 		fmt.Println("    Style:", data.Style)
 		fmt.Println("    Author:", data.Author.Name)
 		fmt.Println("    URL:", data.URL)
-		if image.CanDisplay {
-			image.Display("/tmp/" + filename)
+		if render {
+			image.Display(thumbFile)
 		}
 	}
 	for _, data := range s.Logos {
-		filename := path.Base(data.Thumb)
-		if image.CanDisplay {
-			err := client.CachedDownload(data.Thumb, fmt.Sprintf("/tmp/%v", filename))
+		thumbFile := thumbnailPath(data.Thumb)
+		if render {
+			err := client.CachedDownload(data.Thumb, thumbFile)
 			if err != nil {
 				continue
 			}
@@ -91,14 +95,14 @@ func (s *SearchOutput) Print(client *steamgriddb.Client) {
 		fmt.Println("    Style:", data.Style)
 		fmt.Println("    Author:", data.Author.Name)
 		fmt.Println("    URL:", data.URL)
-		if image.CanDisplay {
-			image.Display("/tmp/" + filename)
+		if render {
+			image.Display(thumbFile)
 		}
 	}
 	for _, data := range s.Icons {
-		filename := path.Base(data.Thumb)
-		if image.CanDisplay {
-			err := client.CachedDownload(data.Thumb, fmt.Sprintf("/tmp/%v", filename))
+		thumbFile := thumbnailPath(data.Thumb)
+		if render {
+			err := client.CachedDownload(data.Thumb, thumbFile)
 			if err != nil {
 				continue
 			}
@@ -108,14 +112,14 @@ func (s *SearchOutput) Print(client *steamgriddb.Client) {
 		fmt.Println("    Style:", data.Style)
 		fmt.Println("    Author:", data.Author.Name)
 		fmt.Println("    URL:", data.URL)
-		if image.CanDisplay {
-			image.Display("/tmp/" + filename)
+		if render {
+			image.Display(thumbFile)
 		}
 	}
 	for _, data := range s.Heroes {
-		filename := path.Base(data.Thumb)
-		if image.CanDisplay {
-			err := client.CachedDownload(data.Thumb, fmt.Sprintf("/tmp/%v", filename))
+		thumbFile := thumbnailPath(data.Thumb)
+		if render {
+			err := client.CachedDownload(data.Thumb, thumbFile)
 			if err != nil {
 				continue
 			}
@@ -125,33 +129,32 @@ func (s *SearchOutput) Print(client *steamgriddb.Client) {
 		fmt.Println("    Style:", data.Style)
 		fmt.Println("    Author:", data.Author.Name)
 		fmt.Println("    URL:", data.URL)
-		if image.CanDisplay {
-			image.Display("/tmp/" + filename)
+		if render {
+			image.Display(thumbFile)
 		}
 	}
 }
 
+// thumbnailPath will return the local path used to cache the given thumbnail
+// URL in the system temp directory.
+func thumbnailPath(thumbURL string) string {
+	return filepath.Join(os.TempDir(), "steam-shortcut-manager", "thumbs", path.Base(thumbURL))
+}
+
 // search for SteamGridDB images
 func search(cmd *cobra.Command, args []string, kind SearchType) {
 	format := rootCmd.PersistentFlags().Lookup("output").Value.String()
 
-	// Ensure we have a SteamGridDB API Key
-	apiKey, _ := cmd.Flags().GetString("api-key")
-	if apiKey == "" {
-		cmd.Help()
-		ExitError(fmt.Errorf("API key is required"), format)
-	}
-
 	// Create a SteamGridDB Client
-	client := steamgriddb.NewClient(apiKey)
+	client := newGridDBClient(cmd, format)
 	results, err := client.Search(args[0])
 	if err != nil {
-		panic(err)
+		ExitError(err, format)
 	}
 
 	// Error if not success
 	if !results.Success {
-		panic(fmt.Errorf("%v", results.Errors))
+		ExitError(fmt.Errorf("%v", results.Errors), format)
 	}
 
 	// Filter our results
@@ -175,6 +178,10 @@ func search(cmd *cobra.Command, args []string, kind SearchType) {
 			Icons:   []steamgriddb.ImageResponseData{},
 		}
 		maxImages := getFlagInt(cmd, "max-images")
+		query := []steamgriddb.QueryFilter{}
+		if page := getFlagInt(cmd, "page"); page > 0 {
+			query = append(query, steamgriddb.FilterPage(page))
+		}
 
 		// Get all grid images
 		if kind.Has(SearchGrids) {
@@ -185,9 +192,9 @@ func search(cmd *cobra.Command, args []string, kind SearchType) {
 			}
 
 			// Get the grids
-			grids, err := client.GetGrids(appID, filters...)
+			grids, err := client.GetGridsWithQuery(appID, query, filters...)
 			if err != nil {
-				panic(err)
+				ExitError(err, format)
 			}
 			num := maxImages
 			if num > len(grids.Data) {
@@ -206,9 +213,9 @@ func search(cmd *cobra.Command, args []string, kind SearchType) {
 			}
 
 			// Get the heroes
-			heroes, err := client.GetHeroes(appID, filters...)
+			heroes, err := client.GetHeroesWithQuery(appID, query, filters...)
 			if err != nil {
-				panic(err)
+				ExitError(err, format)
 			}
 			num := maxImages
 			if num > len(heroes.Data) {
@@ -227,9 +234,9 @@ func search(cmd *cobra.Command, args []string, kind SearchType) {
 			}
 
 			// Get the logos
-			logos, err := client.GetLogos(appID)
+			logos, err := client.GetLogosWithQuery(appID, query)
 			if err != nil {
-				panic(err)
+				ExitError(err, format)
 			}
 			num := maxImages
 			if num > len(logos.Data) {
@@ -248,9 +255,9 @@ func search(cmd *cobra.Command, args []string, kind SearchType) {
 			}
 
 			// Get the icons
-			icons, err := client.GetIcons(appID)
+			icons, err := client.GetIconsWithQuery(appID, query)
 			if err != nil {
-				panic(err)
+				ExitError(err, format)
 			}
 			num := maxImages
 			if num > len(icons.Data) {
@@ -266,16 +273,16 @@ func search(cmd *cobra.Command, args []string, kind SearchType) {
 	switch format {
 	case "term":
 		for _, result := range searchResult {
-			result.Print(client)
+			result.Print(client, format)
 		}
 	case "json":
 		out, err := json.MarshalIndent(searchResult, "", "  ")
 		if err != nil {
-			panic(err)
+			ExitError(err, format)
 		}
 		fmt.Println(string(out))
 	default:
-		panic("unknown output format: " + format)
+		ExitError(usageErrorf("unknown output format: %s", format), format)
 	}
 }
 
@@ -324,6 +331,7 @@ func init() {
 	// is called directly, e.g.:
 	searchCmd.PersistentFlags().IntP("max-results", "n", 1, "Number of search results to return")
 	searchCmd.PersistentFlags().Int("max-images", 1, "Number of image results to return for a given image type")
+	searchCmd.PersistentFlags().Int("page", 0, "Zero-based page of image results to return")
 	searchCmd.PersistentFlags().Bool("only-heroes", false, "Only include hero images in search")
 	searchCmd.PersistentFlags().Bool("only-grids", false, "Only include grid images in search")
 	searchCmd.PersistentFlags().Bool("only-icons", false, "Only include icon images in search")
@@ -24,14 +24,21 @@ SOFTWARE.
 package cmd
 
 import (
+	"errors"
+	"fmt"
+
+	"github.com/shadowblip/steam-shortcut-manager/pkg/logger"
+	"github.com/shadowblip/steam-shortcut-manager/pkg/steam"
+	"github.com/shadowblip/steam-shortcut-manager/pkg/steamgriddb"
 	"github.com/spf13/cobra"
 )
 
 // steamgriddbCmd represents the steamgriddb command
 var steamgriddbCmd = &cobra.Command{
-	Use:   "steamgriddb",
-	Short: "Search and download artwork from SteamGridDB",
-	Long:  `Search and download artwork from SteamGridDB`,
+	Use:     "steamgriddb",
+	Aliases: []string{"griddb"},
+	Short:   "Search and download artwork from SteamGridDB",
+	Long:    `Search and download artwork from SteamGridDB`,
 	Run: func(cmd *cobra.Command, args []string) {
 		cmd.Help()
 	},
@@ -44,10 +51,124 @@ func init() {
 
 	// Cobra supports Persistent Flags which will work for this command
 	// and all subcommands, e.g.:
-	steamgriddbCmd.PersistentFlags().StringP("api-key", "k", "", "SteamGridDB API Key")
-	steamgriddbCmd.MarkFlagRequired("api-key")
+	steamgriddbCmd.PersistentFlags().StringP("api-key", "k", "", "SteamGridDB API Key (default is $"+steamgriddb.APIKeyEnv+")")
+	steamgriddbCmd.PersistentFlags().Float64("rate-limit", steamgriddb.DefaultRateLimit, "Maximum SteamGridDB requests per second (0 disables the limit)")
+	steamgriddbCmd.PersistentFlags().String("strategy", "first", strategyFlagUsage)
 
 	// Cobra supports local flags which will only run when this command
 	// is called directly, e.g.:
 	// steamgriddbCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
 }
+
+// strategyFlagUsage is the usage of the --strategy flag of the commands that
+// fetch artwork from SteamGridDB
+const strategyFlagUsage = "How to choose SteamGridDB artwork: \"first\" result, highest \"votes\", or \"largest\" dimensions"
+
+// newGridDBClient will create a SteamGridDB client using the api-key flag or
+// the STEAMGRIDDB_API_KEY environment variable and verify that the key is
+// valid before any other work is done.
+func newGridDBClient(cmd *cobra.Command, format string, opts ...steamgriddb.Option) *steamgriddb.Client {
+	apiKey, _ := cmd.Flags().GetString("api-key")
+	if cmd.Flags().Lookup("rate-limit") != nil {
+		rps, _ := cmd.Flags().GetFloat64("rate-limit")
+		opts = append(opts, steamgriddb.WithRateLimit(rps, steamgriddb.DefaultRateBurst))
+	}
+	if cmd.Flags().Lookup("strategy") != nil {
+		value, _ := cmd.Flags().GetString("strategy")
+		strategy, err := steamgriddb.ParseStrategy(value)
+		if err != nil {
+			ExitError(usageErrorf("%v", err), format)
+		}
+		opts = append(opts, steamgriddb.WithStrategy(strategy))
+	}
+	if noCache, _ := cmd.Flags().GetBool("no-cache"); !noCache {
+		cacheDir, err := steamgriddb.DefaultCacheDir()
+		if err != nil {
+			DebugPrintln("Unable to find cache directory:", err)
+		} else {
+			opts = append(opts, steamgriddb.WithCache(cacheDir, steamgriddb.DefaultCacheTTL))
+		}
+	}
+	client := steamgriddb.NewClient(apiKey, opts...).WithContext(cmd.Context())
+	if !client.HasAPIKey() {
+		cmd.Help()
+		ExitError(usageErrorf("API key is required (use --api-key or set %s)", steamgriddb.APIKeyEnv), format)
+	}
+
+	if err := client.ValidateKey(); err != nil {
+		ExitError(err, format)
+	}
+
+	return client
+}
+
+// checkFetchError will handle the error of fetching artwork from SteamGridDB.
+// If nothing was fetched, the command exits with the error. Otherwise the
+// lookups that failed are reported as a warning, so the artwork that was
+// found is still applied.
+func checkFetchError(artwork *steam.ArtworkConfig, err error, format string) {
+	if err == nil {
+		return
+	}
+	if artwork == nil || *artwork == (steam.ArtworkConfig{}) {
+		ExitError(err, format)
+	}
+	logger.Warningf("Some artwork could not be fetched: %v", err)
+}
+
+// resolveGameID will return the SteamGridDB game ID to use for the shortcut
+// with the given name and app ID. If the shortcut wraps a real Steam game, the
+// game is looked up by its exact Steam app ID. Otherwise a previously stored
+// match is reused unless refresh is set; failing that, the first search
+// result is used and stored.
+func resolveGameID(client *steamgriddb.Client, name, appID string, steamAppID uint64, refresh bool) (string, error) {
+	// Prefer an exact match by Steam app ID
+	if steamAppID != 0 {
+		game, err := client.GetGameBySteamAppID(steamAppID)
+		if err == nil {
+			DebugPrintln("Found SteamGridDB game for Steam app", steamAppID, "with ID", game.ID)
+			return fmt.Sprintf("%v", game.ID), nil
+		}
+		if !errors.Is(err, steamgriddb.ErrNotFound) {
+			return "", err
+		}
+		DebugPrintln("No SteamGridDB game for Steam app", steamAppID, "falling back to search")
+	}
+
+	// Load the stored matches
+	var cache *steamgriddb.MatchCache
+	cachePath, err := steamgriddb.DefaultMatchCachePath()
+	if err == nil {
+		cache, err = steamgriddb.LoadMatchCache(cachePath)
+	}
+	if err != nil {
+		DebugPrintln("Unable to load match cache:", err)
+	}
+	if cache != nil && !refresh {
+		if gameID, ok := cache.Get(appID); ok {
+			DebugPrintln("Using stored SteamGridDB match for", appID+":", gameID)
+			return gameID, nil
+		}
+	}
+
+	// Search for the game
+	results, err := client.Search(name)
+	if err != nil {
+		return "", err
+	}
+	if len(results.Data) == 0 {
+		return "", fmt.Errorf("no results found for %v", name)
+	}
+	DebugPrintln(fmt.Sprintf("Found %v results for %s", len(results.Data), name))
+
+	// Use the first result and remember it for next time
+	gameID := fmt.Sprintf("%v", results.Data[0].ID)
+	if cache != nil {
+		cache.Set(appID, gameID)
+		if err := cache.Save(); err != nil {
+			DebugPrintln("Unable to save match cache:", err)
+		}
+	}
+
+	return gameID, nil
+}
@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/shadowblip/steam-shortcut-manager/pkg/steamgriddb"
+)
+
+func TestResolveGameIDReusesStoredMatch(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+
+	// Serve a different first search result on every search
+	searches := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.URL.Path, "/search/autocomplete/") {
+			t.Errorf("unexpected request to %v", r.URL)
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		searches++
+		fmt.Fprintf(w, `{"success":true,"data":[{"id":%d,"name":"Test Game"},{"id":999,"name":"Test Game 2"}]}`, 100*searches)
+	}))
+	defer server.Close()
+	client := steamgriddb.NewClient("key", steamgriddb.WithBaseURL(server.URL), steamgriddb.WithRateLimit(0, 0))
+
+	first, err := resolveGameID(client, "Test Game", "3663241086", 0, false)
+	if err != nil {
+		t.Fatalf("first resolveGameID() error = %v", err)
+	}
+	if first != "100" {
+		t.Fatalf("first resolveGameID() = %v, want 100", first)
+	}
+
+	// The second apply must reuse the stored game ID without searching
+	second, err := resolveGameID(client, "Test Game", "3663241086", 0, false)
+	if err != nil {
+		t.Fatalf("second resolveGameID() error = %v", err)
+	}
+	if second != first {
+		t.Errorf("second resolveGameID() = %v, want stored %v", second, first)
+	}
+	if searches != 1 {
+		t.Errorf("searched %d times, want 1", searches)
+	}
+
+	// Another shortcut does not share the match
+	other, err := resolveGameID(client, "Test Game", "1234", 0, false)
+	if err != nil {
+		t.Fatalf("resolveGameID() for another shortcut error = %v", err)
+	}
+	if other != "200" {
+		t.Errorf("resolveGameID() for another shortcut = %v, want 200", other)
+	}
+
+	// --refresh-match searches again and stores the new match
+	refreshed, err := resolveGameID(client, "Test Game", "3663241086", 0, true)
+	if err != nil {
+		t.Fatalf("refreshed resolveGameID() error = %v", err)
+	}
+	if refreshed != "300" {
+		t.Errorf("refreshed resolveGameID() = %v, want 300", refreshed)
+	}
+	again, err := resolveGameID(client, "Test Game", "3663241086", 0, false)
+	if err != nil {
+		t.Fatalf("resolveGameID() after refresh error = %v", err)
+	}
+	if again != refreshed {
+		t.Errorf("resolveGameID() after refresh = %v, want %v", again, refreshed)
+	}
+	if searches != 3 {
+		t.Errorf("searched %d times, want 3", searches)
+	}
+}
@@ -0,0 +1,313 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	multierror "github.com/hashicorp/go-multierror"
+	"github.com/shadowblip/steam-shortcut-manager/pkg/chimera"
+	"github.com/shadowblip/steam-shortcut-manager/pkg/shortcut"
+	"github.com/shadowblip/steam-shortcut-manager/pkg/steam"
+	"github.com/spf13/cobra"
+)
+
+// SyncResult is the result of syncing a single shortcut
+type SyncResult struct {
+	Name   string `json:"name"`
+	User   string `json:"user,omitempty"`
+	Action string `json:"action"`
+}
+
+// chimeraSyncToSteamCmd represents the sync-to-steam command
+var chimeraSyncToSteamCmd = &cobra.Command{
+	Use:   "sync-to-steam",
+	Short: "Add Chimera shortcuts to Steam",
+	Long: `Creates a Steam shortcut for every Chimera shortcut of the platform. Shortcuts
+that already exist in Steam with the same name are skipped. Chimera images are
+copied to the Steam grid folder.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		format := rootCmd.PersistentFlags().Lookup("output").Value.String()
+		shortcutsFile := loadChimeraPlatformFile(format)
+		chimeraShortcuts, err := chimera.LoadShortcuts(shortcutsFile)
+		if err != nil {
+			ExitError(err, format)
+		}
+
+		// Fetch all users
+		users, err := steam.GetUsers()
+		if err != nil {
+			ExitError(err, format)
+		}
+
+		// Check to see if we're syncing for just one user
+		onlyForUser := getUserFlag(cmd, format)
+
+		var errs error
+		results := []SyncResult{}
+		for _, user := range users {
+			if onlyForUser != "all" && onlyForUser != user {
+				continue
+			}
+
+			// Load existing shortcuts or create empty one
+			shortcutsPath, _ := steam.GetShortcutsPath(user)
+			shortcuts, err := shortcut.LoadOrNew(shortcutsPath)
+			if err != nil {
+				ExitError(err, format)
+			}
+
+			changed := false
+			for _, chimeraShortcut := range chimeraShortcuts {
+				if _, exists := shortcuts.FindKey(chimeraShortcut.Name, 0); exists {
+					results = append(results, SyncResult{Name: chimeraShortcut.Name, User: user, Action: "skipped"})
+					continue
+				}
+				sc, err := steamShortcutFromChimera(chimeraShortcut)
+				if err != nil {
+					errs = multierror.Append(errs, fmt.Errorf("%s: %w", chimeraShortcut.Name, err))
+					continue
+				}
+				if err := copyChimeraImages(user, chimeraShortcut, sc); err != nil {
+					errs = multierror.Append(errs, fmt.Errorf("%s: %w", sc.AppName, err))
+				}
+				if err := shortcuts.Add(sc); err != nil {
+					ExitError(err, format)
+				}
+				changed = true
+				results = append(results, SyncResult{Name: sc.AppName, User: user, Action: "added"})
+			}
+
+			if !changed {
+				continue
+			}
+			if err := shortcut.Save(shortcuts, shortcutsPath); err != nil {
+				ExitError(err, format)
+			}
+		}
+
+		printSyncResults(results, format)
+		if errs != nil {
+			ExitError(errs, format)
+		}
+		restartSteamIfRequested(cmd, format)
+	},
+}
+
+// chimeraSyncFromSteamCmd represents the sync-from-steam command
+var chimeraSyncFromSteamCmd = &cobra.Command{
+	Use:   "sync-from-steam",
+	Short: "Add Steam shortcuts to Chimera",
+	Long: `Creates a Chimera shortcut of the platform for every Steam shortcut. Shortcuts
+that already exist in Chimera with the same name are skipped. The Chimera
+shortcuts reference the Steam grid images.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		format := rootCmd.PersistentFlags().Lookup("output").Value.String()
+		shortcutsFile := loadChimeraPlatformFile(format)
+		chimeraShortcuts, err := chimera.LoadShortcuts(shortcutsFile)
+		if err != nil {
+			ExitError(err, format)
+		}
+
+		// Fetch all users
+		users, err := steam.GetUsersWithShortcuts()
+		if err != nil {
+			ExitError(err, format)
+		}
+
+		// Check to see if we're syncing for just one user
+		onlyForUser := getUserFlag(cmd, format)
+
+		existing := map[string]bool{}
+		for _, sc := range chimeraShortcuts {
+			existing[sc.Name] = true
+		}
+
+		results := []SyncResult{}
+		for _, user := range users {
+			if onlyForUser != "all" && onlyForUser != user {
+				continue
+			}
+
+			shortcutsPath, _ := steam.GetShortcutsPath(user)
+			shortcuts, err := shortcut.Load(shortcutsPath)
+			if err != nil {
+				ExitError(err, format)
+			}
+
+			for _, key := range shortcuts.Keys() {
+				sc := shortcuts.Shortcuts[key]
+				if existing[sc.AppName] {
+					results = append(results, SyncResult{Name: sc.AppName, User: user, Action: "skipped"})
+					continue
+				}
+				existing[sc.AppName] = true
+				chimeraShortcuts = append(chimeraShortcuts, chimeraShortcutFromSteam(user, &sc))
+				results = append(results, SyncResult{Name: sc.AppName, User: user, Action: "added"})
+			}
+		}
+
+		// Save the shortcuts
+		if err := chimera.SaveShortcuts(shortcutsFile, chimeraShortcuts); err != nil {
+			ExitError(err, format)
+		}
+
+		printSyncResults(results, format)
+	},
+}
+
+// loadChimeraPlatformFile will ensure the Chimera shortcuts file of the
+// selected platform exists and return its path
+func loadChimeraPlatformFile(format string) string {
+	if !chimera.HasChimera() {
+		ExitError(fmt.Errorf("no chimera config found at %v", chimera.ConfigDir), format)
+	}
+
+	// Get the platform flag
+	platform := chimeraCmd.PersistentFlags().Lookup("platform").Value.String()
+	DebugPrintln("Using Chimera platform:", platform)
+
+	// Ensure the Chimera shortcuts file exists
+	if err := chimera.EnsureShortcutsFileExists(platform); err != nil {
+		ExitError(err, format)
+	}
+
+	return chimera.GetShortcutsFile(platform)
+}
+
+// steamShortcutFromChimera will return a new Steam shortcut for the given
+// Chimera shortcut. If the whole Chimera command is an existing file, it is
+// used as the executable. Otherwise the command is split with shell rules,
+// and the first word is used as the executable and the rest as launch
+// options. The paths are quoted the way add quotes them, so the shortcut
+// gets the same app ID.
+func steamShortcutFromChimera(chimeraShortcut *chimera.Shortcut) (*shortcut.Shortcut, error) {
+	exe, launchOptions := strings.TrimSpace(chimeraShortcut.Cmd), ""
+	if _, err := os.Stat(expandHome(exe)); err == nil {
+		exe = expandHome(exe)
+	} else {
+		words, err := shortcut.SplitCommand(exe)
+		if err != nil {
+			return nil, err
+		}
+		if len(words) > 0 {
+			exe = words[0]
+			args := make([]string, 0, len(words)-1)
+			for _, word := range words[1:] {
+				args = append(args, shortcut.QuoteCommandWord(word))
+			}
+			launchOptions = strings.Join(args, " ")
+		}
+	}
+
+	sc := shortcut.NewShortcut(chimeraShortcut.Name, exe, func(s *shortcut.Shortcut) {
+		s.AllowDesktopConfig = 1
+		s.AllowOverlay = 1
+		s.LaunchOptions = launchOptions
+		s.StartDir = expandHome(chimeraShortcut.Dir)
+		if s.StartDir == "" {
+			s.StartDir = shortcut.DefaultStartDir(exe)
+		}
+		s.IsHidden = boolToInt(chimeraShortcut.Hidden)
+		s.Tags = map[string]interface{}{}
+		for key, tag := range chimeraShortcut.Tags {
+			s.Tags[fmt.Sprintf("%v", key)] = tag
+		}
+	})
+	sc.NormalizePaths()
+	sc.Appid = int64(shortcut.CalculateAppID(sc.Exe, sc.AppName))
+
+	return sc, nil
+}
+
+// chimeraShortcutFromSteam will return a new Chimera shortcut for the given
+// Steam shortcut of the given user
+func chimeraShortcutFromSteam(user string, sc *shortcut.Shortcut) *chimera.Shortcut {
+	cmd := shortcut.QuoteCommandWord(shortcut.Unquote(sc.Exe))
+	if sc.LaunchOptions != "" {
+		cmd += " " + sc.LaunchOptions
+	}
+
+	chimeraShortcut := chimera.NewShortcut(sc.AppName, cmd, func(s *chimera.Shortcut) {
+		s.Dir = shortcut.Unquote(sc.StartDir)
+		s.Hidden = sc.IsHidden != 0
+		s.Tags = sc.TagList()
+	})
+
+	// Reference the Steam grid images
+	if images, _, err := steam.ResolveImages(user, fmt.Sprintf("%v", sc.Appid)); err == nil {
+		chimeraShortcut.Poster = images.Portrait
+		chimeraShortcut.Banner = images.Landscape
+		chimeraShortcut.Background = images.Hero
+		chimeraShortcut.Logo = images.Logo
+	}
+
+	return chimeraShortcut
+}
+
+// copyChimeraImages will copy the images of the given Chimera shortcut to the
+// Steam grid folder of the given user
+func copyChimeraImages(user string, chimeraShortcut *chimera.Shortcut, sc *shortcut.Shortcut) error {
+	var errs error
+	images := map[steam.AssetType]string{
+		steam.AssetTypeGridPortrait:  chimeraShortcut.Poster,
+		steam.AssetTypeGridLandscape: chimeraShortcut.Banner,
+		steam.AssetTypeHero:          chimeraShortcut.Background,
+		steam.AssetTypeLogo:          chimeraShortcut.Logo,
+	}
+	for _, assetType := range steam.AllAssetTypes {
+		source := images[assetType]
+		if source == "" {
+			continue
+		}
+		if _, err := steam.CopyArtworkToGrid(user, uint64(uint32(sc.Appid)), assetType, expandHome(source)); err != nil {
+			errs = multierror.Append(errs, &steam.AssetError{AssetType: assetType, Err: err})
+		}
+	}
+	return errs
+}
+
+// expandHome will replace a leading ~ in the given path with the home
+// directory
+func expandHome(p string) string {
+	if p != "~" && !strings.HasPrefix(p, "~/") {
+		return p
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return p
+	}
+	return home + p[1:]
+}
+
+// printSyncResults will print the results of a sync command
+func printSyncResults(results []SyncResult, format string) {
+	switch format {
+	case "term":
+		for _, result := range results {
+			if result.User != "" {
+				fmt.Printf("%v: %v (user %v)\n", result.Name, result.Action, result.User)
+				continue
+			}
+			fmt.Printf("%v: %v\n", result.Name, result.Action)
+		}
+	case "json":
+		out, err := json.MarshalIndent(results, "", "  ")
+		if err != nil {
+			ExitError(err, format)
+		}
+		fmt.Println(string(out))
+	default:
+		ExitError(usageErrorf("unknown output format: %s", format), format)
+	}
+}
+
+func init() {
+	chimeraCmd.AddCommand(chimeraSyncToSteamCmd)
+	chimeraCmd.AddCommand(chimeraSyncFromSteamCmd)
+
+	chimeraSyncToSteamCmd.Flags().String("user", "all", "Steam user ID to add the shortcuts for (\"all\", \"current\", or an ID)")
+	addRestartFlag(chimeraSyncToSteamCmd)
+	chimeraSyncFromSteamCmd.Flags().String("user", "all", "Steam user ID to read the shortcuts from (\"all\", \"current\", or an ID)")
+}
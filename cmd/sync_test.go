@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/shadowblip/steam-shortcut-manager/pkg/chimera"
+	"github.com/shadowblip/steam-shortcut-manager/pkg/shortcut"
+)
+
+func TestSteamShortcutFromChimeraExistingPathWithSpaces(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "My Games")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	exe := filepath.Join(dir, "run.sh")
+	if err := os.WriteFile(exe, []byte("#!/bin/sh\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	sc, err := steamShortcutFromChimera(&chimera.Shortcut{Name: "Game", Cmd: exe})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `"` + exe + `"`; sc.Exe != want {
+		t.Errorf("Exe = %v, want %v", sc.Exe, want)
+	}
+	if want := `"` + dir + `"`; sc.StartDir != want {
+		t.Errorf("StartDir = %v, want %v", sc.StartDir, want)
+	}
+	if sc.LaunchOptions != "" {
+		t.Errorf("LaunchOptions = %v, want none", sc.LaunchOptions)
+	}
+}
+
+func TestSteamShortcutFromChimeraQuotedCommand(t *testing.T) {
+	chimeraShortcut := &chimera.Shortcut{
+		Name: "Game",
+		Cmd:  `"/nonexistent/My Games/run.sh" --level 'Level 1'`,
+		Dir:  "/nonexistent/My Games",
+	}
+	sc, err := steamShortcutFromChimera(chimeraShortcut)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `"/nonexistent/My Games/run.sh"`; sc.Exe != want {
+		t.Errorf("Exe = %v, want %v", sc.Exe, want)
+	}
+	if want := `"/nonexistent/My Games"`; sc.StartDir != want {
+		t.Errorf("StartDir = %v, want %v", sc.StartDir, want)
+	}
+	if want := `--level 'Level 1'`; sc.LaunchOptions != want {
+		t.Errorf("LaunchOptions = %v, want %v", sc.LaunchOptions, want)
+	}
+
+	// The app ID must match the one add calculates for the same program
+	want := int64(shortcut.CalculateAppID(shortcut.QuotePath("/nonexistent/My Games/run.sh"), "Game"))
+	if sc.Appid != want {
+		t.Errorf("Appid = %v, want %v", sc.Appid, want)
+	}
+}
+
+func TestSteamShortcutFromChimeraUnterminatedQuote(t *testing.T) {
+	if _, err := steamShortcutFromChimera(&chimera.Shortcut{Name: "Game", Cmd: `"/broken`}); err == nil {
+		t.Error("expected an error for an unterminated quote")
+	}
+}
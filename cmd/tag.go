@@ -0,0 +1,240 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/shadowblip/steam-shortcut-manager/pkg/shortcut"
+	"github.com/shadowblip/steam-shortcut-manager/pkg/steam"
+	"github.com/spf13/cobra"
+)
+
+// TagResult is the tags of a shortcut after a tag command
+type TagResult struct {
+	AppName string   `json:"app_name"`
+	Appid   int64    `json:"app_id"`
+	Tags    []string `json:"tags"`
+	Changed bool     `json:"changed"`
+}
+
+// tagCmd represents the tag command
+var tagCmd = &cobra.Command{
+	Use:   "tag",
+	Short: "Manage the tags of Steam shortcuts",
+	Long: `Add, remove and list the tags of Steam shortcuts. Steam uses the tags of a
+shortcut to place it in collections. Shortcuts are selected by name, or by
+--app-id, in which case the name can be left out.`,
+}
+
+// tagAddCmd represents the tag add command
+var tagAddCmd = &cobra.Command{
+	Use:   "add [name] <tag>",
+	Short: "Add a tag to a Steam shortcut",
+	Long:  `Add a tag to a Steam shortcut. Shortcuts that already have the tag are left unchanged.`,
+	Args:  cobra.RangeArgs(1, 2),
+	Run: func(cmd *cobra.Command, args []string) {
+		format := rootCmd.PersistentFlags().Lookup("output").Value.String()
+		name, appId, tag := getTagArgs(cmd, args, format)
+		results := updateTags(cmd, format, name, appId, func(sc *shortcut.Shortcut) bool {
+			return sc.AddTag(tag)
+		})
+		restartSteamIfRequested(cmd, format)
+		printTagResults(results, format, func(result TagResult) string {
+			if !result.Changed {
+				return fmt.Sprintf("%v (%v) already has tag: %v", result.AppName, result.Appid, tag)
+			}
+			return fmt.Sprintf("Added tag to %v (%v): %v", result.AppName, result.Appid, tag)
+		})
+	},
+}
+
+// tagRemoveCmd represents the tag remove command
+var tagRemoveCmd = &cobra.Command{
+	Use:   "remove [name] <tag>",
+	Short: "Remove a tag from a Steam shortcut",
+	Long:  `Remove a tag from a Steam shortcut. Shortcuts that do not have the tag are left unchanged.`,
+	Args:  cobra.RangeArgs(1, 2),
+	Run: func(cmd *cobra.Command, args []string) {
+		format := rootCmd.PersistentFlags().Lookup("output").Value.String()
+		name, appId, tag := getTagArgs(cmd, args, format)
+		results := updateTags(cmd, format, name, appId, func(sc *shortcut.Shortcut) bool {
+			return sc.RemoveTag(tag)
+		})
+		restartSteamIfRequested(cmd, format)
+		printTagResults(results, format, func(result TagResult) string {
+			if !result.Changed {
+				return fmt.Sprintf("%v (%v) does not have tag: %v", result.AppName, result.Appid, tag)
+			}
+			return fmt.Sprintf("Removed tag from %v (%v): %v", result.AppName, result.Appid, tag)
+		})
+	},
+}
+
+// tagListCmd represents the tag list command
+var tagListCmd = &cobra.Command{
+	Use:   "list [name]",
+	Short: "List the tags of a Steam shortcut",
+	Long:  `List the tags of a Steam shortcut`,
+	Args:  cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		format := rootCmd.PersistentFlags().Lookup("output").Value.String()
+		appId := int64(getAppIDFlag(cmd, format))
+		if len(args) == 0 && appId == 0 {
+			cmd.Help()
+			ExitError(usageErrorf("a shortcut name or --app-id is required"), format)
+		}
+		name := ""
+		if len(args) > 0 {
+			name = args[0]
+		}
+
+		// Look up the shortcut for each user that has it
+		results := map[string]TagResult{}
+		forEachTagShortcut(cmd, format, name, appId, func(user string, sc *shortcut.Shortcut) bool {
+			results[user] = TagResult{AppName: sc.AppName, Appid: sc.Appid, Tags: sc.TagList()}
+			return false
+		})
+
+		printTagResults(results, format, func(result TagResult) string {
+			if len(result.Tags) == 0 {
+				return fmt.Sprintf("%v (%v) has no tags", result.AppName, result.Appid)
+			}
+			return fmt.Sprintf("%v (%v): %v", result.AppName, result.Appid, strings.Join(result.Tags, ", "))
+		})
+	},
+}
+
+// getTagArgs will return the shortcut name, app ID and tag given to the tag
+// add and remove commands. The name can be left out if --app-id is given.
+func getTagArgs(cmd *cobra.Command, args []string, format string) (string, int64, string) {
+	appId := int64(getAppIDFlag(cmd, format))
+	if len(args) == 1 && appId == 0 {
+		cmd.Help()
+		ExitError(usageErrorf("a shortcut name or --app-id is required"), format)
+	}
+	tag := args[len(args)-1]
+	if strings.TrimSpace(tag) == "" {
+		ExitError(usageErrorf("tag cannot be empty"), format)
+	}
+	name := ""
+	if len(args) == 2 {
+		name = args[0]
+	}
+	return name, appId, tag
+}
+
+// forEachTagShortcut will call the given function with the shortcut with the
+// given name or app ID of every selected Steam user that has it. If the
+// function returns true, the shortcuts file of the user is saved with the
+// changed shortcut and the rest of the file preserved. Exits with an error if
+// no user has the shortcut.
+func forEachTagShortcut(cmd *cobra.Command, format, name string, appId int64, fn func(user string, sc *shortcut.Shortcut) bool) {
+	// Fetch all users
+	users, err := steam.GetUsersWithShortcuts()
+	if err != nil {
+		ExitError(err, format)
+	}
+
+	// Check to see if we're looking for just one user
+	onlyForUser := getUserFlag(cmd, format)
+
+	found := false
+	for _, user := range users {
+		if onlyForUser != "all" && onlyForUser != user {
+			continue
+		}
+
+		shortcutsPath, _ := steam.GetShortcutsPath(user)
+		shortcuts, err := shortcut.Load(shortcutsPath)
+		if err != nil {
+			ExitError(err, format)
+		}
+
+		for _, key := range shortcuts.Keys() {
+			sc := shortcuts.Shortcuts[key]
+			if appId != 0 && uint32(sc.Appid) != uint32(appId) {
+				continue
+			}
+			if name != "" && sc.AppName != name {
+				continue
+			}
+			found = true
+			if !fn(user, &sc) {
+				break
+			}
+
+			// Write the changes
+			shortcuts.Shortcuts[key] = sc
+			DebugPrintln("Saving tags of", sc.AppName, "to", shortcutsPath)
+			if err := shortcut.Save(shortcuts, shortcutsPath); err != nil {
+				ExitError(err, format)
+			}
+			break
+		}
+	}
+	if !found {
+		if appId != 0 {
+			ExitError(fmt.Errorf("no shortcut found with id: %v", appId), format)
+		}
+		ExitError(fmt.Errorf("no shortcut found with name: %v", name), format)
+	}
+}
+
+// updateTags will apply the given change to the tags of the selected
+// shortcut of every user, saving the shortcuts files that changed
+func updateTags(cmd *cobra.Command, format, name string, appId int64, mutate func(sc *shortcut.Shortcut) bool) map[string]TagResult {
+	results := map[string]TagResult{}
+	forEachTagShortcut(cmd, format, name, appId, func(user string, sc *shortcut.Shortcut) bool {
+		changed := mutate(sc)
+		results[user] = TagResult{AppName: sc.AppName, Appid: sc.Appid, Tags: sc.TagList(), Changed: changed}
+		return changed
+	})
+	return results
+}
+
+// printTagResults will print the results of a tag command in the given
+// format, using describe to print each result in the terminal format
+func printTagResults(results map[string]TagResult, format string, describe func(result TagResult) string) {
+	switch format {
+	case "term":
+		users := make([]string, 0, len(results))
+		for user := range results {
+			users = append(users, user)
+		}
+		sort.Strings(users)
+		for _, user := range users {
+			fmt.Println("User:", user)
+			fmt.Println(" ", describe(results[user]))
+		}
+	case "json":
+		out, err := json.MarshalIndent(results, "", "  ")
+		if err != nil {
+			ExitError(err, format)
+		}
+		fmt.Println(string(out))
+	case "yaml":
+		out, err := marshalYAML(results)
+		if err != nil {
+			ExitError(err, format)
+		}
+		fmt.Println(string(out))
+	default:
+		ExitError(usageErrorf("unknown output format: %s", format), format)
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(tagCmd)
+	tagCmd.AddCommand(tagAddCmd)
+	tagCmd.AddCommand(tagRemoveCmd)
+	tagCmd.AddCommand(tagListCmd)
+
+	for _, cmd := range []*cobra.Command{tagAddCmd, tagRemoveCmd, tagListCmd} {
+		cmd.Flags().String("user", "all", "Steam user ID to use the shortcut of (\"all\", \"current\", or an ID)")
+		cmd.Flags().String("app-id", "", "App ID of the shortcut to use instead of its name")
+	}
+	addRestartFlag(tagAddCmd)
+	addRestartFlag(tagRemoveCmd)
+}
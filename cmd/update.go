@@ -0,0 +1,164 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/shadowblip/steam-shortcut-manager/pkg/logger"
+	"github.com/shadowblip/steam-shortcut-manager/pkg/shortcut"
+	"github.com/shadowblip/steam-shortcut-manager/pkg/steam"
+	"github.com/spf13/cobra"
+)
+
+// updateCmd represents the update command
+var updateCmd = &cobra.Command{
+	Use:   "update [<name>]",
+	Short: "Update an existing Steam shortcut",
+	Long: `Update the fields of an existing Steam shortcut in place. The shortcut is
+selected by name, or by --app-id. Only the fields given as flags are changed,
+and the shortcut keeps its app ID so its artwork stays attached. With
+--recalculate-app-id, the app ID is calculated again from the new executable
+and name, and the artwork of the shortcut is moved to the new app ID.`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		format := rootCmd.PersistentFlags().Lookup("output").Value.String()
+
+		// Determine which shortcut to update
+		appId := int64(getAppIDFlag(cmd, format))
+		if len(args) == 0 && appId == 0 {
+			cmd.Help()
+			ExitError(usageErrorf("a shortcut name or --app-id is required"), format)
+		}
+		name := ""
+		if len(args) > 0 {
+			name = args[0]
+		}
+
+		// Update the shortcut for each user that has it
+		results := map[string]shortcut.Shortcut{}
+		for _, target := range getShortcutsTargets(cmd, format, steam.GetUsersWithShortcuts) {
+			shortcutsPath := target.Path
+			shortcuts, err := shortcut.Load(shortcutsPath)
+			if err != nil {
+				ExitError(err, format)
+			}
+
+			var updated *shortcut.Shortcut
+			mutate := func(sc *shortcut.Shortcut) {
+				updateShortcutFromFlags(cmd, sc)
+				updated = sc
+			}
+			if appId != 0 {
+				err = shortcuts.UpdateByID(appId, mutate)
+			} else {
+				err = shortcuts.Update(name, mutate)
+			}
+			if err != nil {
+				DebugPrintln("Skipping", target.Describe()+":", err)
+				continue
+			}
+
+			// Give the shortcut the app ID of its new executable and name
+			oldAppId := updated.Appid
+			if recalculate, _ := cmd.Flags().GetBool("recalculate-app-id"); recalculate {
+				newAppId := int64(shortcut.CalculateAppID(updated.Exe, updated.AppName))
+				if err := shortcuts.ChangeAppID(oldAppId, newAppId); err != nil {
+					ExitError(err, format)
+				}
+				updated.Appid = newAppId
+			}
+
+			// Write the changes
+			err = shortcut.Save(shortcuts, shortcutsPath)
+			if err != nil {
+				ExitError(err, format)
+			}
+
+			// Keep the artwork attached to the shortcut
+			if updated.Appid != oldAppId && target.User != "" {
+				DebugPrintln("Moving artwork of", updated.AppName, "from", oldAppId, "to", updated.Appid)
+				err := steam.RenameArtwork(target.User, uint64(uint32(oldAppId)), uint64(uint32(updated.Appid)))
+				if err != nil {
+					logger.Warningf("Unable to move the artwork of %v to its new app ID: %v", updated.AppName, err)
+				}
+			}
+			result := *updated
+			if target.User != "" {
+				result.Images, _, _ = steam.ResolveImages(target.User, fmt.Sprintf("%v", result.Appid))
+			}
+			results[target.Name()] = result
+		}
+		if len(results) == 0 {
+			if appId != 0 {
+				ExitError(fmt.Errorf("no shortcut found with id: %v", appId), format)
+			}
+			ExitError(fmt.Errorf("no shortcut found with name: %v", name), format)
+		}
+		restartSteamIfRequested(cmd, format)
+
+		// Print the output
+		switch format {
+		case "term":
+			for user, sc := range results {
+				fmt.Println("User:", user)
+				fmt.Printf("  Updated: %v (%v)\n", sc.AppName, sc.Appid)
+			}
+		case "json":
+			out, err := json.MarshalIndent(results, "", "  ")
+			if err != nil {
+				ExitError(err, format)
+			}
+			fmt.Println(string(out))
+		default:
+			ExitError(usageErrorf("unknown output format: %s", format), format)
+		}
+	},
+}
+
+// updateShortcutFromFlags will apply every flag that was set on the command
+// line to the given shortcut.
+func updateShortcutFromFlags(cmd *cobra.Command, sc *shortcut.Shortcut) {
+	flags := cmd.Flags()
+	if flags.Changed("name") {
+		sc.AppName, _ = flags.GetString("name")
+	}
+	if flags.Changed("exe") {
+		exe, _ := flags.GetString("exe")
+		sc.Exe = shortcut.QuotePath(exe)
+	}
+	if flags.Changed("start-dir") {
+		startDir, _ := flags.GetString("start-dir")
+		sc.StartDir = shortcut.QuotePath(startDir)
+	}
+	if flags.Changed("icon") {
+		sc.Icon, _ = flags.GetString("icon")
+	}
+	if flags.Changed("launch-options") {
+		sc.LaunchOptions, _ = flags.GetString("launch-options")
+	}
+	if flags.Changed("hidden") {
+		hidden, _ := flags.GetBool("hidden")
+		sc.IsHidden = boolToInt(hidden)
+	}
+	if flags.Changed("tag") {
+		tags, _ := flags.GetStringArray("tag")
+		sc.SetTags(tags)
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(updateCmd)
+
+	updateCmd.Flags().String("app-id", "", "Select the shortcut to update by its app ID instead of its name")
+	updateCmd.Flags().String("name", "", "New name of the shortcut")
+	updateCmd.Flags().String("exe", "", "New executable of the shortcut")
+	updateCmd.Flags().String("start-dir", "", "New working directory where the app is started")
+	updateCmd.Flags().String("icon", "", "New path to the icon to use for this application")
+	updateCmd.Flags().String("launch-options", "", "New launch options for the shortcut")
+	updateCmd.Flags().Bool("hidden", false, "Whether or not the shortcut is hidden")
+	updateCmd.Flags().StringArray("tag", []string{}, "Tag of the shortcut, replacing the existing tags (can be repeated)")
+	updateCmd.Flags().Bool("recalculate-app-id", false, "Calculate the app ID again from the new executable and name, and move the shortcut's artwork to it")
+	updateCmd.Flags().String("user", "all", "Steam user ID to update the shortcut for (\"all\", \"current\", or an ID)")
+	addShortcutsFileFlag(updateCmd)
+	addRestartFlag(updateCmd)
+}
@@ -26,9 +26,13 @@ package cmd
 import (
 	"encoding/json"
 	"fmt"
+	"sort"
+	"sync"
 
+	multierror "github.com/hashicorp/go-multierror"
 	"github.com/shadowblip/steam-shortcut-manager/pkg/steam"
 	"github.com/spf13/cobra"
+	"golang.org/x/sync/errgroup"
 )
 
 // usersCmd represents the users command
@@ -38,7 +42,11 @@ var usersCmd = &cobra.Command{
 	Long:  `List current Steam user IDs`,
 	Run: func(cmd *cobra.Command, args []string) {
 		format := rootCmd.PersistentFlags().Lookup("output").Value.String()
-		users, err := steam.GetUsers()
+		getUsers := steam.GetUsers
+		if withShortcuts, _ := cmd.Flags().GetBool("with-shortcuts"); withShortcuts {
+			getUsers = steam.GetUsersWithShortcuts
+		}
+		users, err := getUsers()
 		if err != nil {
 			ExitError(err, format)
 		}
@@ -52,16 +60,47 @@ var usersCmd = &cobra.Command{
 		case "json":
 			out, err := json.MarshalIndent(users, "", "  ")
 			if err != nil {
-				panic(err)
+				ExitError(err, format)
 			}
 			fmt.Println(string(out))
 		default:
-			panic("unknown output format: " + format)
+			ExitError(usageErrorf("unknown output format: %s", format), format)
 		}
 
 	},
 }
 
+// forEachUser will call the given function for each of the given users in
+// parallel, at most steam.DefaultConcurrency at a time. An error for one user
+// does not stop the others; all errors are returned together, sorted so the
+// output does not depend on which user finished first.
+func forEachUser(users []string, fn func(user string) error) error {
+	var mu sync.Mutex
+	var errs *multierror.Error
+	group := new(errgroup.Group)
+	group.SetLimit(steam.DefaultConcurrency)
+	for _, user := range users {
+		user := user
+		group.Go(func() error {
+			if err := fn(user); err != nil {
+				mu.Lock()
+				errs = multierror.Append(errs, err)
+				mu.Unlock()
+			}
+			return nil
+		})
+	}
+	group.Wait()
+
+	if errs == nil {
+		return nil
+	}
+	sort.Slice(errs.Errors, func(i, j int) bool {
+		return errs.Errors[i].Error() < errs.Errors[j].Error()
+	})
+	return errs
+}
+
 func init() {
 	rootCmd.AddCommand(usersCmd)
 
@@ -74,4 +113,5 @@ func init() {
 	// Cobra supports local flags which will only run when this command
 	// is called directly, e.g.:
 	// usersCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+	usersCmd.Flags().Bool("with-shortcuts", false, "Only list users that have a shortcuts file")
 }
@@ -0,0 +1,206 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	multierror "github.com/hashicorp/go-multierror"
+	"github.com/shadowblip/steam-shortcut-manager/pkg/shortcut"
+	"github.com/shadowblip/steam-shortcut-manager/pkg/steam"
+	"github.com/spf13/cobra"
+)
+
+// verifyChecks is the list of all checks the verify command can run, in the
+// order they are run.
+var verifyChecks = []string{"exe", "startdir", "icon", "grid"}
+
+// VerifyCheckResult is the result of a single verification check
+type VerifyCheckResult struct {
+	Check string `json:"check"`
+	OK    bool   `json:"ok"`
+	Error string `json:"error,omitempty"`
+}
+
+// VerifyResult holds all of the check results for a single shortcut
+type VerifyResult struct {
+	AppName string              `json:"AppName"`
+	Appid   int64               `json:"appid"`
+	Checks  []VerifyCheckResult `json:"checks"`
+}
+
+// verifyCmd represents the verify command
+var verifyCmd = &cobra.Command{
+	Use:   "verify",
+	Short: "Verify that Steam shortcuts and their artwork are valid",
+	Long: `Verify that Steam shortcuts point to existing executables and directories,
+and that their icon and grid artwork can be read. Use --check to only run a
+subset of the checks.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		format := rootCmd.PersistentFlags().Lookup("output").Value.String()
+
+		// Determine which checks to run
+		checks, _ := cmd.Flags().GetStringSlice("check")
+		for _, check := range checks {
+			if !contains(verifyChecks, check) {
+				ExitError(usageErrorf("unknown check '%s' (valid checks: %s)", check, strings.Join(verifyChecks, ",")), format)
+			}
+		}
+
+		// Get users
+		users, err := steam.GetUsersWithShortcuts()
+		if err != nil {
+			ExitError(err, format)
+		}
+
+		// Check to see if we're verifying just one user
+		onlyForUser := getUserFlag(cmd, format)
+
+		// The library folders are only needed to resolve relative paths, so
+		// they are read once and only if a path check runs
+		var searchDirs []string
+		if contains(checks, "exe") || contains(checks, "startdir") {
+			searchDirs = getLibrarySearchDirs()
+		}
+
+		// Run the checks for every shortcut
+		results := map[string][]VerifyResult{}
+		failed := false
+		for _, user := range users {
+			if onlyForUser != "all" && onlyForUser != user {
+				continue
+			}
+
+			shortcutsPath, _ := steam.GetShortcutsPath(user)
+			shortcuts, err := shortcut.Load(shortcutsPath)
+			if err != nil {
+				ExitError(err, format)
+			}
+
+			userResults, ok := verifyShortcuts(user, shortcuts, checks, searchDirs)
+			if !ok {
+				failed = true
+			}
+			results[user] = userResults
+		}
+
+		// Print the output
+		switch format {
+		case "term":
+			sortedUsers := make([]string, 0, len(results))
+			for user := range results {
+				sortedUsers = append(sortedUsers, user)
+			}
+			sort.Strings(sortedUsers)
+			for _, user := range sortedUsers {
+				fmt.Println("User:", user)
+				for _, result := range results[user] {
+					fmt.Println("  ", result.AppName)
+					for _, check := range result.Checks {
+						if check.OK {
+							fmt.Printf("    %-9s OK\n", check.Check+":")
+							continue
+						}
+						fmt.Printf("    %-9s FAILED: %v\n", check.Check+":", check.Error)
+					}
+				}
+			}
+		case "json":
+			out, err := json.MarshalIndent(results, "", "  ")
+			if err != nil {
+				ExitError(err, format)
+			}
+			fmt.Println(string(out))
+		default:
+			ExitError(usageErrorf("unknown output format: %s", format), format)
+		}
+
+		if failed {
+			ExitError(fmt.Errorf("one or more checks failed"), format)
+		}
+	},
+}
+
+// getLibrarySearchDirs will return the directories used to resolve relative
+// shortcut paths: each Steam library folder and its installed games directory.
+func getLibrarySearchDirs() []string {
+	folders, err := steam.GetLibraryFolders()
+	if err != nil {
+		DebugPrintln("Unable to read Steam library folders:", err)
+		return nil
+	}
+	searchDirs := []string{}
+	for _, folder := range folders {
+		searchDirs = append(searchDirs, folder, filepath.Join(folder, "steamapps", "common"))
+	}
+	return searchDirs
+}
+
+// verifyShortcuts will run the given checks against every shortcut of the
+// given user, in the order of verifyChecks. Relative paths are resolved
+// against the given search directories. Returns false if any check failed.
+func verifyShortcuts(user string, shortcuts *shortcut.Shortcuts, checks, searchDirs []string) ([]VerifyResult, bool) {
+	ok := true
+	results := []VerifyResult{}
+	for _, key := range shortcuts.Keys() {
+		sc := shortcuts.Shortcuts[key]
+		result := VerifyResult{AppName: sc.AppName, Appid: sc.Appid}
+		for _, check := range verifyChecks {
+			if !contains(checks, check) {
+				continue
+			}
+			checkResult := VerifyCheckResult{Check: check, OK: true}
+			if err := runVerifyCheck(check, user, &sc, searchDirs); err != nil {
+				checkResult.OK = false
+				checkResult.Error = err.Error()
+				ok = false
+			}
+			result.Checks = append(result.Checks, checkResult)
+		}
+		results = append(results, result)
+	}
+	return results, ok
+}
+
+// runVerifyCheck will run the given check against the given shortcut
+func runVerifyCheck(check, user string, sc *shortcut.Shortcut, searchDirs []string) error {
+	switch check {
+	case "exe":
+		return sc.ValidateExe(searchDirs...)
+	case "startdir":
+		return sc.ValidateStartDir(searchDirs...)
+	case "icon":
+		if sc.Icon == "" {
+			return nil
+		}
+		return steam.ValidateImage(shortcut.Unquote(sc.Icon))
+	case "grid":
+		var errs error
+		idStr := fmt.Sprintf("%v", sc.Appid)
+		lookups := []func(user, appId string) (string, error){
+			steam.GetImagePortrait,
+			steam.GetImageLandscape,
+			steam.GetImageHero,
+			steam.GetImageLogo,
+		}
+		for _, lookup := range lookups {
+			_, err := lookup(user, idStr)
+			if err == nil || errors.Is(err, steam.ErrImageNotFound) {
+				continue
+			}
+			errs = multierror.Append(errs, err)
+		}
+		return errs
+	}
+	return fmt.Errorf("unknown check: %s", check)
+}
+
+func init() {
+	rootCmd.AddCommand(verifyCmd)
+
+	verifyCmd.Flags().String("user", "all", "Steam user ID to verify the shortcuts of (\"all\", \"current\", or an ID)")
+	verifyCmd.Flags().StringSlice("check", verifyChecks, "Comma-separated list of checks to run (exe, startdir, icon, grid)")
+}
@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/shadowblip/steam-shortcut-manager/pkg/shortcut"
+)
+
+func TestVerifyShortcutsRunsOnlySelectedChecks(t *testing.T) {
+	shortcuts := shortcut.NewShortcuts()
+	shortcuts.Shortcuts["0"] = shortcut.Shortcut{
+		AppName:  "Game",
+		Exe:      "/bin/sh",
+		StartDir: "/nonexistent/start/dir",
+		Icon:     "/nonexistent/icon.png",
+		Appid:    0x80000001,
+	}
+
+	// The start dir and icon are broken, so only running the exe check
+	// must pass
+	results, ok := verifyShortcuts("123", shortcuts, []string{"exe"}, nil)
+	if !ok {
+		t.Errorf("verifyShortcuts failed with only the exe check: %+v", results)
+	}
+	if len(results) != 1 || len(results[0].Checks) != 1 || results[0].Checks[0].Check != "exe" {
+		t.Fatalf("results = %+v, want only the exe check", results)
+	}
+
+	// Each selected check is reported on its own, in the verifyChecks order
+	results, ok = verifyShortcuts("123", shortcuts, []string{"icon", "exe", "startdir"}, nil)
+	if ok {
+		t.Error("verifyShortcuts passed with broken start dir and icon")
+	}
+	want := []VerifyCheckResult{
+		{Check: "exe", OK: true},
+		{Check: "startdir", OK: false},
+		{Check: "icon", OK: false},
+	}
+	checks := results[0].Checks
+	if len(checks) != len(want) {
+		t.Fatalf("checks = %+v, want %+v", checks, want)
+	}
+	for i := range want {
+		if checks[i].Check != want[i].Check || checks[i].OK != want[i].OK {
+			t.Errorf("check %d = %+v, want %+v", i, checks[i], want[i])
+		}
+	}
+}
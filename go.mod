@@ -4,6 +4,7 @@ go 1.18
 
 require (
 	github.com/dolmen-go/kittyimg v0.0.0-20220724200233-b126743af44c
+	github.com/gorilla/websocket v1.5.0
 	github.com/hashicorp/go-multierror v1.1.1
 	github.com/pkg/sftp v1.13.6
 	github.com/spf13/cobra v1.5.0
@@ -11,7 +12,11 @@ require (
 	github.com/spf13/viper v1.12.0
 	github.com/wakeful-cloud/vdf v0.0.0-20210218214150-0be6ec18b390
 	golang.org/x/crypto v0.17.0
+	golang.org/x/image v0.14.0
+	golang.org/x/sync v0.5.0
 	golang.org/x/sys v0.15.0
+	golang.org/x/term v0.15.0
+	golang.org/x/time v0.5.0
 	gopkg.in/yaml.v3 v3.0.1
 )
 
@@ -0,0 +1,79 @@
+package fsutil
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// ownerUID and ownerGID are the owner given to written files, if set with
+// SetOwner. -1 leaves the owner unchanged.
+var ownerUID, ownerGID = -1, -1
+
+// SetOwner will give every file written with WriteFileAtomic the given user
+// and group ID. -1 keeps the automatic owner for that ID. Setting an owner
+// usually requires running as root.
+func SetOwner(uid, gid int) {
+	ownerUID, ownerGID = uid, gid
+}
+
+// WriteFileAtomic will write the given data to a temporary file in the same
+// directory as the target, sync it to disk, and then rename it over the
+// target. This ensures that a crash mid-write never leaves a truncated file
+// behind. If the target already exists, its file mode is preserved;
+// otherwise the given permissions are used. The file is given the owner set
+// with SetOwner, or else the owner of the file it replaces or of its
+// directory, so files written with sudo still belong to the Steam user.
+func WriteFileAtomic(path string, data []byte, perm os.FileMode) error {
+	dir := filepath.Dir(path)
+	uid, gid, hasOwner := fileOwner(dir)
+	if info, err := os.Stat(path); err == nil {
+		perm = info.Mode().Perm()
+		uid, gid, hasOwner = fileOwner(path)
+	}
+	explicitOwner := ownerUID >= 0 || ownerGID >= 0
+	if explicitOwner {
+		uid, gid, hasOwner = ownerUID, ownerGID, true
+	}
+
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("unable to create temp file: %w", err)
+	}
+	tmpName := tmp.Name()
+
+	// Remove the temp file if anything goes wrong before the rename
+	success := false
+	defer func() {
+		if !success {
+			tmp.Close()
+			os.Remove(tmpName)
+		}
+	}()
+
+	if _, err := tmp.Write(data); err != nil {
+		return fmt.Errorf("unable to write temp file: %w", err)
+	}
+	if err := tmp.Chmod(perm); err != nil {
+		return fmt.Errorf("unable to set file mode: %w", err)
+	}
+	if hasOwner && (uid != os.Getuid() || gid != os.Getgid()) {
+		// Only root can give files away, so this is best effort unless an
+		// owner was explicitly requested
+		if err := tmp.Chown(uid, gid); err != nil && explicitOwner {
+			return fmt.Errorf("unable to set file owner: %w", err)
+		}
+	}
+	if err := tmp.Sync(); err != nil {
+		return fmt.Errorf("unable to sync temp file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("unable to close temp file: %w", err)
+	}
+	if err := os.Rename(tmpName, path); err != nil {
+		return fmt.Errorf("unable to replace %s: %w", path, err)
+	}
+	success = true
+
+	return nil
+}
@@ -0,0 +1,191 @@
+// Package httpclient provides the shared HTTP client used for all network
+// requests, with a request timeout and automatic retries.
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/shadowblip/steam-shortcut-manager/pkg/logger"
+)
+
+// DefaultTimeout is the default timeout for a single HTTP request
+const DefaultTimeout = 30 * time.Second
+
+// Client is the HTTP client used for all requests. It can be replaced, for
+// example to inject a mock transport. By default requests go through the
+// proxy given in the HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment
+// variables. Use SetProxy to override it.
+var Client = &http.Client{Timeout: DefaultTimeout, Transport: newTransport()}
+
+// DefaultUserAgent is the User-Agent sent with every request unless it is
+// changed with SetUserAgent
+const DefaultUserAgent = "steam-shortcut-manager"
+
+// userAgent is the User-Agent sent with every request
+var userAgent = DefaultUserAgent
+
+// headers are extra headers sent with every request
+var headers = http.Header{}
+
+// SetUserAgent will set the User-Agent sent with every request. Some CDNs
+// reject the default Go user agent. An empty value restores DefaultUserAgent.
+func SetUserAgent(ua string) {
+	if ua == "" {
+		ua = DefaultUserAgent
+	}
+	userAgent = ua
+}
+
+// SetHeader will send the given header with every request, unless the
+// request already sets it
+func SetHeader(key, value string) {
+	headers.Set(key, value)
+}
+
+// ErrOffline is returned instead of making a network request in offline mode
+var ErrOffline = errors.New("network access is disabled in offline mode")
+
+// Offline disables all network requests. Requests fail with ErrOffline
+// instead of reaching out.
+var Offline = false
+
+// MaxRetries is the number of times a failed request will be retried
+var MaxRetries = 3
+
+// RetryBackoff is the delay before the first retry. It doubles with every
+// following retry.
+var RetryBackoff = 500 * time.Millisecond
+
+// MaxRetryWait is the longest we will wait before retrying, even if the
+// server asks for a longer delay with a Retry-After header.
+var MaxRetryWait = time.Minute
+
+// SetTimeout will set the timeout for a single HTTP request
+func SetTimeout(timeout time.Duration) {
+	Client.Timeout = timeout
+}
+
+// newTransport will return the transport of the shared client, which uses the
+// proxy from the environment
+func newTransport() *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = http.ProxyFromEnvironment
+	return transport
+}
+
+// Get will perform a GET request to the given URL. See Do.
+func Get(url string) (*http.Response, error) {
+	return GetContext(context.Background(), url)
+}
+
+// GetContext will perform a GET request to the given URL that is aborted when
+// the given context is cancelled. See Do.
+func GetContext(ctx context.Context, url string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	return Do(req)
+}
+
+// Do will send the given request using the shared client with the configured
+// User-Agent and extra headers. Network errors and
+// 5xx responses are retried with exponential backoff. 429 responses are
+// retried after the delay given in the Retry-After header. Retries stop as soon
+// as the request's context is cancelled.
+func Do(req *http.Request) (*http.Response, error) {
+	return DoWithClient(Client, req)
+}
+
+// DoWithClient is like Do, but sends the request with the given client
+// instead of the shared one. The configured User-Agent, extra headers and
+// retries still apply. Returns ErrOffline in offline mode.
+func DoWithClient(client *http.Client, req *http.Request) (*http.Response, error) {
+	if Offline {
+		return nil, fmt.Errorf("%w: %s %s", ErrOffline, req.Method, req.URL.Redacted())
+	}
+	if req.Header.Get("User-Agent") == "" {
+		req.Header.Set("User-Agent", userAgent)
+	}
+	for key, values := range headers {
+		if req.Header.Get(key) == "" {
+			req.Header[key] = values
+		}
+	}
+
+	backoff := RetryBackoff
+	for attempt := 0; ; attempt++ {
+		res, err := client.Do(req)
+		if attempt >= MaxRetries || !canRetry(req) || req.Context().Err() != nil {
+			return res, err
+		}
+
+		// Determine if and how long to wait before the next attempt
+		wait := backoff
+		switch {
+		case err != nil:
+			logger.DebugPrintln("Request failed, retrying:", err)
+		case res.StatusCode == http.StatusTooManyRequests:
+			if retryAfter, ok := parseRetryAfter(res.Header.Get("Retry-After")); ok {
+				wait = retryAfter
+			}
+			res.Body.Close()
+			logger.DebugPrintln("Rate limited, retrying in", wait)
+		case res.StatusCode >= 500:
+			res.Body.Close()
+			logger.DebugPrintln("Received", res.StatusCode, "response, retrying in", wait)
+		default:
+			return res, nil
+		}
+		if wait > MaxRetryWait {
+			wait = MaxRetryWait
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		}
+		backoff *= 2
+
+		// Rewind the request body for the next attempt
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, fmt.Errorf("unable to retry request: %w", err)
+			}
+			req.Body = body
+		}
+	}
+}
+
+// canRetry will return whether or not the given request can be safely sent
+// again.
+func canRetry(req *http.Request) bool {
+	return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+}
+
+// parseRetryAfter will parse the value of a Retry-After header, which is
+// either a number of seconds or an HTTP date.
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		wait := time.Until(date)
+		if wait < 0 {
+			wait = 0
+		}
+		return wait, true
+	}
+	return 0, false
+}
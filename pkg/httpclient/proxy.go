@@ -0,0 +1,79 @@
+package httpclient
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// SetProxy will send all requests of the shared client through the given
+// proxy URL instead of the proxy from the environment. Hosts listed in the
+// NO_PROXY environment variable are still reached directly. An empty URL
+// restores the proxy from the environment.
+func SetProxy(proxy string) error {
+	transport, ok := Client.Transport.(*http.Transport)
+	if !ok {
+		return fmt.Errorf("unable to set proxy on custom transport %T", Client.Transport)
+	}
+	if proxy == "" {
+		transport.Proxy = http.ProxyFromEnvironment
+		return nil
+	}
+
+	proxyURL, err := url.Parse(proxy)
+	if err != nil || proxyURL.Host == "" {
+		return fmt.Errorf("invalid proxy URL: %s", proxy)
+	}
+	noProxy := os.Getenv("NO_PROXY")
+	if noProxy == "" {
+		noProxy = os.Getenv("no_proxy")
+	}
+	transport.Proxy = func(req *http.Request) (*url.URL, error) {
+		if bypassProxy(req.URL.Hostname(), noProxy) {
+			return nil, nil
+		}
+		return proxyURL, nil
+	}
+	return nil
+}
+
+// bypassProxy will return whether or not the given host matches the given
+// NO_PROXY list. Entries are comma separated and may be "*", a host name
+// that also matches its subdomains, a domain starting with ".", an IP
+// address, or a CIDR range. Ports are ignored. Loopback hosts always bypass
+// the proxy.
+func bypassProxy(host, noProxy string) bool {
+	host = strings.ToLower(host)
+	ip := net.ParseIP(host)
+	if host == "localhost" || (ip != nil && ip.IsLoopback()) {
+		return true
+	}
+
+	for _, entry := range strings.Split(noProxy, ",") {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		if entry == "" {
+			continue
+		}
+		if entry == "*" {
+			return true
+		}
+		if _, cidr, err := net.ParseCIDR(entry); err == nil {
+			if ip != nil && cidr.Contains(ip) {
+				return true
+			}
+			continue
+		}
+		if h, _, err := net.SplitHostPort(entry); err == nil {
+			entry = h
+		}
+		entry = strings.TrimPrefix(entry, "*")
+		domain := strings.TrimPrefix(entry, ".")
+		if host == domain || strings.HasSuffix(host, "."+domain) {
+			return true
+		}
+	}
+	return false
+}
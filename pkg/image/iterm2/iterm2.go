@@ -0,0 +1,23 @@
+package iterm2
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Display will render the given image file to the terminal using the iTerm2
+// inline images protocol. The terminal decodes the image itself, so the file
+// is sent as-is.
+func Display(file string) error {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return fmt.Errorf("%s: %w", file, err)
+	}
+
+	name := base64.StdEncoding.EncodeToString([]byte(filepath.Base(file)))
+	_, err = fmt.Fprintf(os.Stdout, "\033]1337;File=name=%s;size=%d;inline=1:%s\a\n",
+		name, len(data), base64.StdEncoding.EncodeToString(data))
+	return err
+}
@@ -2,10 +2,41 @@ package image
 
 import (
 	"os"
+	"strings"
 
+	"github.com/shadowblip/steam-shortcut-manager/pkg/image/iterm2"
 	"github.com/shadowblip/steam-shortcut-manager/pkg/image/kitty"
+	"github.com/shadowblip/steam-shortcut-manager/pkg/image/sixel"
+	"golang.org/x/term"
 )
 
+// Protocol is a terminal graphics protocol used to display images inline
+type Protocol int
+
+const (
+	// None means images cannot be displayed
+	None Protocol = iota
+	// Kitty is the kitty terminal graphics protocol
+	Kitty
+	// Sixel is the DEC sixel graphics protocol
+	Sixel
+	// ITerm2 is the iTerm2 inline images protocol
+	ITerm2
+)
+
+// String will return the name of the protocol
+func (p Protocol) String() string {
+	switch p {
+	case Kitty:
+		return "kitty"
+	case Sixel:
+		return "sixel"
+	case ITerm2:
+		return "iterm2"
+	}
+	return "none"
+}
+
 // Displayer is a function signature of a provider that can display images
 type Displayer func(filename string) error
 
@@ -13,10 +44,90 @@ type Displayer func(filename string) error
 var Display Displayer
 var CanDisplay = false
 
+// ShouldRender will return whether or not images should be rendered for the
+// given output format. Images are only rendered for terminal output, so
+// machine-readable formats such as json and yaml never contain escape codes.
+func ShouldRender(format string) bool {
+	return CanDisplay && format == "term"
+}
+
+// sixelTerms are $TERM or $TERM_PROGRAM prefixes of terminals known to
+// support sixel graphics
+var sixelTerms = []string{"mlterm", "yaft", "foot", "contour", "mintty"}
+
 func init() {
-	// Set our displayer to Kitty if detected
-	if os.Getenv("TERM") == "xterm-kitty" {
-		Display = kitty.Display
+	var display Displayer
+	switch DetectProtocol() {
+	case Kitty:
+		display = kitty.Display
+	case Sixel:
+		display = sixel.Display
+	case ITerm2:
+		display = iterm2.Display
+	}
+	if display != nil {
+		Display = scaled(display)
 		CanDisplay = true
 	}
 }
+
+// scaled will wrap the given displayer so images are downscaled to the
+// preview width before they are displayed
+func scaled(display Displayer) Displayer {
+	return func(filename string) error {
+		resized, err := Resize(filename, PreviewWidth)
+		if err != nil {
+			return err
+		}
+		return display(resized)
+	}
+}
+
+// DetectProtocol will return the graphics protocol supported by the terminal.
+// If stdout is not an interactive terminal, such as when output is piped or
+// redirected, None is returned so no escape codes end up in the output.
+func DetectProtocol() Protocol {
+	if !term.IsTerminal(int(os.Stdout.Fd())) {
+		return None
+	}
+
+	switch {
+	case supportsKitty():
+		return Kitty
+	case supportsITerm2():
+		return ITerm2
+	case supportsSixel():
+		return Sixel
+	}
+	return None
+}
+
+// supportsKitty will return whether or not the terminal supports the kitty
+// graphics protocol
+func supportsKitty() bool {
+	return os.Getenv("TERM") == "xterm-kitty" || os.Getenv("TERM_PROGRAM") == "WezTerm"
+}
+
+// supportsITerm2 will return whether or not the terminal supports the iTerm2
+// inline images protocol
+func supportsITerm2() bool {
+	return os.Getenv("TERM_PROGRAM") == "iTerm.app"
+}
+
+// supportsSixel will return whether or not the terminal supports sixel
+// graphics
+func supportsSixel() bool {
+	// XTERM_VERSION is only set by xterm itself, not by terminals that just
+	// claim to be xterm compatible.
+	if os.Getenv("XTERM_VERSION") != "" {
+		return true
+	}
+	termName := strings.ToLower(os.Getenv("TERM"))
+	termProgram := strings.ToLower(os.Getenv("TERM_PROGRAM"))
+	for _, prefix := range sixelTerms {
+		if strings.HasPrefix(termName, prefix) || strings.HasPrefix(termProgram, prefix) {
+			return true
+		}
+	}
+	return false
+}
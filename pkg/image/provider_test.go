@@ -0,0 +1,21 @@
+package image
+
+import "testing"
+
+func TestShouldRender(t *testing.T) {
+	canDisplay := CanDisplay
+	t.Cleanup(func() { CanDisplay = canDisplay })
+
+	CanDisplay = true
+	for format, want := range map[string]bool{"term": true, "json": false, "yaml": false, "csv": false} {
+		if got := ShouldRender(format); got != want {
+			t.Errorf("ShouldRender(%q) = %v, want %v", format, got, want)
+		}
+	}
+
+	// Nothing is rendered if the terminal has no graphics protocol
+	CanDisplay = false
+	if ShouldRender("term") {
+		t.Error("ShouldRender(\"term\") = true without a graphics protocol")
+	}
+}
@@ -0,0 +1,104 @@
+package image
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+
+	_ "image/gif"
+	_ "image/jpeg"
+
+	_ "golang.org/x/image/webp"
+
+	"golang.org/x/image/draw"
+)
+
+// DefaultPreviewWidth is the default maximum width in pixels of images
+// displayed in the terminal
+const DefaultPreviewWidth = 320
+
+// PreviewWidth is the maximum width in pixels of images displayed in the
+// terminal. Larger images are downscaled before they are displayed. A value of
+// zero disables downscaling.
+var PreviewWidth = DefaultPreviewWidth
+
+// Resize will return the path to a copy of the given image downscaled to the
+// given maximum width. Resized images are cached, keyed by the source path,
+// its modification time, and the target width. If the image is already small
+// enough, the original path is returned.
+func Resize(file string, maxWidth int) (string, error) {
+	if maxWidth <= 0 {
+		return file, nil
+	}
+	info, err := os.Stat(file)
+	if err != nil {
+		return "", err
+	}
+
+	// Check the cache first
+	cacheDir, err := previewCacheDir()
+	if err != nil {
+		return "", err
+	}
+	key := sha256.Sum256([]byte(fmt.Sprintf("%s:%d:%d", file, info.ModTime().UnixNano(), maxWidth)))
+	cached := filepath.Join(cacheDir, fmt.Sprintf("%x.png", key[:16]))
+	if _, err := os.Stat(cached); err == nil {
+		return cached, nil
+	}
+
+	f, err := os.Open(file)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	src, _, err := image.Decode(f)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", file, err)
+	}
+
+	bounds := src.Bounds()
+	if bounds.Dx() <= maxWidth {
+		return file, nil
+	}
+	height := bounds.Dy() * maxWidth / bounds.Dx()
+	if height < 1 {
+		height = 1
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, maxWidth, height))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, bounds, draw.Over, nil)
+
+	// Write to a temporary file first so a partially written preview is
+	// never picked up from the cache
+	if err := os.MkdirAll(cacheDir, 0755); err != nil {
+		return "", err
+	}
+	tmp, err := os.CreateTemp(cacheDir, "preview-*.png")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmp.Name())
+	if err := png.Encode(tmp, dst); err != nil {
+		tmp.Close()
+		return "", err
+	}
+	if err := tmp.Close(); err != nil {
+		return "", err
+	}
+	if err := os.Rename(tmp.Name(), cached); err != nil {
+		return "", err
+	}
+
+	return cached, nil
+}
+
+// previewCacheDir will return the directory resized images are cached in
+func previewCacheDir() (string, error) {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(cacheDir, "steam-shortcut-manager", "previews"), nil
+}
@@ -0,0 +1,132 @@
+package sixel
+
+import (
+	"bufio"
+	"fmt"
+	"image"
+	"image/color/palette"
+	"image/draw"
+	"io"
+	"os"
+
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+)
+
+// Display will render the given image file to the terminal using the sixel
+// graphics protocol.
+func Display(file string) error {
+	img, err := readImageFile(file)
+	if err != nil {
+		return fmt.Errorf("%s: %w", file, err)
+	}
+
+	w := bufio.NewWriter(os.Stdout)
+	if err := Encode(w, img); err != nil {
+		return err
+	}
+	fmt.Fprintln(w)
+	return w.Flush()
+}
+
+// Encode will write the given image as a sixel sequence. The image is reduced
+// to a 256 color palette with dithering.
+func Encode(w io.Writer, img image.Image) error {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width == 0 || height == 0 {
+		return nil
+	}
+
+	paletted := image.NewPaletted(image.Rect(0, 0, width, height), palette.Plan9)
+	draw.FloydSteinberg.Draw(paletted, paletted.Bounds(), img, bounds.Min)
+
+	// Start the sixel sequence with a 1:1 pixel aspect ratio
+	if _, err := fmt.Fprintf(w, "\033Pq\"1;1;%d;%d", width, height); err != nil {
+		return err
+	}
+
+	// Define the palette. Sixel colors are given as RGB percentages.
+	for i, c := range paletted.Palette {
+		r, g, b, _ := c.RGBA()
+		fmt.Fprintf(w, "#%d;2;%d;%d;%d", i, r*100/0xffff, g*100/0xffff, b*100/0xffff)
+	}
+
+	// Each band is six pixel rows tall. Every color used in the band is drawn
+	// in its own pass over the row.
+	used := make([]bool, len(paletted.Palette))
+	for y := 0; y < height; y += 6 {
+		for i := range used {
+			used[i] = false
+		}
+		for dy := 0; dy < 6 && y+dy < height; dy++ {
+			for x := 0; x < width; x++ {
+				used[paletted.ColorIndexAt(x, y+dy)] = true
+			}
+		}
+
+		first := true
+		for colorIndex, isUsed := range used {
+			if !isUsed {
+				continue
+			}
+			if !first {
+				// Return to the start of the band
+				fmt.Fprint(w, "$")
+			}
+			first = false
+			fmt.Fprintf(w, "#%d", colorIndex)
+
+			run, count := byte(0), 0
+			for x := 0; x < width; x++ {
+				var bits byte
+				for dy := 0; dy < 6 && y+dy < height; dy++ {
+					if int(paletted.ColorIndexAt(x, y+dy)) == colorIndex {
+						bits |= 1 << uint(dy)
+					}
+				}
+				if count > 0 && bits != run {
+					writeRun(w, run, count)
+					count = 0
+				}
+				run = bits
+				count++
+			}
+			writeRun(w, run, count)
+		}
+		// Move to the next band
+		fmt.Fprint(w, "-")
+	}
+
+	_, err := fmt.Fprint(w, "\033\\")
+	return err
+}
+
+// writeRun will write a run of the same sixel, using the repeat introducer
+// for longer runs.
+func writeRun(w io.Writer, bits byte, count int) {
+	char := bits + '?'
+	if count > 3 {
+		fmt.Fprintf(w, "!%d%c", count, char)
+		return
+	}
+	for i := 0; i < count; i++ {
+		fmt.Fprintf(w, "%c", char)
+	}
+}
+
+func readImageFile(path string) (image.Image, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	img, _, err := image.Decode(f)
+	if err != nil {
+		return nil, err
+	}
+
+	return img, nil
+}
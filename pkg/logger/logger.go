@@ -2,12 +2,147 @@ package logger
 
 import (
 	"fmt"
+	"io"
 	"os"
+	"sync"
 )
 
+// Level is the severity of a log message
+type Level int
+
+const (
+	// LevelDebug is used for messages only useful when debugging
+	LevelDebug Level = iota
+	// LevelInfo is used for informational messages
+	LevelInfo
+	// LevelWarning is used for problems that do not stop the operation
+	LevelWarning
+	// LevelError is used for failures
+	LevelError
+)
+
+// String will return the label printed in front of messages of this level
+func (l Level) String() string {
+	switch l {
+	case LevelDebug:
+		return "DEBUG"
+	case LevelInfo:
+		return "INFO"
+	case LevelWarning:
+		return "WARNING"
+	}
+	return "ERROR"
+}
+
+// Logger writes leveled messages. Messages below the logger's level are
+// discarded.
+type Logger struct {
+	mu    sync.Mutex
+	out   io.Writer
+	level Level
+}
+
+// New will return a new logger writing messages of at least the given level
+// to the given writer
+func New(out io.Writer, level Level) *Logger {
+	return &Logger{out: out, level: level}
+}
+
+// Default is the logger used by the package-level functions. User-facing
+// messages go to stderr so they never end up in machine-readable output.
+var Default = New(os.Stderr, defaultLevel())
+
+// defaultLevel will return the debug level if the DEBUG environment variable
+// is set, and the info level otherwise.
+func defaultLevel() Level {
+	if os.Getenv("DEBUG") != "" {
+		return LevelDebug
+	}
+	return LevelInfo
+}
+
+// SetLevel will set the minimum level of messages that are written
+func (l *Logger) SetLevel(level Level) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.level = level
+}
+
+// SetOutput will set the writer messages are written to
+func (l *Logger) SetOutput(out io.Writer) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.out = out
+}
+
+// Enabled will return whether or not messages of the given level are written
+func (l *Logger) Enabled(level Level) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return level >= l.level
+}
+
+// Logf will write a formatted message with the given level
+func (l *Logger) Logf(level Level, format string, a ...interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if level < l.level {
+		return
+	}
+	fmt.Fprintf(l.out, "[%v] %s\n", level, fmt.Sprintf(format, a...))
+}
+
+// Debugf will write a formatted debug message
+func (l *Logger) Debugf(format string, a ...interface{}) {
+	l.Logf(LevelDebug, format, a...)
+}
+
+// Infof will write a formatted informational message
+func (l *Logger) Infof(format string, a ...interface{}) {
+	l.Logf(LevelInfo, format, a...)
+}
+
+// Warningf will write a formatted warning message
+func (l *Logger) Warningf(format string, a ...interface{}) {
+	l.Logf(LevelWarning, format, a...)
+}
+
+// Errorf will write a formatted error message
+func (l *Logger) Errorf(format string, a ...interface{}) {
+	l.Logf(LevelError, format, a...)
+}
+
+// SetLevel will set the minimum level of the default logger
+func SetLevel(level Level) {
+	Default.SetLevel(level)
+}
+
+// Debugf will write a formatted debug message to the default logger
+func Debugf(format string, a ...interface{}) {
+	Default.Debugf(format, a...)
+}
+
+// Infof will write a formatted informational message to the default logger
+func Infof(format string, a ...interface{}) {
+	Default.Infof(format, a...)
+}
+
+// Warningf will write a formatted warning message to the default logger
+func Warningf(format string, a ...interface{}) {
+	Default.Warningf(format, a...)
+}
+
+// Errorf will write a formatted error message to the default logger
+func Errorf(format string, a ...interface{}) {
+	Default.Errorf(format, a...)
+}
+
 // Print debug messages if debug is enabled
 func DebugPrintln(s ...interface{}) {
-	if os.Getenv("DEBUG") != "" {
-		fmt.Println(s...)
+	if !Default.Enabled(LevelDebug) {
+		return
 	}
+	Default.mu.Lock()
+	defer Default.mu.Unlock()
+	fmt.Fprintln(Default.out, s...)
 }
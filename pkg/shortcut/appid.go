@@ -2,6 +2,9 @@ package shortcut
 
 import (
 	"hash/crc32"
+	"math"
+	"strconv"
+	"strings"
 )
 
 // https://github.com/boppreh/steamgrid/blob/9c8788db4f04613ecfb3e8fb36a0af02395e4593/games.go
@@ -22,3 +25,38 @@ func CalculateAppID(exe, name string) uint64 {
 	combined := exe + name
 	return uint64(crc32.ChecksumIEEE([]byte(combined))) | 0x80000000
 }
+
+// ParseAppID will parse a shortcut app ID. The ID can be given as an unsigned
+// number, as the signed 32-bit number some tools show, or in floating point
+// notation such as "3.663241086e+09". Returns false if the string is not a
+// 32-bit app ID.
+func ParseAppID(appID string) (uint32, bool) {
+	appID = strings.TrimSpace(appID)
+	if id, err := strconv.ParseUint(appID, 10, 32); err == nil {
+		return uint32(id), true
+	}
+	if id, err := strconv.ParseInt(appID, 10, 32); err == nil {
+		return uint32(id), true
+	}
+	id, err := strconv.ParseFloat(appID, 64)
+	if err != nil || id != math.Trunc(id) || id < math.MinInt32 || id > math.MaxUint32 {
+		return 0, false
+	}
+	return uint32(int64(id)), true
+}
+
+// sameAppID will return whether the given app IDs are the same 32-bit app
+// ID, whether they are stored signed or unsigned
+func sameAppID(a, b int64) bool {
+	return uint32(a) == uint32(b)
+}
+
+// normalizeAppIDs will store the app ID of every shortcut as its unsigned
+// 32-bit value, so shortcuts loaded with a signed app ID compare equal to
+// the ones loaded with an unsigned app ID
+func normalizeAppIDs(shortcuts *Shortcuts) {
+	for key, sc := range shortcuts.Shortcuts {
+		sc.Appid = int64(uint32(sc.Appid))
+		shortcuts.Shortcuts[key] = sc
+	}
+}
@@ -0,0 +1,55 @@
+package shortcut
+
+import "testing"
+
+func TestParseAppID(t *testing.T) {
+	tests := []struct {
+		value string
+		want  uint32
+		ok    bool
+	}{
+		{"3663241086", 3663241086, true},
+		{"-631726210", 3663241086, true},
+		{"3.663241086e+09", 3663241086, true},
+		{" 2147483649 ", 2147483649, true},
+		{"4294967295", 4294967295, true},
+		{"4294967296", 0, false},
+		{"3.5", 0, false},
+		{"all", 0, false},
+		{"", 0, false},
+	}
+	for _, test := range tests {
+		got, ok := ParseAppID(test.value)
+		if got != test.want || ok != test.ok {
+			t.Errorf("ParseAppID(%q) = %v, %v, want %v, %v", test.value, got, ok, test.want, test.ok)
+		}
+	}
+}
+
+func TestLookupByIDAboveInt32(t *testing.T) {
+	shortcuts := NewShortcuts()
+	shortcuts.Shortcuts["0"] = Shortcut{AppName: "Game", Appid: 3663241086}
+
+	// A signed ID from another tool matches the stored unsigned ID
+	for _, appId := range []int64{3663241086, -631726210} {
+		sc, err := shortcuts.LookupByID(appId)
+		if err != nil {
+			t.Errorf("LookupByID(%v) error = %v", appId, err)
+			continue
+		}
+		if sc.AppName != "Game" {
+			t.Errorf("LookupByID(%v) = %v, want Game", appId, sc.AppName)
+		}
+	}
+
+	err := shortcuts.UpdateByID(-631726210, func(sc *Shortcut) { sc.LaunchOptions = "--updated" })
+	if err != nil {
+		t.Fatalf("UpdateByID() error = %v", err)
+	}
+	if sc := shortcuts.Shortcuts["0"]; sc.LaunchOptions != "--updated" || sc.Appid != 3663241086 {
+		t.Errorf("UpdateByID() left %+v", sc)
+	}
+	if _, found := shortcuts.FindKey("Other", -631726210); !found {
+		t.Error("FindKey() did not match the signed app ID")
+	}
+}
@@ -0,0 +1,49 @@
+package shortcut
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitCommand(t *testing.T) {
+	tests := []struct {
+		command string
+		want    []string
+	}{
+		{"/usr/bin/game --fullscreen", []string{"/usr/bin/game", "--fullscreen"}},
+		{`"/home/deck/My Games/run.sh" -x`, []string{"/home/deck/My Games/run.sh", "-x"}},
+		{`'/home/deck/My Games/run.sh' 'a b'`, []string{"/home/deck/My Games/run.sh", "a b"}},
+		{`/home/deck/My\ Games/run.sh`, []string{"/home/deck/My Games/run.sh"}},
+		{`  spaced   out  `, []string{"spaced", "out"}},
+		{`empty ""`, []string{"empty", ""}},
+		{"", []string{}},
+	}
+	for _, test := range tests {
+		got, err := SplitCommand(test.command)
+		if err != nil {
+			t.Errorf("SplitCommand(%q) returned error: %v", test.command, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("SplitCommand(%q) = %q, want %q", test.command, got, test.want)
+		}
+	}
+}
+
+func TestSplitCommandUnterminatedQuote(t *testing.T) {
+	if _, err := SplitCommand(`"/home/deck/My Games/run.sh`); err == nil {
+		t.Error("expected an error for an unterminated quote")
+	}
+}
+
+func TestQuoteCommandWordRoundTrip(t *testing.T) {
+	for _, word := range []string{"plain", "with space", "it's", `back\slash`, ""} {
+		got, err := SplitCommand(QuoteCommandWord(word))
+		if err != nil {
+			t.Fatalf("SplitCommand(QuoteCommandWord(%q)) returned error: %v", word, err)
+		}
+		if len(got) != 1 || got[0] != word {
+			t.Errorf("SplitCommand(QuoteCommandWord(%q)) = %q", word, got)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package shortcut
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/shadowblip/steam-shortcut-manager/pkg/fsutil"
+)
+
+// LoadJSON will load shortcuts from the given portable JSON file. The file
+// uses the same layout as the shortcuts JSON output.
+func LoadJSON(file string) (*Shortcuts, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	var shortcuts Shortcuts
+	if err := json.Unmarshal(data, &shortcuts); err != nil {
+		return nil, fmt.Errorf("unable to parse %s: %w", file, err)
+	}
+	if shortcuts.Shortcuts == nil {
+		return nil, fmt.Errorf("unable to parse %s: missing \"shortcuts\" object", file)
+	}
+	for key := range shortcuts.Shortcuts {
+		if _, err := strconv.Atoi(key); err != nil {
+			return nil, fmt.Errorf("unable to parse %s: non-number shortcut key: %v", file, key)
+		}
+	}
+	normalizeAppIDs(&shortcuts)
+
+	return &shortcuts, nil
+}
+
+// MarshalJSON will encode the given shortcuts as portable JSON that can be
+// read back with LoadJSON. Discovered image paths are left out, since they
+// are specific to this machine.
+func MarshalJSON(shortcuts *Shortcuts) ([]byte, error) {
+	portable := &Shortcuts{Shortcuts: map[string]Shortcut{}}
+	for key, sc := range shortcuts.Shortcuts {
+		sc.Images = nil
+		portable.Shortcuts[key] = sc
+	}
+	return json.MarshalIndent(portable, "", "  ")
+}
+
+// SaveJSON will write the given shortcuts to a portable JSON file
+func SaveJSON(shortcuts *Shortcuts, file string) error {
+	data, err := MarshalJSON(shortcuts)
+	if err != nil {
+		return err
+	}
+	return fsutil.WriteFileAtomic(file, append(data, '\n'), 0644)
+}
@@ -2,7 +2,11 @@ package shortcut
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
 	"strconv"
+	"strings"
 )
 
 /*
@@ -49,9 +53,52 @@ func (s *Shortcuts) Add(shortcut *Shortcut) error {
 	return nil
 }
 
-// LookupByName will return a shortcut by name
+// Keys will return the shortcut keys in numeric order. Keys that are not
+// numbers are sorted after numeric keys.
+func (s *Shortcuts) Keys() []string {
+	keys := make([]string, 0, len(s.Shortcuts))
+	for key := range s.Shortcuts {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return lessNumeric(keys[i], keys[j])
+	})
+	return keys
+}
+
+// lessNumeric orders numeric keys by value, followed by non-numeric keys in
+// lexical order.
+func lessNumeric(a, b string) bool {
+	numA, errA := strconv.Atoi(a)
+	numB, errB := strconv.Atoi(b)
+	switch {
+	case errA == nil && errB == nil:
+		return numA < numB
+	case errA == nil:
+		return true
+	case errB == nil:
+		return false
+	}
+	return a < b
+}
+
+// FindKey will return the key of the first shortcut that has the given name
+// or app ID.
+func (s *Shortcuts) FindKey(name string, appId int64) (string, bool) {
+	for _, key := range s.Keys() {
+		sc := s.Shortcuts[key]
+		if sc.AppName == name || (appId != 0 && sameAppID(sc.Appid, appId)) {
+			return key, true
+		}
+	}
+	return "", false
+}
+
+// LookupByName will return the first shortcut with the given name, in key
+// order
 func (s *Shortcuts) LookupByName(name string) (*Shortcut, error) {
-	for _, sc := range s.Shortcuts {
+	for _, key := range s.Keys() {
+		sc := s.Shortcuts[key]
 		if sc.AppName == name {
 			return &sc, nil
 		}
@@ -59,16 +106,126 @@ func (s *Shortcuts) LookupByName(name string) (*Shortcut, error) {
 	return nil, fmt.Errorf("no shortcut found with name: %v", name)
 }
 
-// LookupByID will return a shortcut by name
+// LookupByID will return the first shortcut with the given app ID, in key
+// order
 func (s *Shortcuts) LookupByID(appId int64) (*Shortcut, error) {
-	for _, sc := range s.Shortcuts {
-		if sc.Appid == appId {
+	for _, key := range s.Keys() {
+		sc := s.Shortcuts[key]
+		if sameAppID(sc.Appid, appId) {
 			return &sc, nil
 		}
 	}
 	return nil, fmt.Errorf("no shortcut found with id: %v", appId)
 }
 
+// Update will apply the given changes to the shortcut with the given name. The
+// shortcut keeps its app ID, so any artwork stays attached to it.
+func (s *Shortcuts) Update(name string, mutate func(*Shortcut)) error {
+	for _, key := range s.Keys() {
+		if s.Shortcuts[key].AppName == name {
+			s.updateKey(key, mutate)
+			return nil
+		}
+	}
+	return fmt.Errorf("no shortcut found with name: %v", name)
+}
+
+// UpdateByID will apply the given changes to the shortcut with the given app
+// ID. The shortcut keeps its app ID.
+func (s *Shortcuts) UpdateByID(appId int64, mutate func(*Shortcut)) error {
+	for _, key := range s.Keys() {
+		if sameAppID(s.Shortcuts[key].Appid, appId) {
+			s.updateKey(key, mutate)
+			return nil
+		}
+	}
+	return fmt.Errorf("no shortcut found with id: %v", appId)
+}
+
+// ChangeAppID will give the shortcut with the given app ID a new app ID.
+// Returns an error if another shortcut already has the new app ID.
+func (s *Shortcuts) ChangeAppID(appId, newAppId int64) error {
+	if sameAppID(appId, newAppId) {
+		return nil
+	}
+	if _, err := s.LookupByID(newAppId); err == nil {
+		return fmt.Errorf("another shortcut already has id: %v", newAppId)
+	}
+	for _, key := range s.Keys() {
+		sc := s.Shortcuts[key]
+		if sameAppID(sc.Appid, appId) {
+			sc.Appid = int64(uint32(newAppId))
+			s.Shortcuts[key] = sc
+			return nil
+		}
+	}
+	return fmt.Errorf("no shortcut found with id: %v", appId)
+}
+
+func (s *Shortcuts) updateKey(key string, mutate func(*Shortcut)) {
+	sc := s.Shortcuts[key]
+	appId := sc.Appid
+	mutate(&sc)
+	sc.Appid = appId
+	s.Shortcuts[key] = sc
+}
+
+// Dedupe will remove duplicate shortcuts, which are shortcuts with the same
+// executable and name, or the same app ID. Of each set of duplicates, the
+// shortcut with the most artwork and tags populated is kept, preferring the
+// first one. Grid artwork is only taken into account if the Images of the
+// shortcuts have been resolved. The remaining shortcuts are re-keyed
+// sequentially. Returns the number of shortcuts that were removed.
+func (s *Shortcuts) Dedupe() int {
+	kept := []Shortcut{}
+	removed := 0
+	for _, key := range s.Keys() {
+		sc := s.Shortcuts[key]
+		duplicate := -1
+		for i, other := range kept {
+			if (sc.Exe == other.Exe && sc.AppName == other.AppName) || (sc.Appid != 0 && sc.Appid == other.Appid) {
+				duplicate = i
+				break
+			}
+		}
+		if duplicate < 0 {
+			kept = append(kept, sc)
+			continue
+		}
+		removed++
+		if sc.completeness() > kept[duplicate].completeness() {
+			kept[duplicate] = sc
+		}
+	}
+
+	s.Shortcuts = map[string]Shortcut{}
+	for i, sc := range kept {
+		s.Shortcuts[strconv.Itoa(i)] = sc
+	}
+
+	return removed
+}
+
+// completeness will return how many of the optional artwork and tag fields of
+// the shortcut are populated
+func (s *Shortcut) completeness() int {
+	count := 0
+	if s.Icon != "" {
+		count++
+	}
+	if len(s.Tags) > 0 {
+		count++
+	}
+	if s.Images != nil {
+		for _, image := range []string{s.Images.Portrait, s.Images.Landscape, s.Images.Hero, s.Images.Logo, s.Images.Icon} {
+			if image != "" {
+				count++
+			}
+		}
+	}
+	return count
+}
+
 // Get the next shortcut id
 func (s *Shortcuts) getNextKey() (string, error) {
 	highestKey := -1
@@ -104,6 +261,22 @@ func NewShortcut(name, exe string, settings ...ShortcutSetting) *Shortcut {
 	return shortcut
 }
 
+// DefaultStartDir will return the start directory to use for the given
+// executable, which is the directory containing it. The result is quoted if
+// the executable is. Returns an empty string for bare commands looked up in
+// the PATH.
+func DefaultStartDir(exe string) string {
+	path := Unquote(exe)
+	if !filepath.IsAbs(path) && !strings.ContainsRune(path, os.PathSeparator) {
+		return ""
+	}
+	dir := filepath.Dir(path)
+	if IsQuoted(exe) {
+		return `"` + dir + `"`
+	}
+	return dir
+}
+
 // Shortcut defines a single shortcut entry in the VDF file
 type Shortcut struct {
 	AllowDesktopConfig  int                    `json:"AllowDesktopConfig"`
@@ -126,6 +299,60 @@ type Shortcut struct {
 	Images              *Images                `json:"images,omitempty"`
 }
 
+// TagList will return the tags of the shortcut in order
+func (s *Shortcut) TagList() []string {
+	tags := make([]string, 0, len(s.Tags))
+	for _, key := range sortedTagKeys(s.Tags) {
+		tags = append(tags, fmt.Sprintf("%v", s.Tags[key]))
+	}
+	return tags
+}
+
+// SetTags will replace the tags of the shortcut with the given tags, keyed
+// sequentially like Steam does.
+func (s *Shortcut) SetTags(tags []string) {
+	s.Tags = map[string]interface{}{}
+	for key, tag := range tags {
+		s.Tags[fmt.Sprintf("%v", key)] = tag
+	}
+}
+
+// HasTag will return whether or not the shortcut has the given tag
+func (s *Shortcut) HasTag(tag string) bool {
+	for _, existing := range s.TagList() {
+		if existing == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// AddTag will add the given tag to the end of the shortcut's tags. Returns
+// false if the shortcut already has the tag.
+func (s *Shortcut) AddTag(tag string) bool {
+	if s.HasTag(tag) {
+		return false
+	}
+	s.SetTags(append(s.TagList(), tag))
+	return true
+}
+
+// RemoveTag will remove the given tag from the shortcut, re-keying the
+// remaining tags. Returns false if the shortcut does not have the tag.
+func (s *Shortcut) RemoveTag(tag string) bool {
+	tags := []string{}
+	for _, existing := range s.TagList() {
+		if existing != tag {
+			tags = append(tags, existing)
+		}
+	}
+	if len(tags) == len(s.Tags) {
+		return false
+	}
+	s.SetTags(tags)
+	return true
+}
+
 // Images is a structure that holds the paths to grid images for a shortcut.
 type Images struct {
 	Portrait  string `json:"portrait"`
@@ -133,4 +360,18 @@ type Images struct {
 	Hero      string `json:"hero"`
 	Logo      string `json:"logo"`
 	Icon      string `json:"icon"`
+	// Corrupt lists any discovered image paths that are empty or could not
+	// be decoded.
+	Corrupt []string `json:"corrupt,omitempty"`
+}
+
+// IsCorrupt will return whether or not the given image path was flagged as
+// corrupt during discovery.
+func (i *Images) IsCorrupt(path string) bool {
+	for _, p := range i.Corrupt {
+		if p == path {
+			return true
+		}
+	}
+	return false
 }
@@ -0,0 +1,46 @@
+package shortcut
+
+import (
+	"testing"
+)
+
+func TestDedupeKeepsShortcutWithArtwork(t *testing.T) {
+	shortcuts := NewShortcuts()
+	shortcuts.Shortcuts["0"] = Shortcut{AppName: "Game", Exe: "/bin/game", Appid: 1}
+	shortcuts.Shortcuts["1"] = Shortcut{AppName: "Other", Exe: "/bin/other", Appid: 2}
+	shortcuts.Shortcuts["2"] = Shortcut{
+		AppName: "Game",
+		Exe:     "/bin/game",
+		Appid:   3,
+		Images:  &Images{Portrait: "/grid/3p.png", Hero: "/grid/3_hero.png"},
+	}
+
+	if removed := shortcuts.Dedupe(); removed != 1 {
+		t.Fatalf("Dedupe() removed %d shortcuts, want 1", removed)
+	}
+	if len(shortcuts.Shortcuts) != 2 {
+		t.Fatalf("got %d shortcuts, want 2", len(shortcuts.Shortcuts))
+	}
+
+	// The duplicate with artwork replaces the first one in its place, and the
+	// keys stay sequential
+	if got := shortcuts.Shortcuts["0"]; got.Appid != 3 {
+		t.Errorf("kept shortcut has app ID %v, want 3 (the one with artwork)", got.Appid)
+	}
+	if got := shortcuts.Shortcuts["1"]; got.AppName != "Other" {
+		t.Errorf("second shortcut is %v, want Other", got.AppName)
+	}
+}
+
+func TestDedupeKeepsFirstOnTie(t *testing.T) {
+	shortcuts := NewShortcuts()
+	shortcuts.Shortcuts["0"] = Shortcut{AppName: "Game", Exe: "/bin/game", Appid: 1, Tags: map[string]interface{}{"0": "a"}}
+	shortcuts.Shortcuts["1"] = Shortcut{AppName: "Renamed", Exe: "/bin/other", Appid: 1, Tags: map[string]interface{}{"0": "b"}}
+
+	if removed := shortcuts.Dedupe(); removed != 1 {
+		t.Fatalf("Dedupe() removed %d shortcuts, want 1", removed)
+	}
+	if got := shortcuts.Shortcuts["0"]; got.AppName != "Game" {
+		t.Errorf("kept shortcut is %v, want the first one", got.AppName)
+	}
+}
@@ -2,9 +2,14 @@ package shortcut
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
+	"sort"
+	"time"
 
+	"github.com/shadowblip/steam-shortcut-manager/pkg/fsutil"
 	"github.com/wakeful-cloud/vdf"
 )
 
@@ -15,6 +20,12 @@ func Load(file string) (*Shortcuts, error) {
 		return nil, err
 	}
 
+	// Make sure the file is intact before parsing it
+	if err := checkVDF(bytes); err != nil {
+		err.(*CorruptVDFError).File = file
+		return nil, err
+	}
+
 	// Parse the VDF file
 	vdfMap, err := vdf.ReadVdf(bytes)
 	if err != nil {
@@ -33,61 +44,116 @@ func Load(file string) (*Shortcuts, error) {
 	if err != nil {
 		return nil, err
 	}
+	normalizeAppIDs(&shortcuts)
 
 	return &shortcuts, nil
 }
 
-// Save the given shortcuts file
-func Save(shortcuts *Shortcuts, file string) error {
-	// Convert the struct to JSON so we can map it to a VDF map
-	rawJSON, err := json.Marshal(shortcuts)
-	if err != nil {
-		return fmt.Errorf("unable to marshal to JSON: %v", err)
+// LoadOrNew will load the given shortcuts file. Steam only creates the file
+// once the first shortcut is added, so if it does not exist yet an empty set
+// of shortcuts is returned instead of an error.
+func LoadOrNew(file string) (*Shortcuts, error) {
+	shortcuts, err := Load(file)
+	if errors.Is(err, os.ErrNotExist) {
+		return NewShortcuts(), nil
+	}
+	return shortcuts, err
+}
+
+// LoadSafe will load the given shortcuts file. If the file is corrupt, the
+// newest backup made by Backup that can be loaded is used instead and its
+// path is returned. If there is no usable backup, the CorruptVDFError of the
+// shortcuts file is returned.
+func LoadSafe(file string) (*Shortcuts, string, error) {
+	shortcuts, err := Load(file)
+	if !errors.Is(err, ErrCorruptVDF) {
+		return shortcuts, "", err
+	}
+
+	backups, backupErr := FindBackups(file)
+	if backupErr != nil {
+		return nil, "", err
+	}
+	for _, backup := range backups {
+		if shortcuts, backupErr := Load(backup); backupErr == nil {
+			return shortcuts, backup, nil
+		}
 	}
 
-	// Marshal the shortcut into a VDF map
-	var vdfMap map[string]interface{}
-	err = json.Unmarshal(rawJSON, &vdfMap)
+	return nil, "", err
+}
+
+// FindBackups will return the backups made by Backup for the given shortcuts
+// file, newest first.
+func FindBackups(file string) ([]string, error) {
+	backups, err := filepath.Glob(file + ".*.bak")
 	if err != nil {
-		return fmt.Errorf("unable to unmarshal to VDF Map: %v", err)
+		return nil, err
 	}
+	sort.Sort(sort.Reverse(sort.StringSlice(backups)))
+	return backups, nil
+}
+
+// backupTimeFormat is the timestamp in backup names. It has a fixed width, so
+// the names sort in the order the backups were made.
+const backupTimeFormat = "20060102-150405.000000000"
 
-	// Save the shortcuts
-	rawVdf, err := vdf.WriteVdf(ensureVDFMap(vdfMap))
+// Backup will copy the given shortcuts file next to itself with a timestamp
+// and sequence number suffix. Backups made at the same time get increasing
+// sequence numbers, so they never overwrite each other. Returns the path of
+// the backup.
+func Backup(file string) (string, error) {
+	data, err := os.ReadFile(file)
 	if err != nil {
-		return fmt.Errorf("unable to convert VDF to bytes: %v", err)
+		return "", err
 	}
 
-	// Write the file
-	err = os.WriteFile(file, rawVdf, 0666)
+	backupPath, err := reserveBackupPath(file, time.Now().Format(backupTimeFormat))
 	if err != nil {
-		return fmt.Errorf("unable to write VDF file: %v", err)
+		return "", fmt.Errorf("unable to write backup: %v", err)
+	}
+	if err := fsutil.WriteFileAtomic(backupPath, data, 0644); err != nil {
+		os.Remove(backupPath)
+		return "", fmt.Errorf("unable to write backup: %v", err)
 	}
 
-	return nil
+	return backupPath, nil
 }
 
-// ensureVDFMap ensures the given map is a vdf.Map with correct types
-func ensureVDFMap(m map[string]interface{}) vdf.Map {
-	var newMap vdf.Map = vdf.Map{}
-	for k, v := range m {
-		if v == nil {
-			// Skip nil values - VDF doesn't support them
-			continue
+// reserveBackupPath will create an empty backup file for the given shortcuts
+// file and timestamp, using the first sequence number no other backup has.
+// Returns the path of the backup.
+func reserveBackupPath(file, stamp string) (string, error) {
+	for i := 0; ; i++ {
+		backupPath := fmt.Sprintf("%s.%s-%02d.bak", file, stamp, i)
+		reserved, err := os.OpenFile(backupPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if err == nil {
+			return backupPath, reserved.Close()
 		}
-		switch val := v.(type) {
-		case int:
-			newMap[k] = uint32(val)
-		case int64:
-			newMap[k] = uint32(val)
-		case float64:
-			newMap[k] = uint32(val)
-		case string:
-			newMap[k] = val
-		case map[string]interface{}:
-			newMap[k] = ensureVDFMap(val)
-		// Skip any other types that VDF doesn't support
+		if !errors.Is(err, os.ErrExist) {
+			return "", err
 		}
 	}
-	return newMap
+}
+
+// Save the given shortcuts file
+func Save(shortcuts *Shortcuts, file string) error {
+	// Encode the shortcuts using Steam's binary VDF layout
+	rawVdf, err := marshalVDF(shortcuts)
+	if err != nil {
+		return fmt.Errorf("unable to convert VDF to bytes: %v", err)
+	}
+
+	// Make sure the user's config directory exists
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return fmt.Errorf("unable to create shortcuts directory: %v", err)
+	}
+
+	// Write the file
+	err = fsutil.WriteFileAtomic(file, rawVdf, 0644)
+	if err != nil {
+		return fmt.Errorf("unable to write VDF file: %v", err)
+	}
+
+	return nil
 }
@@ -0,0 +1,155 @@
+package shortcut
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSaveLoadPreservesOrder(t *testing.T) {
+	// Keys above 9 make sure the order is numeric rather than lexical, and
+	// the gap makes sure the entries are re-keyed without being reordered
+	shortcuts := NewShortcuts()
+	names := []string{}
+	for i := 0; i < 12; i++ {
+		if i == 4 {
+			continue
+		}
+		name := fmt.Sprintf("Game %d", i)
+		names = append(names, name)
+		shortcuts.Shortcuts[fmt.Sprintf("%d", i)] = Shortcut{AppName: name, Exe: "/bin/game", Appid: int64(0x80000000 + i)}
+	}
+
+	file := filepath.Join(t.TempDir(), "shortcuts.vdf")
+	if err := Save(shortcuts, file); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := Load(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keys := loaded.Keys()
+	if len(keys) != len(names) {
+		t.Fatalf("loaded %d shortcuts, want %d", len(keys), len(names))
+	}
+	for i, key := range keys {
+		if key != fmt.Sprintf("%d", i) {
+			t.Errorf("key %d is %v, want sequential keys", i, key)
+		}
+		if got := loaded.Shortcuts[key].AppName; got != names[i] {
+			t.Errorf("shortcut %d is %v, want %v", i, got, names[i])
+		}
+	}
+
+	// Saving the loaded shortcuts again must produce the same file
+	first, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Save(loaded, file); err != nil {
+		t.Fatal(err)
+	}
+	second, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(first, second) {
+		t.Error("saving the loaded shortcuts changed the file")
+	}
+}
+
+func TestLookupReturnsFirstDuplicate(t *testing.T) {
+	shortcuts := NewShortcuts()
+	for i := 0; i < 20; i++ {
+		shortcuts.Shortcuts[fmt.Sprintf("%d", i)] = Shortcut{AppName: "Game", Exe: fmt.Sprintf("/bin/game%d", i), Appid: 1}
+	}
+
+	for i := 0; i < 10; i++ {
+		byName, err := shortcuts.LookupByName("Game")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if byName.Exe != "/bin/game0" {
+			t.Fatalf("LookupByName returned %v, want the first shortcut", byName.Exe)
+		}
+		byID, err := shortcuts.LookupByID(1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if byID.Exe != "/bin/game0" {
+			t.Fatalf("LookupByID returned %v, want the first shortcut", byID.Exe)
+		}
+	}
+}
+
+func TestBackupsInTheSameSecondDoNotCollide(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "shortcuts.vdf")
+	made := []string{}
+	for i := 0; i < 5; i++ {
+		data := []byte(fmt.Sprintf("version %d", i))
+		if err := os.WriteFile(file, data, 0644); err != nil {
+			t.Fatal(err)
+		}
+		backup, err := Backup(file)
+		if err != nil {
+			t.Fatalf("Backup() error = %v", err)
+		}
+		made = append(made, backup)
+	}
+
+	// Every backup is kept with its own contents
+	for i, backup := range made {
+		data, err := os.ReadFile(backup)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := fmt.Sprintf("version %d", i); string(data) != want {
+			t.Errorf("backup %d contains %q, want %q", i, data, want)
+		}
+	}
+
+	// Backups are found newest first
+	found, err := FindBackups(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(found) != len(made) {
+		t.Fatalf("FindBackups() = %v, want %d backups", found, len(made))
+	}
+	for i := range made {
+		if want := made[len(made)-1-i]; found[i] != want {
+			t.Errorf("FindBackups()[%d] = %v, want %v", i, found[i], want)
+		}
+	}
+}
+
+func TestReserveBackupPathSameTimestamp(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "shortcuts.vdf")
+	stamp := time.Now().Format(backupTimeFormat)
+
+	reserved := []string{}
+	for i := 0; i < 3; i++ {
+		backup, err := reserveBackupPath(file, stamp)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := fmt.Sprintf("%s.%s-%02d.bak", file, stamp, i); backup != want {
+			t.Errorf("reserveBackupPath() = %v, want %v", backup, want)
+		}
+		reserved = append(reserved, backup)
+	}
+
+	found, err := FindBackups(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range reserved {
+		if want := reserved[len(reserved)-1-i]; found[i] != want {
+			t.Errorf("FindBackups()[%d] = %v, want %v", i, found[i], want)
+		}
+	}
+}
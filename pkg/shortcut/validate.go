@@ -0,0 +1,166 @@
+package shortcut
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	multierror "github.com/hashicorp/go-multierror"
+)
+
+// Validate will check that the shortcut has everything Steam needs to show a
+// working entry. All problems found are returned together. The shortcut is
+// not modified, so paths with spaces must be quoted first, see NormalizePaths.
+func (s *Shortcut) Validate(searchDirs ...string) error {
+	var errors error
+
+	if strings.TrimSpace(s.AppName) == "" {
+		errors = multierror.Append(errors, fmt.Errorf("app name is empty"))
+	}
+	if err := s.ValidateExe(searchDirs...); err != nil {
+		errors = multierror.Append(errors, err)
+	}
+	if err := s.ValidateStartDir(searchDirs...); err != nil {
+		errors = multierror.Append(errors, err)
+	}
+
+	return errors
+}
+
+// ValidateFields will check that the shortcut fields are well-formed without
+// touching the filesystem. This is useful for validating shortcuts meant for
+// another machine.
+func (s *Shortcut) ValidateFields() error {
+	var errors error
+
+	if strings.TrimSpace(s.AppName) == "" {
+		errors = multierror.Append(errors, fmt.Errorf("app name is empty"))
+	}
+	if strings.TrimSpace(Unquote(s.Exe)) == "" {
+		errors = multierror.Append(errors, fmt.Errorf("exe is empty"))
+	} else if err := checkQuoted("exe path", s.Exe); err != nil {
+		errors = multierror.Append(errors, err)
+	}
+	if err := checkQuoted("start dir", s.StartDir); err != nil {
+		errors = multierror.Append(errors, err)
+	}
+
+	return errors
+}
+
+// ValidateExe will check that the shortcut executable is set, properly quoted,
+// and exists. Relative paths are looked up in the given search directories.
+func (s *Shortcut) ValidateExe(searchDirs ...string) error {
+	var errors error
+
+	exe := Unquote(s.Exe)
+	if strings.TrimSpace(exe) == "" {
+		return fmt.Errorf("exe is empty")
+	}
+	if err := checkQuoted("exe path", s.Exe); err != nil {
+		errors = multierror.Append(errors, err)
+	}
+	if err := checkExecutable(exe, searchDirs); err != nil {
+		errors = multierror.Append(errors, err)
+	}
+
+	return errors
+}
+
+// ValidateStartDir will check that the shortcut start directory, if one is
+// set, is properly quoted and exists. Relative paths are looked up in the given
+// search directories.
+func (s *Shortcut) ValidateStartDir(searchDirs ...string) error {
+	var errors error
+
+	startDir := Unquote(s.StartDir)
+	if startDir == "" {
+		return nil
+	}
+	if err := checkQuoted("start dir", s.StartDir); err != nil {
+		errors = multierror.Append(errors, err)
+	}
+	info, err := os.Stat(resolvePath(startDir, searchDirs))
+	if err != nil {
+		errors = multierror.Append(errors, fmt.Errorf("start dir does not exist: %v", startDir))
+	} else if !info.IsDir() {
+		errors = multierror.Append(errors, fmt.Errorf("start dir is not a directory: %v", startDir))
+	}
+
+	return errors
+}
+
+// IsQuoted will return whether or not the given value is wrapped in double
+// quotes.
+func IsQuoted(value string) bool {
+	return len(value) >= 2 && strings.HasPrefix(value, `"`) && strings.HasSuffix(value, `"`)
+}
+
+// Unquote will remove the double quotes Steam wraps around paths
+func Unquote(value string) string {
+	if IsQuoted(value) {
+		return value[1 : len(value)-1]
+	}
+	return value
+}
+
+// QuotePath will wrap the given path in double quotes if it contains
+// whitespace and is not quoted already, like Steam does.
+func QuotePath(value string) string {
+	if value == "" || IsQuoted(value) || !strings.ContainsAny(value, " \t") {
+		return value
+	}
+	return `"` + value + `"`
+}
+
+// NormalizePaths will quote the exe path and start directory of the shortcut
+// if they need to be, matching the way Steam stores them
+func (s *Shortcut) NormalizePaths() {
+	s.Exe = QuotePath(s.Exe)
+	s.StartDir = QuotePath(s.StartDir)
+}
+
+// checkQuoted will return an error if the given path contains spaces but is
+// not wrapped in quotes.
+func checkQuoted(name, value string) error {
+	if !IsQuoted(value) && strings.Contains(value, " ") {
+		return fmt.Errorf("%s contains spaces and is not quoted: %v", name, value)
+	}
+	return nil
+}
+
+// checkExecutable will check that the given executable exists, either as a
+// path or as a command in the PATH.
+func checkExecutable(exe string, searchDirs []string) error {
+	if filepath.IsAbs(exe) || strings.ContainsRune(exe, os.PathSeparator) {
+		if _, err := os.Stat(resolvePath(exe, searchDirs)); err != nil {
+			return fmt.Errorf("exe does not exist: %v", exe)
+		}
+		return nil
+	}
+	if _, err := exec.LookPath(exe); err != nil {
+		return fmt.Errorf("exe not found in PATH: %v", exe)
+	}
+	return nil
+}
+
+// resolvePath will return the given path if it is absolute, otherwise the
+// first matching path inside the given search directories. If nothing matches,
+// the path is returned unchanged.
+func resolvePath(p string, searchDirs []string) string {
+	if filepath.IsAbs(p) {
+		return p
+	}
+	if _, err := os.Stat(p); err == nil {
+		return p
+	}
+	for _, dir := range searchDirs {
+		candidate := filepath.Join(dir, p)
+		if _, err := os.Stat(candidate); err == nil {
+			return candidate
+		}
+	}
+	return p
+}
@@ -0,0 +1,82 @@
+package shortcut
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestQuotePath(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{`C:\Program Files (x86)\My Game\game.exe`, `"C:\Program Files (x86)\My Game\game.exe"`},
+		{`/opt/games/doom(1993)/run.sh`, `/opt/games/doom(1993)/run.sh`},
+		{`/opt/My Game (GOG)/start.sh`, `"/opt/My Game (GOG)/start.sh"`},
+		{"/opt/game\tdir/run", "\"/opt/game\tdir/run\""},
+		{`"/opt/My Game/start.sh"`, `"/opt/My Game/start.sh"`},
+		{`/usr/bin/game`, `/usr/bin/game`},
+		{``, ``},
+	}
+	for _, test := range tests {
+		if got := QuotePath(test.path); got != test.want {
+			t.Errorf("QuotePath(%q) = %q, want %q", test.path, got, test.want)
+		}
+	}
+}
+
+func TestNormalizePaths(t *testing.T) {
+	sc := Shortcut{
+		AppName:  "Game",
+		Exe:      `C:\Program Files\My Game\game.exe`,
+		StartDir: `C:\Program Files\My Game\`,
+	}
+	sc.NormalizePaths()
+	if sc.Exe != `"C:\Program Files\My Game\game.exe"` {
+		t.Errorf("Exe = %q, want it quoted", sc.Exe)
+	}
+	if sc.StartDir != `"C:\Program Files\My Game\"` {
+		t.Errorf("StartDir = %q, want it quoted", sc.StartDir)
+	}
+	if err := sc.ValidateFields(); err != nil {
+		t.Errorf("ValidateFields() of the quoted paths = %v", err)
+	}
+
+	sc = Shortcut{Exe: "/opt/doom(1993)/run.sh", StartDir: "/opt/doom(1993)/"}
+	sc.NormalizePaths()
+	if sc.Exe != "/opt/doom(1993)/run.sh" || sc.StartDir != "/opt/doom(1993)/" {
+		t.Errorf("paths without whitespace were changed: %q, %q", sc.Exe, sc.StartDir)
+	}
+}
+
+func TestValidateReportsUnquotedPathsWithSpaces(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "My Game (Linux)")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	exe := filepath.Join(dir, "run.sh")
+	if err := os.WriteFile(exe, []byte("#!/bin/sh\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	sc := Shortcut{AppName: "Game", Exe: exe, StartDir: dir}
+	err := sc.Validate()
+	if err == nil {
+		t.Fatal("Validate() error = nil, want the unquoted paths reported")
+	}
+	for _, want := range []string{"exe path contains spaces", "start dir contains spaces"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Validate() error = %v, want %q", err, want)
+		}
+	}
+	if sc.Exe != exe || sc.StartDir != dir {
+		t.Errorf("Validate() changed the shortcut to Exe = %q, StartDir = %q", sc.Exe, sc.StartDir)
+	}
+
+	sc.NormalizePaths()
+	if err := sc.Validate(); err != nil {
+		t.Errorf("Validate() after NormalizePaths() error = %v", err)
+	}
+}
@@ -0,0 +1,182 @@
+package shortcut
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Binary VDF type markers
+const (
+	vdfMapStart byte = 0x00
+	vdfString   byte = 0x01
+	vdfNumber   byte = 0x02
+	vdfMapEnd   byte = 0x08
+)
+
+// ErrCorruptVDF is returned when a shortcuts file is not valid binary VDF,
+// for example because Steam crashed while writing it.
+var ErrCorruptVDF = errors.New("shortcuts file is corrupt")
+
+// CorruptVDFError describes where a binary VDF document is corrupt. It
+// matches ErrCorruptVDF with errors.Is.
+type CorruptVDFError struct {
+	File   string
+	Offset int
+	Reason string
+}
+
+func (e *CorruptVDFError) Error() string {
+	return fmt.Sprintf("%s: %v at byte %d: %s", e.File, ErrCorruptVDF, e.Offset, e.Reason)
+}
+
+func (e *CorruptVDFError) Is(target error) bool {
+	return target == ErrCorruptVDF
+}
+
+// checkVDF will walk the given binary VDF document and return a
+// CorruptVDFError describing the first problem found, if any.
+func checkVDF(data []byte) error {
+	corrupt := func(offset int, reason string) error {
+		return &CorruptVDFError{Offset: offset, Reason: reason}
+	}
+
+	pos, depth := 0, 0
+	for {
+		if pos >= len(data) {
+			return corrupt(pos, "unexpected end of file")
+		}
+		start, kind := pos, data[pos]
+		pos++
+		if kind == vdfMapEnd {
+			if depth == 0 {
+				return nil
+			}
+			depth--
+			continue
+		}
+
+		// Every other item starts with a NUL terminated key
+		end := bytes.IndexByte(data[pos:], 0)
+		if end == -1 {
+			return corrupt(pos, "unterminated key")
+		}
+		pos += end + 1
+
+		switch kind {
+		case vdfMapStart:
+			depth++
+		case vdfString:
+			end := bytes.IndexByte(data[pos:], 0)
+			if end == -1 {
+				return corrupt(pos, "unterminated string value")
+			}
+			pos += end + 1
+		case vdfNumber:
+			if pos+4 > len(data) {
+				return corrupt(pos, "truncated number value")
+			}
+			pos += 4
+		default:
+			return corrupt(start, fmt.Sprintf("unknown value type 0x%02x", kind))
+		}
+	}
+}
+
+// vdfWriter builds a binary VDF document
+type vdfWriter struct {
+	buf bytes.Buffer
+	err error
+}
+
+func (w *vdfWriter) key(kind byte, key string) {
+	w.buf.WriteByte(kind)
+	w.cstring(key)
+}
+
+func (w *vdfWriter) cstring(value string) {
+	if strings.IndexByte(value, 0) != -1 && w.err == nil {
+		w.err = fmt.Errorf("NUL byte found in value: %q", value)
+	}
+	w.buf.WriteString(value)
+	w.buf.WriteByte(0)
+}
+
+func (w *vdfWriter) startMap(key string) {
+	w.key(vdfMapStart, key)
+}
+
+func (w *vdfWriter) endMap() {
+	w.buf.WriteByte(vdfMapEnd)
+}
+
+func (w *vdfWriter) str(key, value string) {
+	w.key(vdfString, key)
+	w.cstring(value)
+}
+
+func (w *vdfWriter) num(key string, value uint32) {
+	w.key(vdfNumber, key)
+	var raw [4]byte
+	binary.LittleEndian.PutUint32(raw[:], value)
+	w.buf.Write(raw[:])
+}
+
+// marshalVDF will encode the given shortcuts using the exact binary layout
+// Steam writes: shortcut entries in numeric key order, re-keyed sequentially
+// from "0" so gaps left by removed entries are closed, each entry's fields in
+// Steam's own order, an always-present "tags" map, and the closing map end
+// markers for the entry, the "shortcuts" map, and the document itself.
+func marshalVDF(shortcuts *Shortcuts) ([]byte, error) {
+	w := &vdfWriter{}
+	w.startMap("shortcuts")
+	for i, key := range shortcuts.Keys() {
+		sc := shortcuts.Shortcuts[key]
+		w.startMap(strconv.Itoa(i))
+		w.num("appid", uint32(sc.Appid))
+		w.str("AppName", sc.AppName)
+		w.str("Exe", sc.Exe)
+		w.str("StartDir", sc.StartDir)
+		w.str("icon", sc.Icon)
+		w.str("ShortcutPath", sc.ShortcutPath)
+		w.str("LaunchOptions", sc.LaunchOptions)
+		w.num("IsHidden", uint32(sc.IsHidden))
+		w.num("AllowDesktopConfig", uint32(sc.AllowDesktopConfig))
+		w.num("AllowOverlay", uint32(sc.AllowOverlay))
+		w.num("OpenVR", uint32(sc.OpenVR))
+		w.num("Devkit", uint32(sc.Devkit))
+		w.str("DevkitGameID", sc.DevkitGameID)
+		w.num("DevkitOverrideAppID", uint32(sc.DevkitOverrideAppID))
+		w.num("LastPlayTime", uint32(sc.LastPlayTime))
+		w.str("FlatpakAppID", sc.FlatpakAppID)
+		w.startMap("tags")
+		for _, tagKey := range sortedTagKeys(sc.Tags) {
+			w.str(tagKey, fmt.Sprintf("%v", sc.Tags[tagKey]))
+		}
+		w.endMap() // tags
+		w.endMap() // shortcut entry
+	}
+	w.endMap() // shortcuts
+	w.endMap() // document
+
+	if w.err != nil {
+		return nil, w.err
+	}
+	return w.buf.Bytes(), nil
+}
+
+// sortedTagKeys will return the keys of the given tags map in numeric order
+func sortedTagKeys(tags map[string]interface{}) []string {
+	keys := make([]string, 0, len(tags))
+	for key := range tags {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return lessNumeric(keys[i], keys[j])
+	})
+	return keys
+}
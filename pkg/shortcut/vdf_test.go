@@ -0,0 +1,62 @@
+package shortcut
+
+import (
+	"bytes"
+	"os"
+	"testing"
+)
+
+// testdata/shortcuts.vdf follows the layout Steam writes: every entry has its
+// fields in Steam's order, an always-present "tags" map, and the tags, entry,
+// "shortcuts" and document maps are each closed with a 0x08 byte. The second
+// entry has no tags, so the file ends with four 0x08 bytes.
+const fixture = "testdata/shortcuts.vdf"
+
+func TestMarshalVDFMatchesFixture(t *testing.T) {
+	want, err := os.ReadFile(fixture)
+	if err != nil {
+		t.Fatal(err)
+	}
+	shortcuts, err := Load(fixture)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := marshalVDF(shortcuts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		for i := 0; i < len(got) && i < len(want); i++ {
+			if got[i] != want[i] {
+				t.Fatalf("output differs from %s at byte %d: got 0x%02x, want 0x%02x", fixture, i, got[i], want[i])
+			}
+		}
+		t.Fatalf("output is %d bytes, %s is %d bytes", len(got), fixture, len(want))
+	}
+}
+
+func TestMarshalVDFFraming(t *testing.T) {
+	shortcuts := NewShortcuts()
+	shortcuts.Shortcuts["0"] = Shortcut{AppName: "Game", Exe: "/bin/game", Appid: 0x80000001}
+	got, err := marshalVDF(shortcuts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The document starts with the "shortcuts" map and the first entry
+	prefix := []byte("\x00shortcuts\x00\x00" + "0\x00" + "\x02appid\x00\x01\x00\x00\x80")
+	if !bytes.HasPrefix(got, prefix) {
+		t.Errorf("output starts with %q, want %q", got[:len(prefix)], prefix)
+	}
+
+	// An empty tags map is still written, followed by the end markers of the
+	// tags, the entry, the "shortcuts" map and the document
+	suffix := []byte("\x00tags\x00\x08\x08\x08\x08")
+	if !bytes.HasSuffix(got, suffix) {
+		t.Errorf("output ends with %q, want %q", got[len(got)-len(suffix):], suffix)
+	}
+	if err := checkVDF(got); err != nil {
+		t.Errorf("output is not valid VDF: %v", err)
+	}
+}
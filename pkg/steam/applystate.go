@@ -0,0 +1,82 @@
+package steam
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+
+	"github.com/shadowblip/steam-shortcut-manager/pkg/fsutil"
+)
+
+// ApplyState records which shortcuts of an artwork manifest have been applied,
+// so an interrupted apply can be resumed without starting over.
+type ApplyState struct {
+	path      string
+	Manifest  string          `json:"manifest"`
+	Completed map[uint64]bool `json:"completed"`
+}
+
+// GetApplyStatePath will return the location of the apply state of the given
+// manifest. The state is keyed by a hash of the manifest contents, so editing
+// the manifest starts a fresh apply.
+func GetApplyStatePath(manifest []byte) (string, error) {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(manifest)
+	name := hex.EncodeToString(sum[:]) + ".json"
+	return filepath.Join(cacheDir, "steam-shortcut-manager", "apply-state", name), nil
+}
+
+// LoadApplyState will load the apply state from the given path. If the file
+// does not exist, an empty state is returned.
+func LoadApplyState(path string) (*ApplyState, error) {
+	state := &ApplyState{path: path, Completed: map[uint64]bool{}}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return state, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, state); err != nil {
+		return nil, err
+	}
+	if state.Completed == nil {
+		state.Completed = map[uint64]bool{}
+	}
+
+	return state, nil
+}
+
+// Path will return the file the apply state is stored in
+func (s *ApplyState) Path() string {
+	return s.path
+}
+
+// IsCompleted will return whether or not the artwork of the given app ID was
+// applied
+func (s *ApplyState) IsCompleted(appID uint64) bool {
+	return s.Completed[appID]
+}
+
+// MarkCompleted will record that the artwork of the given app ID was applied
+func (s *ApplyState) MarkCompleted(appID uint64) {
+	s.Completed[appID] = true
+}
+
+// Save will write the apply state to disk
+func (s *ApplyState) Save() error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return fsutil.WriteFileAtomic(s.path, data, 0644)
+}
@@ -0,0 +1,96 @@
+package steam
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+)
+
+// ErrAppNotInstalled is returned when a Steam app is not installed in any
+// library folder.
+var ErrAppNotInstalled = errors.New("app is not installed")
+
+// libraryIconPattern matches the icon file names newer versions of Steam
+// store in the per-app library cache directory.
+var libraryIconPattern = regexp.MustCompile(`^[0-9a-f]{40}\.(jpg|png|ico)$`)
+
+// AppManifest holds the details of an installed Steam app, as read from its
+// appmanifest_<id>.acf file.
+type AppManifest struct {
+	AppID uint64 `json:"appid"`
+	Name  string `json:"name"`
+	// InstallDir is the full path to the directory the app is installed in
+	InstallDir string `json:"install_dir"`
+	// Library is the library folder the app is installed in
+	Library string `json:"library"`
+	// Icon is the path to the app's icon in Steam's library cache, if found
+	Icon string `json:"icon,omitempty"`
+}
+
+// GetInstalledApp will return the manifest of the given installed Steam app.
+// Every library folder is searched. Returns ErrAppNotInstalled if the app is
+// not installed.
+func GetInstalledApp(appID uint64) (*AppManifest, error) {
+	folders, err := GetLibraryFolders()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, folder := range folders {
+		manifestPath := filepath.Join(folder, "steamapps", fmt.Sprintf("appmanifest_%d.acf", appID))
+		if _, err := os.Stat(manifestPath); err != nil {
+			continue
+		}
+		kv, err := LoadKeyValues(manifestPath)
+		if err != nil {
+			return nil, err
+		}
+		state := kv.GetMap("AppState")
+		if state == nil {
+			return nil, fmt.Errorf("unable to parse %s: missing AppState", manifestPath)
+		}
+
+		manifest := &AppManifest{
+			AppID:   appID,
+			Name:    state.GetString("name"),
+			Library: folder,
+			Icon:    findLibraryIcon(appID),
+		}
+		if installDir := state.GetString("installdir"); installDir != "" {
+			manifest.InstallDir = filepath.Join(folder, "steamapps", "common", installDir)
+		}
+		return manifest, nil
+	}
+
+	return nil, fmt.Errorf("%w: %d", ErrAppNotInstalled, appID)
+}
+
+// findLibraryIcon will return the path to the icon Steam cached for the given
+// app. Older versions of Steam store it as <id>_icon.jpg in the library
+// cache, while newer versions use a per-app directory with the icon named
+// after its hash. Returns an empty string if no icon was found.
+func findLibraryIcon(appID uint64) string {
+	steamDir, err := GetBaseDir()
+	if err != nil {
+		return ""
+	}
+	cacheDir := filepath.Join(steamDir, "appcache", "librarycache")
+
+	legacyIcon := filepath.Join(cacheDir, fmt.Sprintf("%d_icon.jpg", appID))
+	if _, err := os.Stat(legacyIcon); err == nil {
+		return legacyIcon
+	}
+
+	entries, err := os.ReadDir(filepath.Join(cacheDir, fmt.Sprintf("%d", appID)))
+	if err != nil {
+		return ""
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() && libraryIconPattern.MatchString(entry.Name()) {
+			return filepath.Join(cacheDir, fmt.Sprintf("%d", appID), entry.Name())
+		}
+	}
+	return ""
+}
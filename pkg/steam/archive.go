@@ -0,0 +1,286 @@
+package steam
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shadowblip/steam-shortcut-manager/pkg/fsutil"
+	"github.com/shadowblip/steam-shortcut-manager/pkg/shortcut"
+)
+
+// ArchiveVersion is the version of the backup archive layout
+const ArchiveVersion = 1
+
+// archiveManifestName is the name of the manifest inside a backup archive
+const archiveManifestName = "manifest.json"
+
+// ArchiveManifest describes the contents of a backup archive
+type ArchiveManifest struct {
+	Version int           `json:"version"`
+	Created time.Time     `json:"created"`
+	Users   []ArchiveUser `json:"users"`
+}
+
+// ArchiveUser lists the shortcuts of one user in a backup archive
+type ArchiveUser struct {
+	ID        string            `json:"id"`
+	Shortcuts []ArchiveShortcut `json:"shortcuts"`
+	Images    int               `json:"images"`
+}
+
+// ArchiveShortcut identifies a shortcut in a backup archive
+type ArchiveShortcut struct {
+	AppID   int64  `json:"appid"`
+	AppName string `json:"name"`
+}
+
+// WriteArchive will write a gzipped tar archive of the shortcuts file and grid
+// folder of each of the given users to w. Files are stored relative to the
+// Steam directory (userdata/<user>/config/...) so the archive can be restored
+// on another machine. A manifest of the archived users and shortcuts is
+// included and returned.
+func WriteArchive(w io.Writer, users []string) (*ArchiveManifest, error) {
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+
+	manifest := &ArchiveManifest{Version: ArchiveVersion, Created: time.Now().UTC(), Users: []ArchiveUser{}}
+	for _, user := range users {
+		archiveUser := ArchiveUser{ID: user, Shortcuts: []ArchiveShortcut{}}
+
+		// Add the shortcuts file
+		shortcutsPath, err := GetShortcutsPath(user)
+		if err != nil {
+			return nil, err
+		}
+		if HasShortcuts(user) {
+			shortcuts, err := shortcut.Load(shortcutsPath)
+			if err != nil {
+				return nil, err
+			}
+			for _, key := range shortcuts.Keys() {
+				sc := shortcuts.Shortcuts[key]
+				archiveUser.Shortcuts = append(archiveUser.Shortcuts, ArchiveShortcut{AppID: sc.Appid, AppName: sc.AppName})
+			}
+			if err := addFileToArchive(tw, shortcutsPath, archiveShortcutsName(user)); err != nil {
+				return nil, err
+			}
+		}
+
+		// Add the grid folder
+		gridDir, err := GetImagesDir(user)
+		if err != nil {
+			return nil, err
+		}
+		err = filepath.Walk(gridDir, func(file string, info os.FileInfo, err error) error {
+			if errors.Is(err, os.ErrNotExist) && file == gridDir {
+				return nil
+			}
+			if err != nil || !info.Mode().IsRegular() {
+				return err
+			}
+			rel, err := filepath.Rel(gridDir, file)
+			if err != nil {
+				return err
+			}
+			archiveUser.Images++
+			return addFileToArchive(tw, file, path.Join(archiveGridDir(user), filepath.ToSlash(rel)))
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		manifest.Users = append(manifest.Users, archiveUser)
+	}
+
+	// Add the manifest
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	header := &tar.Header{Name: archiveManifestName, Mode: 0644, Size: int64(len(data)), ModTime: manifest.Created}
+	if err := tw.WriteHeader(header); err != nil {
+		return nil, err
+	}
+	if _, err := tw.Write(data); err != nil {
+		return nil, err
+	}
+
+	if err := tw.Close(); err != nil {
+		return nil, err
+	}
+	if err := gz.Close(); err != nil {
+		return nil, err
+	}
+
+	return manifest, nil
+}
+
+// ReadArchiveManifest will return the manifest of the given backup archive
+func ReadArchiveManifest(r io.Reader) (*ArchiveManifest, error) {
+	var manifest *ArchiveManifest
+	err := walkArchive(r, func(header *tar.Header, data io.Reader) error {
+		if header.Name != archiveManifestName {
+			return nil
+		}
+		manifest = &ArchiveManifest{}
+		return json.NewDecoder(data).Decode(manifest)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if manifest == nil {
+		return nil, fmt.Errorf("invalid backup archive: missing %s", archiveManifestName)
+	}
+	return manifest, nil
+}
+
+// RestoreArchive will restore the shortcuts files and grid images of the given
+// users from a backup archive written by WriteArchive. If no users are given,
+// every user in the archive is restored. Existing shortcuts files are backed
+// up with shortcut.Backup before they are replaced. Returns the paths of the
+// restored files.
+func RestoreArchive(r io.Reader, users ...string) ([]string, error) {
+	restored := []string{}
+	err := walkArchive(r, func(header *tar.Header, data io.Reader) error {
+		if header.Name == archiveManifestName || header.Typeflag != tar.TypeReg {
+			return nil
+		}
+		user, target, err := archiveTarget(header.Name)
+		if err != nil {
+			return err
+		}
+		if len(users) > 0 && !containsString(users, user) {
+			return nil
+		}
+
+		contents, err := io.ReadAll(data)
+		if err != nil {
+			return err
+		}
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return err
+		}
+		if header.Name == archiveShortcutsName(user) {
+			if _, err := os.Stat(target); err == nil {
+				if _, err := shortcut.Backup(target); err != nil {
+					return err
+				}
+			}
+		}
+		if err := fsutil.WriteFileAtomic(target, contents, 0644); err != nil {
+			return err
+		}
+		restored = append(restored, target)
+		return nil
+	})
+
+	return restored, err
+}
+
+// archiveShortcutsName will return the archive path of a user's shortcuts file
+func archiveShortcutsName(user string) string {
+	return path.Join("userdata", user, "config", "shortcuts.vdf")
+}
+
+// archiveGridDir will return the archive path of a user's grid folder
+func archiveGridDir(user string) string {
+	return path.Join("userdata", user, "config", "grid")
+}
+
+// archiveTarget will return the user and local path of the given archive
+// entry. Only shortcuts files and grid images are accepted, so a crafted
+// archive cannot write anywhere else.
+func archiveTarget(name string) (string, string, error) {
+	parts := strings.Split(path.Clean(name), "/")
+	invalid := fmt.Errorf("invalid backup archive entry: %s", name)
+	if len(parts) < 4 || parts[0] != "userdata" || parts[2] != "config" {
+		return "", "", invalid
+	}
+	user := parts[1]
+	if _, err := strconv.ParseUint(user, 10, 64); err != nil {
+		return "", "", invalid
+	}
+
+	switch {
+	case len(parts) == 4 && parts[3] == "shortcuts.vdf":
+		target, err := GetShortcutsPath(user)
+		return user, target, err
+	case len(parts) > 4 && parts[3] == "grid":
+		for _, part := range parts[4:] {
+			if part == ".." || part == "" {
+				return "", "", invalid
+			}
+		}
+		gridDir, err := GetImagesDir(user)
+		if err != nil {
+			return "", "", err
+		}
+		return user, filepath.Join(append([]string{gridDir}, parts[4:]...)...), nil
+	}
+	return "", "", invalid
+}
+
+// addFileToArchive will add the given file to the archive with the given name
+func addFileToArchive(tw *tar.Writer, file, name string) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	header := &tar.Header{Name: name, Mode: 0644, Size: info.Size(), ModTime: info.ModTime()}
+	if err := tw.WriteHeader(header); err != nil {
+		return err
+	}
+	_, err = io.Copy(tw, f)
+	return err
+}
+
+// walkArchive will call the given function for every entry of the given
+// gzipped tar archive
+func walkArchive(r io.Reader, fn func(header *tar.Header, data io.Reader) error) error {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return fmt.Errorf("invalid backup archive: %w", err)
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("invalid backup archive: %w", err)
+		}
+		if err := fn(header, tr); err != nil {
+			return err
+		}
+	}
+}
+
+// containsString will return whether or not the given list contains the
+// given string
+func containsString(list []string, str string) bool {
+	for _, s := range list {
+		if s == str {
+			return true
+		}
+	}
+	return false
+}
@@ -2,13 +2,28 @@
 package steam
 
 import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"os"
-	"os/exec"
 	"path"
+	"path/filepath"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/websocket"
+	multierror "github.com/hashicorp/go-multierror"
+	"github.com/shadowblip/steam-shortcut-manager/pkg/fsutil"
+	"github.com/shadowblip/steam-shortcut-manager/pkg/httpclient"
+	"github.com/shadowblip/steam-shortcut-manager/pkg/logger"
+	"golang.org/x/sync/errgroup"
 )
 
 // AssetType represents the asset types for Steam's SetCustomArtworkForApp API
@@ -20,316 +35,978 @@ const (
 	AssetTypeLogo          AssetType = 2 // Logo
 	AssetTypeGridLandscape AssetType = 3 // Wide Capsule 920x430 (grid_l)
 	AssetTypeIcon          AssetType = 4 // Icon
+
+	// AssetTypeGridLegacy is the 460x215 grid used by the old Big Picture
+	// mode. It is not part of Steam's API and is only written to the grid
+	// folder.
+	AssetTypeGridLegacy AssetType = 5
 )
 
-// ArtworkConfig holds artwork URLs to apply
+// AllAssetTypes is the list of every supported artwork asset type
+var AllAssetTypes = []AssetType{
+	AssetTypeGridPortrait,
+	AssetTypeHero,
+	AssetTypeLogo,
+	AssetTypeGridLandscape,
+	AssetTypeIcon,
+}
+
+// artworkAssetTypes is every asset type that can be applied or cleared,
+// including the legacy grid
+var artworkAssetTypes = append(append([]AssetType{}, AllAssetTypes...), AssetTypeGridLegacy)
+
+// supportsCEF will return whether or not the given asset type can be set
+// through Steam's CEF API
+func (a AssetType) supportsCEF() bool {
+	return a != AssetTypeIcon && a != AssetTypeGridLegacy
+}
+
+// LegacyGridID will return the 64-bit ID the old Big Picture mode uses to name
+// the legacy grid image of the shortcut with the given app ID
+func LegacyGridID(appID uint64) uint64 {
+	return uint64(uint32(appID))<<32 | 0x02000000
+}
+
+// String will return a human readable name of the asset type
+func (a AssetType) String() string {
+	switch a {
+	case AssetTypeGridPortrait:
+		return "grid portrait"
+	case AssetTypeHero:
+		return "hero"
+	case AssetTypeLogo:
+		return "logo"
+	case AssetTypeGridLandscape:
+		return "grid landscape"
+	case AssetTypeIcon:
+		return "icon"
+	case AssetTypeGridLegacy:
+		return "legacy grid"
+	}
+	return fmt.Sprintf("asset type %d", int(a))
+}
+
+// ParseAssetType will return the asset type with the given name. Names are
+// the same as the flags of the apply commands, e.g. "grid-portrait" or
+// "hero"; underscores may be used instead of dashes.
+func ParseAssetType(name string) (AssetType, error) {
+	switch strings.ReplaceAll(strings.ToLower(name), "_", "-") {
+	case "grid-portrait", "portrait":
+		return AssetTypeGridPortrait, nil
+	case "hero":
+		return AssetTypeHero, nil
+	case "logo":
+		return AssetTypeLogo, nil
+	case "grid-landscape", "landscape":
+		return AssetTypeGridLandscape, nil
+	case "icon":
+		return AssetTypeIcon, nil
+	case "grid-legacy", "legacy":
+		return AssetTypeGridLegacy, nil
+	}
+	return 0, fmt.Errorf("unknown asset type '%s' (valid types: grid-portrait, grid-landscape, hero, logo, icon, grid-legacy)", name)
+}
+
+// ParseArtworkMethod will return the artwork method with the given name:
+// "auto", "cef" or "filesystem"
+func ParseArtworkMethod(name string) (ArtworkMethod, error) {
+	switch strings.ToLower(name) {
+	case "auto", "":
+		return ArtworkMethodAuto, nil
+	case string(ArtworkMethodCEF):
+		return ArtworkMethodCEF, nil
+	case string(ArtworkMethodFilesystem), "fs":
+		return ArtworkMethodFilesystem, nil
+	}
+	return "", fmt.Errorf("unknown artwork method '%s' (valid methods: auto, cef, filesystem)", name)
+}
+
+// AssetError is an error fetching or applying a single artwork asset
+type AssetError struct {
+	AssetType AssetType
+	Err       error
+}
+
+func (e *AssetError) Error() string {
+	return fmt.Sprintf("%s: %v", e.AssetType, e.Err)
+}
+
+func (e *AssetError) Unwrap() error {
+	return e.Err
+}
+
+// DefaultConcurrency is the default number of artwork assets that are
+// downloaded and applied at the same time.
+const DefaultConcurrency = 4
+
+// artworkBaseName returns the grid folder file name (without extension) used
+// for the given asset type.
+func artworkBaseName(appID uint64, assetType AssetType) string {
+	switch assetType {
+	case AssetTypeGridPortrait:
+		return fmt.Sprintf("%dp", appID)
+	case AssetTypeHero:
+		return fmt.Sprintf("%d_hero", appID)
+	case AssetTypeLogo:
+		return fmt.Sprintf("%d_logo", appID)
+	case AssetTypeIcon:
+		return fmt.Sprintf("%d_icon", appID)
+	case AssetTypeGridLegacy:
+		return fmt.Sprintf("%d", LegacyGridID(appID))
+	default:
+		return fmt.Sprintf("%d", appID)
+	}
+}
+
+// ArtworkConfig holds artwork sources to apply. Each source may be an
+// HTTP(S) URL, a file:// URL, or a local file path.
 type ArtworkConfig struct {
 	GridPortrait  string // 600x900 portrait grid
 	GridLandscape string // 920x430 landscape grid
 	HeroImage     string // 1920x620 hero banner
 	LogoImage     string // Logo with transparency
 	IconImage     string // Square icon
+	GridLegacy    string // 460x215 legacy Big Picture grid (optional)
+}
+
+// ArtworkOptions controls how artwork is applied
+type ArtworkOptions struct {
+	// User is the Steam user whose grid folder the artwork is written to. If
+	// empty, the user that owns the shortcut with the app ID is used.
+	User string
+
+	// UpdateShortcutIcon will also point the shortcut's Icon field at the
+	// applied icon image so the small library icon changes too.
+	UpdateShortcutIcon bool
+
+	// StrictDimensions will refuse to apply grid and hero images whose
+	// aspect ratio does not match the asset type instead of only warning.
+	StrictDimensions bool
+
+	// Concurrency is the maximum number of assets applied at the same time.
+	// If zero, DefaultConcurrency is used.
+	Concurrency int
+
+	// Method forces the way artwork is applied. ArtworkMethodAuto, the zero
+	// value, uses Steam's CEF API when available and falls back to the
+	// filesystem. ArtworkMethodCEF never falls back, so animated artwork is
+	// never silently flattened; it fails with ErrCEFUnavailable if the API
+	// cannot be reached. ArtworkMethodFilesystem never uses the CEF API.
+	Method ArtworkMethod
+
+	// Offline only uses local artwork files. URL sources fail with
+	// httpclient.ErrOffline and Steam's CEF API is not used. Setting
+	// httpclient.Offline has the same effect.
+	Offline bool
+
+	// Headers are sent with every artwork image download, for example to
+	// authenticate with a private image server. They are not sent to Steam's
+	// CEF API.
+	Headers http.Header
+
+	// HTTPClient is used to download artwork images instead of the shared
+	// client, for example to use a custom transport.
+	HTTPClient *http.Client
+
+	// OnAsset is called as each asset is processed, for example to show
+	// progress. Calls are never made at the same time, even when assets are
+	// applied concurrently. The error is only set for AssetStatusFailed.
+	OnAsset func(assetType AssetType, status AssetStatus, err error)
 }
 
+// AssetStatus is the progress of a single artwork asset reported to
+// ArtworkOptions.OnAsset
+type AssetStatus string
+
+const (
+	// AssetStatusDownloading means the asset image is being downloaded or read
+	AssetStatusDownloading AssetStatus = "downloading"
+	// AssetStatusAppliedCEF means the asset was set through Steam's CEF API
+	AssetStatusAppliedCEF AssetStatus = "applied-cef"
+	// AssetStatusAppliedFilesystem means the asset was written to the grid
+	// folder
+	AssetStatusAppliedFilesystem AssetStatus = "applied-fs"
+	// AssetStatusFailed means the asset could not be applied
+	AssetStatusFailed AssetStatus = "failed"
+)
+
+// ArtworkMethod is the way an artwork asset is applied
+type ArtworkMethod string
+
+const (
+	// ArtworkMethodAuto means the best available method is used
+	ArtworkMethodAuto ArtworkMethod = ""
+	// ArtworkMethodCEF means the asset was set through Steam's CEF API
+	ArtworkMethodCEF ArtworkMethod = "cef"
+	// ArtworkMethodFilesystem means the asset was written to the grid folder
+	ArtworkMethodFilesystem ArtworkMethod = "filesystem"
+)
+
+// AssetResult is the outcome of applying a single artwork asset. Method is
+// empty if the asset could not be applied.
+type AssetResult struct {
+	AssetType AssetType
+	Method    ArtworkMethod
+	// Path is the grid file that was written when using the filesystem method
+	Path string
+	Err  error
+}
+
+// MarshalJSON will encode the result with the asset type and error as strings
+func (r AssetResult) MarshalJSON() ([]byte, error) {
+	out := struct {
+		AssetType string        `json:"type"`
+		Method    ArtworkMethod `json:"method,omitempty"`
+		Path      string        `json:"path,omitempty"`
+		Error     string        `json:"error,omitempty"`
+	}{
+		AssetType: r.AssetType.String(),
+		Method:    r.Method,
+		Path:      r.Path,
+	}
+	if r.Err != nil {
+		out.Error = r.Err.Error()
+	}
+	return json.Marshal(out)
+}
+
+// ErrCEFUnavailable is returned when artwork must be applied through Steam's
+// CEF API, but the API cannot be reached
+var ErrCEFUnavailable = errors.New("Steam CEF API is unavailable")
+
+// ErrGridNotWritable is returned when artwork cannot be written to the grid
+// folder, for example because it is on a read-only mount
+var ErrGridNotWritable = errors.New("grid folder is not writable")
+
 // SetArtwork applies artwork for a Steam shortcut.
 // Tries Steam's CEF API first (supports animated WebP/GIF), then falls back
 // to the filesystem method if the API is unavailable.
 func SetArtwork(appID uint64, artwork *ArtworkConfig) error {
+	return SetArtworkWithOptions(appID, artwork, nil)
+}
+
+// SetArtworkWithOptions applies artwork for a Steam shortcut using the given
+// options. See SetArtwork.
+func SetArtworkWithOptions(appID uint64, artwork *ArtworkConfig, opts *ArtworkOptions) error {
+	return SetArtworkContext(context.Background(), appID, artwork, opts)
+}
+
+// SetArtworkContext applies artwork for a Steam shortcut like
+// SetArtworkWithOptions. Assets that have not been applied yet are skipped
+// once the given context is cancelled.
+func SetArtworkContext(ctx context.Context, appID uint64, artwork *ArtworkConfig, opts *ArtworkOptions) error {
+	results, err := SetArtworkDetailedContext(ctx, appID, artwork, opts)
+	if err != nil {
+		return err
+	}
+
+	var errs error
+	for _, result := range results {
+		if result.Err != nil {
+			errs = multierror.Append(errs, &AssetError{AssetType: result.AssetType, Err: result.Err})
+		}
+	}
+
+	return errs
+}
+
+// SetArtworkDetailed applies artwork for a Steam shortcut like
+// SetArtworkWithOptions, but returns the result of every asset that was given
+// in a stable order. The returned error is only non-nil if the artwork could
+// not be applied at all.
+func SetArtworkDetailed(appID uint64, artwork *ArtworkConfig, opts *ArtworkOptions) ([]AssetResult, error) {
+	return SetArtworkDetailedContext(context.Background(), appID, artwork, opts)
+}
+
+// SetArtworkDetailedContext is like SetArtworkDetailed, but skips the assets
+// that have not been applied yet once the given context is cancelled. Grid
+// files are written atomically, so a cancelled run never leaves a partially
+// written image behind.
+func SetArtworkDetailedContext(ctx context.Context, appID uint64, artwork *ArtworkConfig, opts *ArtworkOptions) ([]AssetResult, error) {
 	if artwork == nil {
-		return nil
+		return []AssetResult{}, nil
+	}
+	if opts == nil {
+		opts = &ArtworkOptions{}
 	}
 
-	// Check if aiohttp is available for Steam CEF API method
-	canUseSteamAPI := checkAiohttpAvailable()
+	// Check if the Steam CEF debugger is available
+	offline := opts.Offline || httpclient.Offline
+	canUseSteamAPI := false
+	switch opts.Method {
+	case ArtworkMethodAuto:
+		canUseSteamAPI = !offline && checkCEFAvailable()
+	case ArtworkMethodCEF:
+		if offline {
+			return nil, fmt.Errorf("%w: Steam's CEF API cannot be used", httpclient.ErrOffline)
+		}
+		if !checkCEFAvailable() {
+			return nil, fmt.Errorf("%w: start Steam with CEF debugging enabled or use the filesystem method", ErrCEFUnavailable)
+		}
+		canUseSteamAPI = true
+	case ArtworkMethodFilesystem:
+	default:
+		return nil, fmt.Errorf("unknown artwork method: %s", opts.Method)
+	}
+	cefOnly := opts.Method == ArtworkMethodCEF
 
 	// Get grid path for filesystem fallback
-	gridPath, err := getGridPath()
+	gridUser, err := getGridUser(appID, opts.User)
 	if err != nil {
-		return fmt.Errorf("failed to get grid path: %w", err)
+		return nil, fmt.Errorf("failed to get grid path: %w", err)
+	}
+	gridPath, err := GetImagesDir(gridUser)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get grid path: %w", err)
 	}
 
-	// Helper to apply single artwork with fallback
-	applyOne := func(url, baseName string, assetType AssetType) {
-		if url == "" {
+	if !canUseSteamAPI && opts.Method == ArtworkMethodAuto && !offline {
+		logger.Infof("Using filesystem method for artwork (static images only)")
+		logger.Infof("To enable animated WebP/GIF, start Steam with CEF debugging enabled")
+	}
+
+	// Apply all artwork types in parallel
+	sources := map[AssetType]string{
+		AssetTypeGridPortrait:  artwork.GridPortrait,
+		AssetTypeGridLandscape: artwork.GridLandscape,
+		AssetTypeHero:          artwork.HeroImage,
+		AssetTypeLogo:          artwork.LogoImage,
+		AssetTypeIcon:          artwork.IconImage,
+		AssetTypeGridLegacy:    artwork.GridLegacy,
+	}
+
+	// Fail early with a single error if the grid folder cannot be written
+	// to, instead of once for every asset
+	for assetType, url := range sources {
+		if url != "" && !cefOnly && (!canUseSteamAPI || !assetType.supportsCEF()) {
+			if err := CheckGridWritable(gridPath); err != nil {
+				return nil, err
+			}
+			break
+		}
+	}
+
+	// Report progress one call at a time
+	var progressMu sync.Mutex
+	progress := func(assetType AssetType, status AssetStatus, err error) {
+		if opts.OnAsset == nil {
 			return
 		}
+		progressMu.Lock()
+		defer progressMu.Unlock()
+		opts.OnAsset(assetType, status, err)
+	}
 
-		success := false
-		if canUseSteamAPI {
-			if err := SetArtworkViaCEF(appID, url, assetType); err != nil {
-				fmt.Printf("[WARNING] Steam CEF API failed for %s: %v\n", baseName, err)
-			} else {
-				success = true
+	// Helper to apply single artwork with fallback
+	applyOne := func(url string, assetType AssetType) AssetResult {
+		result := AssetResult{AssetType: assetType}
+		baseName := artworkBaseName(appID, assetType)
+
+		// Download or read the image once for both methods
+		if err := ctx.Err(); err != nil {
+			result.Err = err
+			return result
+		}
+		if cefOnly && !assetType.supportsCEF() {
+			result.Err = fmt.Errorf("%s cannot be applied through Steam's CEF API", assetType)
+			return result
+		}
+		data, ext, err := readArtwork(ctx, url, opts)
+		if err != nil {
+			result.Err = err
+			return result
+		}
+
+		// Check that the image fits the asset type
+		if err := CheckArtworkDimensions(data, assetType); err != nil {
+			if opts.StrictDimensions {
+				result.Err = err
+				return result
+			}
+			logger.Warningf("%s: %v", baseName, err)
+		}
+
+		// Animation is lost when using the filesystem method, and Steam's
+		// CEF API silently rejects images that are too large
+		if assetType.supportsCEF() {
+			if info, err := InspectImageData(data); err == nil {
+				if !canUseSteamAPI && info.Animated {
+					logger.Warningf("%s is animated, but Steam's CEF API is unavailable so it will be shown as a static image", baseName)
+				}
+				if info.declaredAnimated && !info.Animated {
+					logger.Warningf("%s is a %s image with only %d frame, so it will not be animated", baseName, info.Format, info.Frames)
+				}
+				if canUseSteamAPI && info.TooLarge() {
+					logger.Warningf("%s is %d bytes, which is over Steam's %d byte limit, so Steam may reject it", baseName, info.Size, MaxArtworkSize)
+				}
 			}
 		}
 
-		if !success {
-			// Filesystem fallback
-			os.MkdirAll(gridPath, 0755)
-			if err := uploadArtworkToGrid(url, gridPath, baseName); err != nil {
-				fmt.Printf("[ERROR] Failed to upload %s: %v\n", baseName, err)
+		// Icon only via filesystem (Steam API icon handling differs)
+		if canUseSteamAPI && assetType.supportsCEF() {
+			err := setArtworkDataViaCEF(appID, data, assetType)
+			if err == nil {
+				result.Method = ArtworkMethodCEF
+				return result
+			}
+			if cefOnly {
+				result.Err = fmt.Errorf("Steam CEF API failed for %s: %w", baseName, err)
+				return result
 			}
+			logger.Warningf("Steam CEF API failed for %s: %v", baseName, err)
+		}
+
+		// Filesystem fallback
+		os.MkdirAll(gridPath, 0755)
+		imagePath, err := writeArtworkToGrid(data, ext, gridPath, baseName)
+		if err != nil {
+			result.Err = fmt.Errorf("failed to upload %s: %w", baseName, err)
+			return result
+		}
+		result.Method = ArtworkMethodFilesystem
+		result.Path = imagePath
+		if assetType == AssetTypeIcon && opts.UpdateShortcutIcon {
+			if err := SetShortcutIcon(gridUser, appID, imagePath); err != nil {
+				result.Err = fmt.Errorf("failed to update shortcut icon: %w", err)
+			}
+		}
+		return result
+	}
+
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = DefaultConcurrency
+	}
+	results := make([]*AssetResult, len(artworkAssetTypes))
+	group := new(errgroup.Group)
+	group.SetLimit(concurrency)
+	for i, assetType := range artworkAssetTypes {
+		i, assetType := i, assetType
+		url := sources[assetType]
+		if url == "" {
+			continue
 		}
+		group.Go(func() error {
+			progress(assetType, AssetStatusDownloading, nil)
+			result := applyOne(url, assetType)
+			switch {
+			case result.Err != nil:
+				progress(assetType, AssetStatusFailed, result.Err)
+			case result.Method == ArtworkMethodCEF:
+				progress(assetType, AssetStatusAppliedCEF, nil)
+			default:
+				progress(assetType, AssetStatusAppliedFilesystem, nil)
+			}
+			results[i] = &result
+			return nil
+		})
 	}
+	group.Wait()
 
-	if !canUseSteamAPI {
-		fmt.Println("[INFO] Using filesystem method for artwork (static images only)")
-		fmt.Println("[INFO] To enable animated WebP/GIF, install: pip install --user aiohttp")
+	// Report the results in a stable order
+	applied := []AssetResult{}
+	for _, result := range results {
+		if result != nil {
+			applied = append(applied, *result)
+		}
 	}
 
-	// Apply all artwork types
-	applyOne(artwork.GridPortrait, fmt.Sprintf("%dp", appID), AssetTypeGridPortrait)
-	applyOne(artwork.GridLandscape, fmt.Sprintf("%d", appID), AssetTypeGridLandscape)
-	applyOne(artwork.HeroImage, fmt.Sprintf("%d_hero", appID), AssetTypeHero)
-	applyOne(artwork.LogoImage, fmt.Sprintf("%d_logo", appID), AssetTypeLogo)
+	return applied, nil
+}
+
+// cefDebuggerURL is the address of Steam's CEF remote debugger. It is a
+// variable so tests can use a fake debugger.
+var cefDebuggerURL = "http://localhost:8080"
 
-	// Icon only via filesystem (Steam API icon handling differs)
-	if artwork.IconImage != "" {
-		os.MkdirAll(gridPath, 0755)
-		if err := uploadArtworkToGrid(artwork.IconImage, gridPath, fmt.Sprintf("%d_icon", appID)); err != nil {
-			fmt.Printf("[ERROR] Failed to upload icon: %v\n", err)
+// cefTab is a single debuggable tab reported by Steam's CEF debugger
+type cefTab struct {
+	Title                string `json:"title"`
+	WebSocketDebuggerURL string `json:"webSocketDebuggerUrl"`
+}
+
+// cefResponse is a Chrome DevTools Protocol response to a Runtime.evaluate call
+type cefResponse struct {
+	ID     int `json:"id"`
+	Result struct {
+		Result struct {
+			Type  string      `json:"type"`
+			Value interface{} `json:"value"`
+		} `json:"result"`
+		ExceptionDetails *struct {
+			Text string `json:"text"`
+		} `json:"exceptionDetails"`
+	} `json:"result"`
+	Error *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// ClearArtwork removes custom artwork for a Steam shortcut. The matching grid
+// files of every extension are deleted and, if Steam's CEF API is available,
+// the custom artwork is also cleared from the running Steam client. If no
+// types are given, all artwork types are cleared. Returns the list of files
+// that were removed.
+func ClearArtwork(appID uint64, types ...AssetType) ([]string, error) {
+	return ClearArtworkForUser("", appID, types...)
+}
+
+// ClearArtworkForUser removes custom artwork for a Steam shortcut from the
+// grid folder of the given user like ClearArtwork. If the user is empty, the
+// user that owns the shortcut is used.
+func ClearArtworkForUser(user string, appID uint64, types ...AssetType) ([]string, error) {
+	if len(types) == 0 {
+		types = artworkAssetTypes
+	}
+
+	gridUser, err := getGridUser(appID, user)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get grid path: %w", err)
+	}
+	gridPath, err := GetImagesDir(gridUser)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get grid path: %w", err)
+	}
+
+	// Clear the artwork in the running Steam client
+	if checkCEFAvailable() {
+		for _, assetType := range types {
+			if !assetType.supportsCEF() {
+				continue
+			}
+			if err := ClearArtworkViaCEF(appID, assetType); err != nil {
+				logger.Warningf("Steam CEF API failed to clear %s: %v", artworkBaseName(appID, assetType), err)
+			}
+		}
+	}
+
+	// Remove the grid files
+	removed := []string{}
+	for _, assetType := range types {
+		matches, err := filepath.Glob(path.Join(gridPath, artworkBaseName(appID, assetType)+".*"))
+		if err != nil {
+			return removed, err
+		}
+		for _, match := range matches {
+			if err := os.Remove(match); err != nil {
+				return removed, fmt.Errorf("failed to remove %s: %w", match, err)
+			}
+			removed = append(removed, match)
+		}
+	}
+
+	return removed, nil
+}
+
+// RenameArtwork moves the grid files of every asset type and extension from
+// the given old app ID to the given new app ID in the grid folder of the
+// given user, so the artwork stays attached to a shortcut whose app ID
+// changed. Asset types that already have artwork under the new app ID are
+// left alone.
+func RenameArtwork(user string, oldAppID, newAppID uint64) error {
+	if oldAppID == newAppID {
+		return nil
+	}
+	gridPath, err := GetImagesDir(user)
+	if err != nil {
+		return fmt.Errorf("failed to get grid path: %w", err)
+	}
+
+	for _, assetType := range artworkAssetTypes {
+		oldBase := artworkBaseName(oldAppID, assetType)
+		newBase := artworkBaseName(newAppID, assetType)
+		matches, err := filepath.Glob(path.Join(gridPath, oldBase+".*"))
+		if err != nil {
+			return err
+		}
+		if len(matches) == 0 {
+			continue
+		}
+		existing, err := filepath.Glob(path.Join(gridPath, newBase+".*"))
+		if err != nil {
+			return err
+		}
+		if len(existing) > 0 {
+			logger.Warningf("Not moving %s artwork, %s already exists", assetType, existing[0])
+			continue
+		}
+		for _, match := range matches {
+			newPath := path.Join(gridPath, newBase+filepath.Ext(match))
+			logger.Debugf("Moving %s to %s", match, newPath)
+			if err := os.Rename(match, newPath); err != nil {
+				return fmt.Errorf("failed to move %s: %w", match, err)
+			}
 		}
 	}
 
 	return nil
 }
 
+// ClearArtworkViaCEF clears custom artwork of the given type using Steam's
+// internal CEF debugger API.
+func ClearArtworkViaCEF(appID uint64, assetType AssetType) error {
+	jsCode := fmt.Sprintf(`
+		(async () => {
+			try {
+				await SteamClient.Apps.ClearCustomArtworkForApp(%d, %d);
+				return "success";
+			} catch (e) {
+				return "error: " + e.message;
+			}
+		})()
+	`, appID, assetType)
+
+	value, err := evaluateCEF(jsCode)
+	if err != nil {
+		return fmt.Errorf("Steam CEF API failed: %w", err)
+	}
+	if strings.Contains(strings.ToLower(value), "error") {
+		return fmt.Errorf("Steam CEF API error: %s", value)
+	}
+
+	return nil
+}
+
 // SetArtworkViaCEF applies artwork using Steam's internal CEF debugger API.
 // This method supports animated WebP/GIF images unlike the filesystem method.
-// Requires aiohttp Python module.
+// Requires Steam to be running with CEF debugging enabled.
 func SetArtworkViaCEF(appID uint64, imageURL string, assetType AssetType) error {
-	// Download the image
-	resp, err := http.Get(imageURL)
+	// Download or read the image
+	data, _, err := readArtwork(context.Background(), imageURL, nil)
 	if err != nil {
-		return fmt.Errorf("failed to download artwork: %w", err)
+		return err
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("failed to download artwork: HTTP %d", resp.StatusCode)
-	}
+	return setArtworkDataViaCEF(appID, data, assetType)
+}
 
-	data, err := io.ReadAll(resp.Body)
+// setArtworkDataViaCEF applies the given image data using Steam's internal CEF
+// debugger API.
+func setArtworkDataViaCEF(appID uint64, data []byte, assetType AssetType) error {
+	// Call SetCustomArtworkForApp in Steam's main JS context
+	imageData := base64.StdEncoding.EncodeToString(data)
+	jsCode := fmt.Sprintf(`
+		(async () => {
+			try {
+				await SteamClient.Apps.SetCustomArtworkForApp(%d, "%s", "png", %d);
+				return "success";
+			} catch (e) {
+				return "error: " + e.message;
+			}
+		})()
+	`, appID, imageData, assetType)
+
+	value, err := evaluateCEF(jsCode)
 	if err != nil {
-		return fmt.Errorf("failed to read artwork data: %w", err)
-	}
-
-	// Write image to temp file
-	imagePath := "/tmp/steam_artwork_temp.bin"
-	if err := os.WriteFile(imagePath, data, 0644); err != nil {
-		return fmt.Errorf("failed to write temp image: %w", err)
-	}
-
-	// Python script that connects to Steam's CEF debugger and calls SetCustomArtworkForApp
-	pythonScript := fmt.Sprintf(`
-import json
-import asyncio
-import base64
-import aiohttp
-
-async def set_artwork():
-    # Read image from temp file
-    with open('%s', 'rb') as f:
-        image_data = base64.b64encode(f.read()).decode('ascii')
-
-    # Get Steam CEF tabs
-    async with aiohttp.ClientSession() as session:
-        async with session.get('http://localhost:8080/json') as resp:
-            tabs = await resp.json()
-
-    # Find SharedJSContext tab (Steam's main JS context)
-    tab = None
-    for t in tabs:
-        title = t.get('title', '')
-        if title in ['SharedJSContext', 'SP', 'Steam']:
-            tab = t
-            break
-
-    if not tab:
-        print('ERROR: Steam SharedJSContext tab not found')
-        return False
-
-    ws_url = tab['webSocketDebuggerUrl']
-
-    # Connect to WebSocket and execute JS
-    async with aiohttp.ClientSession() as session:
-        async with session.ws_connect(ws_url) as ws:
-            js_code = f'''
-                (async () => {{
-                    try {{
-                        await SteamClient.Apps.SetCustomArtworkForApp(%d, "{image_data}", "png", %d);
-                        return "success";
-                    }} catch (e) {{
-                        return "error: " + e.message;
-                    }}
-                }})()
-            '''
-
-            await ws.send_json({
-                "id": 1,
-                "method": "Runtime.evaluate",
-                "params": {
-                    "expression": js_code,
-                    "awaitPromise": True,
-                    "userGesture": True
-                }
-            })
-
-            async for msg in ws:
-                if msg.type == aiohttp.WSMsgType.TEXT:
-                    result = json.loads(msg.data)
-                    if result.get('id') == 1:
-                        res = result.get('result', {})
-                        if 'exceptionDetails' in res:
-                            print('ERROR:', res['exceptionDetails'])
-                        else:
-                            value = res.get('result', {}).get('value', '')
-                            if 'error' in str(value).lower():
-                                print('ERROR:', value)
-                                return False
-                        return True
-    return False
-
-import sys
-success = asyncio.run(set_artwork())
-sys.exit(0 if success else 1)
-`, imagePath, appID, assetType)
-
-	// Write and execute the Python script
-	scriptPath := "/tmp/steam_set_artwork.py"
-	if err := os.WriteFile(scriptPath, []byte(pythonScript), 0755); err != nil {
-		return fmt.Errorf("failed to write Python script: %w", err)
-	}
-
-	cmd := exec.Command("python3", scriptPath)
-	output, err := cmd.CombinedOutput()
-
-	// Clean up temp files
-	os.Remove(scriptPath)
-	os.Remove(imagePath)
+		return fmt.Errorf("Steam CEF API failed: %w", err)
+	}
+	if strings.Contains(strings.ToLower(value), "error") {
+		return fmt.Errorf("Steam CEF API error: %s", value)
+	}
+
+	return nil
+}
 
+// findCEFTab will query the Steam CEF debugger for the SharedJSContext tab,
+// which is Steam's main JS context.
+func findCEFTab() (*cefTab, error) {
+	if httpclient.Offline {
+		return nil, fmt.Errorf("unable to reach Steam CEF debugger: %w", httpclient.ErrOffline)
+	}
+	client := http.Client{Timeout: 5 * time.Second}
+	resp, err := client.Get(cefDebuggerURL + "/json")
 	if err != nil {
-		return fmt.Errorf("Steam CEF API failed: %w (output: %s)", err, string(output))
+		return nil, fmt.Errorf("unable to reach Steam CEF debugger: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var tabs []cefTab
+	if err := json.NewDecoder(resp.Body).Decode(&tabs); err != nil {
+		return nil, fmt.Errorf("unable to decode Steam CEF tabs: %w", err)
 	}
 
-	if strings.Contains(string(output), "ERROR") {
-		return fmt.Errorf("Steam CEF API error: %s", string(output))
+	for _, tab := range tabs {
+		switch tab.Title {
+		case "SharedJSContext", "SP", "Steam":
+			return &tab, nil
+		}
 	}
 
-	return nil
+	return nil, fmt.Errorf("Steam SharedJSContext tab not found")
 }
 
-func checkAiohttpAvailable() bool {
-	// First check if python3 is available
-	cmd := exec.Command("python3", "--version")
-	if err := cmd.Run(); err != nil {
-		fmt.Println("[WARNING] python3 not found, cannot use Steam CEF API")
-		return false
+// cefRequestID is the ID of the last Chrome DevTools Protocol request. Every
+// request gets its own ID, so concurrent evaluations never mistake each
+// other's responses.
+var cefRequestID uint64
+
+// cefTabCache holds the last discovered SharedJSContext tab, so concurrent
+// evaluations share a single discovery of the CEF debugger endpoint
+var cefTabCache struct {
+	sync.Mutex
+	tab *cefTab
+}
+
+// getCEFTab will return the cached SharedJSContext tab, discovering it if
+// needed
+func getCEFTab() (*cefTab, error) {
+	cefTabCache.Lock()
+	defer cefTabCache.Unlock()
+	if cefTabCache.tab != nil {
+		return cefTabCache.tab, nil
 	}
+	tab, err := findCEFTab()
+	if err != nil {
+		return nil, err
+	}
+	cefTabCache.tab = tab
+	return tab, nil
+}
+
+// refreshCEFTab will discover the SharedJSContext tab again, replacing the
+// cached one. The cache is cleared if the tab cannot be found.
+func refreshCEFTab() (*cefTab, error) {
+	cefTabCache.Lock()
+	defer cefTabCache.Unlock()
+	tab, err := findCEFTab()
+	cefTabCache.tab = tab
+	return tab, err
+}
 
-	// Check if aiohttp is already installed
-	cmd = exec.Command("python3", "-c", "import aiohttp")
-	output, err := cmd.CombinedOutput()
-	if err == nil && !strings.Contains(string(output), "ModuleNotFoundError") && !strings.Contains(string(output), "No module") {
-		return true
+// evaluateCEF will evaluate the given JavaScript expression in Steam's main JS
+// context using the Chrome DevTools Protocol. Returns the string value that
+// the expression resolved to. It is safe to call concurrently; every call uses
+// its own connection and request ID.
+func evaluateCEF(expression string) (string, error) {
+	tab, err := getCEFTab()
+	if err != nil {
+		return "", err
 	}
 
-	// aiohttp not found, try to install it
-	fmt.Println("[INFO] aiohttp not found, attempting to install...")
+	// The cached tab goes stale when Steam restarts, so discover it again if
+	// it cannot be reached
+	conn, _, err := websocket.DefaultDialer.Dial(tab.WebSocketDebuggerURL, nil)
+	if err != nil {
+		tab, err = refreshCEFTab()
+		if err != nil {
+			return "", err
+		}
+		conn, _, err = websocket.DefaultDialer.Dial(tab.WebSocketDebuggerURL, nil)
+	}
+	if err != nil {
+		return "", fmt.Errorf("unable to connect to Steam CEF debugger: %w", err)
+	}
+	defer conn.Close()
 
-	// Check if pip is available, try different methods
-	pipCmd := findPipCommand()
-	if pipCmd == "" {
-		fmt.Println("[WARNING] pip not found, cannot install aiohttp")
-		return false
+	// Send the evaluate request
+	requestID := int(atomic.AddUint64(&cefRequestID, 1))
+	request := map[string]interface{}{
+		"id":     requestID,
+		"method": "Runtime.evaluate",
+		"params": map[string]interface{}{
+			"expression":   expression,
+			"awaitPromise": true,
+			"userGesture":  true,
+		},
+	}
+	if err := conn.WriteJSON(request); err != nil {
+		return "", fmt.Errorf("unable to send CEF request: %w", err)
 	}
 
-	// Install aiohttp
-	var installCmd *exec.Cmd
-	if pipCmd == "python3 -m pip" {
-		installCmd = exec.Command("python3", "-m", "pip", "install", "--user", "aiohttp")
-	} else {
-		installCmd = exec.Command(pipCmd, "install", "--user", "aiohttp")
+	// Wait for the response to our request, ignoring any events
+	conn.SetReadDeadline(time.Now().Add(30 * time.Second))
+	for {
+		var response cefResponse
+		if err := conn.ReadJSON(&response); err != nil {
+			return "", fmt.Errorf("unable to read CEF response: %w", err)
+		}
+		if response.ID != requestID {
+			continue
+		}
+		if response.Error != nil {
+			return "", fmt.Errorf("CEF error %d: %s", response.Error.Code, response.Error.Message)
+		}
+		if response.Result.ExceptionDetails != nil {
+			return "", fmt.Errorf("JavaScript exception: %s", response.Result.ExceptionDetails.Text)
+		}
+		return fmt.Sprintf("%v", response.Result.Result.Value), nil
 	}
+}
+
+// checkCEFAvailable will return whether or not Steam's CEF debugger can be
+// reached. The debugger endpoint is discovered again, so a restarted Steam is
+// picked up.
+func checkCEFAvailable() bool {
+	_, err := refreshCEFTab()
+	return err == nil
+}
 
-	installOutput, installErr := installCmd.CombinedOutput()
-	if installErr != nil {
-		fmt.Printf("[WARNING] Failed to install aiohttp: %v\n", installErr)
-		fmt.Printf("[WARNING] Output: %s\n", string(installOutput))
-		return false
+// getGridUser returns the Steam user whose grid folder the artwork of the
+// given app ID belongs in. The given user is used if set. Otherwise the user
+// that owns the shortcut is used, preferring the user that most recently
+// logged into Steam if more than one does. If nobody owns the shortcut, the
+// most recent user is used, then the first user.
+func getGridUser(appID uint64, user string) (string, error) {
+	users, err := GetUsers()
+	if err != nil {
+		return "", err
+	}
+	if len(users) == 0 {
+		return "", ErrNoUsers
+	}
+	if user != "" {
+		for _, existing := range users {
+			if existing == user {
+				return user, nil
+			}
+		}
+		return "", fmt.Errorf("Steam user %s not found", user)
 	}
-	fmt.Println("[INFO] aiohttp installed successfully")
 
-	// Verify installation
-	cmd = exec.Command("python3", "-c", "import aiohttp")
-	output, err = cmd.CombinedOutput()
+	recent, _ := GetMostRecentUser()
+	owners, err := GetShortcutOwners(appID)
 	if err != nil {
-		return false
+		logger.Debugf("Unable to find the owner of shortcut %d: %v", appID, err)
 	}
-	return !strings.Contains(string(output), "ModuleNotFoundError") && !strings.Contains(string(output), "No module")
+	if len(owners) > 0 {
+		for _, owner := range owners {
+			if owner == recent {
+				return owner, nil
+			}
+		}
+		if len(owners) > 1 {
+			logger.Warningf("Shortcut %d belongs to users %s, using the grid folder of user %s", appID, strings.Join(owners, ", "), owners[0])
+		}
+		return owners[0], nil
+	}
+	for _, existing := range users {
+		if existing == recent {
+			return recent, nil
+		}
+	}
+	return users[0], nil
 }
 
-func findPipCommand() string {
-	// Try pip3 first
-	cmd := exec.Command("pip3", "--version")
-	if output, err := cmd.CombinedOutput(); err == nil && !strings.Contains(string(output), "not found") {
-		fmt.Println("[INFO] Using pip3")
-		return "pip3"
+// CopyArtworkToGrid reads the given artwork source and saves it to the grid
+// folder of the given user as the given asset type. Returns the path the image
+// was written to.
+func CopyArtworkToGrid(user string, appID uint64, assetType AssetType, source string) (string, error) {
+	gridPath, err := GetImagesDir(user)
+	if err != nil {
+		return "", fmt.Errorf("failed to get grid path: %w", err)
 	}
+	if err := os.MkdirAll(gridPath, 0755); err != nil {
+		return "", err
+	}
+	return uploadArtworkToGrid(source, gridPath, artworkBaseName(appID, assetType))
+}
 
-	// Try pip
-	cmd = exec.Command("pip", "--version")
-	if output, err := cmd.CombinedOutput(); err == nil && !strings.Contains(string(output), "not found") {
-		fmt.Println("[INFO] Using pip")
-		return "pip"
+// uploadArtworkToGrid downloads or reads an image and saves it to the Steam
+// grid folder. Returns the path the image was written to.
+func uploadArtworkToGrid(url, gridPath, baseName string) (string, error) {
+	data, ext, err := readArtwork(context.Background(), url, nil)
+	if err != nil {
+		return "", err
 	}
 
-	// Try python3 -m pip
-	cmd = exec.Command("python3", "-m", "pip", "--version")
-	if output, err := cmd.CombinedOutput(); err == nil && !strings.Contains(string(output), "No module") {
-		fmt.Println("[INFO] Using python3 -m pip")
-		return "python3 -m pip"
+	return writeArtworkToGrid(data, ext, gridPath, baseName)
+}
+
+// writeArtworkToGrid saves the given image data to the Steam grid folder.
+// Returns the path the image was written to.
+func writeArtworkToGrid(data []byte, ext, gridPath, baseName string) (string, error) {
+	// Save to grid folder
+	destPath := path.Join(gridPath, baseName+ext)
+	if err := fsutil.WriteFileAtomic(destPath, data, 0644); err != nil {
+		return "", err
 	}
+	return destPath, nil
+}
 
-	return ""
+// CheckGridWritable will verify that artwork can be written to the given grid
+// folder by creating and removing a probe file in it. The folder is created
+// if it does not exist. Returns ErrGridNotWritable if it cannot be written.
+func CheckGridWritable(gridPath string) error {
+	if err := os.MkdirAll(gridPath, 0755); err != nil {
+		return fmt.Errorf("%w: %v: %v", ErrGridNotWritable, gridPath, err)
+	}
+	probe, err := os.CreateTemp(gridPath, ".write-probe-*")
+	if err != nil {
+		return fmt.Errorf("%w: %v: %v", ErrGridNotWritable, gridPath, err)
+	}
+	probe.Close()
+	return os.Remove(probe.Name())
 }
 
-func getGridPath() (string, error) {
-	users, err := GetUsers()
-	if err != nil || len(users) == 0 {
-		return "", fmt.Errorf("no Steam users found")
+// readArtwork returns the image data and file extension for the given artwork
+// source. The source may be an HTTP(S) URL, a file:// URL, or a local path.
+// Downloads use the headers and HTTP client of the given options, which may
+// be nil.
+func readArtwork(ctx context.Context, source string, opts *ArtworkOptions) ([]byte, string, error) {
+	lower := strings.ToLower(source)
+	if strings.HasPrefix(lower, "http://") || strings.HasPrefix(lower, "https://") {
+		if httpclient.Offline || (opts != nil && opts.Offline) {
+			return nil, "", fmt.Errorf("failed to download artwork: %w: %s", httpclient.ErrOffline, source)
+		}
+		return cachedDownloadArtwork(ctx, source, opts)
 	}
-	userDir, err := GetUserDir()
+
+	// Local file
+	filePath := source
+	if strings.HasPrefix(lower, "file://") {
+		u, err := url.Parse(source)
+		if err != nil {
+			return nil, "", fmt.Errorf("invalid artwork path: %w", err)
+		}
+		filePath = u.Path
+	}
+	data, err := os.ReadFile(filePath)
 	if err != nil {
-		return "", err
+		return nil, "", fmt.Errorf("failed to read artwork file: %w", err)
 	}
-	return path.Join(userDir, users[0], "config", "grid"), nil
+
+	return data, getExtensionFromURL(filePath), nil
 }
 
-// uploadArtworkToGrid downloads an image and saves it to the Steam grid folder
-func uploadArtworkToGrid(url, gridPath, baseName string) error {
-	// Download the image
-	resp, err := http.Get(url)
+// downloadArtwork downloads the given image URL and returns the image data
+// and file extension.
+func downloadArtwork(ctx context.Context, url string, opts *ArtworkOptions) ([]byte, string, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to download artwork: %w", err)
+	}
+	resp, err := doArtworkRequest(req, opts)
 	if err != nil {
-		return fmt.Errorf("failed to download artwork: %w", err)
+		return nil, "", fmt.Errorf("failed to download artwork: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("failed to download artwork: HTTP %d", resp.StatusCode)
+		return nil, "", fmt.Errorf("failed to download artwork: HTTP %d", resp.StatusCode)
 	}
 
 	data, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return fmt.Errorf("failed to read artwork data: %w", err)
+		return nil, "", fmt.Errorf("failed to read artwork data: %w", err)
 	}
 
 	// Determine extension from content type or URL
-	ext := getExtensionFromResponse(resp, url)
+	return data, getExtensionFromResponse(resp, url), nil
+}
 
-	// Save to grid folder
-	destPath := path.Join(gridPath, baseName+ext)
-	return os.WriteFile(destPath, data, 0644)
+// doArtworkRequest will send the given artwork download request with the
+// headers and HTTP client of the given options, which may be nil
+func doArtworkRequest(req *http.Request, opts *ArtworkOptions) (*http.Response, error) {
+	if opts == nil {
+		return httpclient.Do(req)
+	}
+	for key, values := range opts.Headers {
+		req.Header[http.CanonicalHeaderKey(key)] = values
+	}
+	if opts.HTTPClient != nil {
+		return httpclient.DoWithClient(opts.HTTPClient, req)
+	}
+	return httpclient.Do(req)
 }
 
 // getExtensionFromResponse determines file extension from HTTP response or URL
@@ -348,6 +1025,11 @@ func getExtensionFromResponse(resp *http.Response, url string) string {
 	}
 
 	// Fallback to URL extension
+	return getExtensionFromURL(url)
+}
+
+// getExtensionFromURL determines the file extension from a URL or file path
+func getExtensionFromURL(url string) string {
 	urlPath := url
 	if idx := strings.Index(url, "?"); idx != -1 {
 		urlPath = url[:idx]
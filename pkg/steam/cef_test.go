@@ -0,0 +1,156 @@
+package steam
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// fakeCEF is a fake Steam CEF debugger that answers Runtime.evaluate calls
+type fakeCEF struct {
+	discoveries int32
+
+	mu         sync.Mutex
+	requestIDs map[int]bool
+	appIDs     map[string]bool
+	duplicates []int
+}
+
+// cefAppIDPattern matches the app ID passed to SetCustomArtworkForApp
+var cefAppIDPattern = regexp.MustCompile(`SetCustomArtworkForApp\((\d+),`)
+
+// newFakeCEF will start a fake CEF debugger and use it for the rest of the
+// test. Every evaluation is held until the given number of requests have
+// arrived, so they all run at the same time.
+func newFakeCEF(t *testing.T, concurrent int) *fakeCEF {
+	t.Helper()
+	fake := &fakeCEF{requestIDs: map[int]bool{}, appIDs: map[string]bool{}}
+	arrived := int32(0)
+	ready := make(chan struct{})
+	upgrader := websocket.Upgrader{}
+
+	mux := http.NewServeMux()
+	var server *httptest.Server
+	mux.HandleFunc("/json", func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&fake.discoveries, 1)
+		wsURL := "ws" + strings.TrimPrefix(server.URL, "http") + "/devtools/page/1"
+		json.NewEncoder(w).Encode([]cefTab{
+			{Title: "Other", WebSocketDebuggerURL: "ws://127.0.0.1:1/invalid"},
+			{Title: "SharedJSContext", WebSocketDebuggerURL: wsURL},
+		})
+	})
+	mux.HandleFunc("/devtools/page/1", func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			t.Errorf("websocket upgrade failed: %v", err)
+			return
+		}
+		defer conn.Close()
+
+		var request struct {
+			ID     int `json:"id"`
+			Params struct {
+				Expression string `json:"expression"`
+			} `json:"params"`
+		}
+		if err := conn.ReadJSON(&request); err != nil {
+			t.Errorf("unable to read CDP request: %v", err)
+			return
+		}
+		match := cefAppIDPattern.FindStringSubmatch(request.Params.Expression)
+		if match == nil {
+			t.Errorf("unexpected expression: %.80s", request.Params.Expression)
+			return
+		}
+		fake.mu.Lock()
+		if fake.requestIDs[request.ID] {
+			fake.duplicates = append(fake.duplicates, request.ID)
+		}
+		fake.requestIDs[request.ID] = true
+		fake.appIDs[match[1]] = true
+		fake.mu.Unlock()
+
+		// Wait for every apply to be in flight
+		if atomic.AddInt32(&arrived, 1) == int32(concurrent) {
+			close(ready)
+		}
+		select {
+		case <-ready:
+		case <-time.After(5 * time.Second):
+			t.Errorf("only %d of %d applies ran concurrently", atomic.LoadInt32(&arrived), concurrent)
+		}
+
+		// Send an event and a response to another request first, which must
+		// both be ignored
+		conn.WriteJSON(map[string]interface{}{"method": "Runtime.consoleAPICalled"})
+		conn.WriteJSON(map[string]interface{}{
+			"id":     request.ID + 1000,
+			"result": map[string]interface{}{"result": map[string]interface{}{"type": "string", "value": "error: wrong request"}},
+		})
+		conn.WriteJSON(map[string]interface{}{
+			"id":     request.ID,
+			"result": map[string]interface{}{"result": map[string]interface{}{"type": "string", "value": "success"}},
+		})
+	})
+	server = httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	debuggerURL := cefDebuggerURL
+	cefDebuggerURL = server.URL
+	cefTabCache.tab = nil
+	t.Cleanup(func() {
+		cefDebuggerURL = debuggerURL
+		cefTabCache.tab = nil
+	})
+	return fake
+}
+
+func TestSetArtworkViaCEFConcurrent(t *testing.T) {
+	const applies = 10
+	fake := newFakeCEF(t, applies)
+	dir := t.TempDir()
+
+	errs := make([]error, applies)
+	var wg sync.WaitGroup
+	for i := 0; i < applies; i++ {
+		i := i
+		source := filepath.Join(dir, fmt.Sprintf("%d.png", i))
+		writeTestFile(t, source, testPNG(t, i+1, i+1))
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = SetArtworkViaCEF(uint64(3000000000+i), source, AssetTypeHero)
+		}()
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			t.Errorf("apply %d failed: %v", i, err)
+		}
+	}
+	if len(fake.duplicates) > 0 {
+		t.Errorf("CDP request IDs were reused: %v", fake.duplicates)
+	}
+	if len(fake.requestIDs) != applies {
+		t.Errorf("got %d distinct CDP request IDs, want %d", len(fake.requestIDs), applies)
+	}
+	for i := 0; i < applies; i++ {
+		if appID := fmt.Sprintf("%d", 3000000000+i); !fake.appIDs[appID] {
+			t.Errorf("artwork for app %v was never applied", appID)
+		}
+	}
+	if discoveries := atomic.LoadInt32(&fake.discoveries); discoveries != 1 {
+		t.Errorf("CEF endpoint was discovered %d times, want 1", discoveries)
+	}
+}
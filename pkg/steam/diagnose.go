@@ -0,0 +1,116 @@
+package steam
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// CandidateDir is a directory Steam may be installed to
+type CandidateDir struct {
+	Path        string `json:"path"`
+	Source      string `json:"source"`
+	Exists      bool   `json:"exists"`
+	HasUserData bool   `json:"has_userdata"`
+}
+
+// Diagnostics describes how the Steam installation was found, to help figure
+// out why no Steam users can be found
+type Diagnostics struct {
+	BaseDir            string         `json:"base_dir"`
+	BaseDirError       string         `json:"base_dir_error,omitempty"`
+	Override           bool           `json:"override"`
+	UserDataDir        string         `json:"userdata_dir"`
+	UserDataExists     bool           `json:"userdata_exists"`
+	Users              []string       `json:"users"`
+	UsersWithShortcuts []string       `json:"users_with_shortcuts"`
+	Candidates         []CandidateDir `json:"candidates"`
+	Problems           []string       `json:"problems"`
+}
+
+// Diagnose will report the detected Steam directory, the other directories
+// Steam is commonly installed to, and the users that were found. Any problems
+// that would stop commands from finding Steam users are described in
+// Diagnostics.Problems along with how to fix them.
+func Diagnose() (*Diagnostics, error) {
+	diag := &Diagnostics{
+		Override:           baseDirOverride != "",
+		Users:              []string{},
+		UsersWithShortcuts: []string{},
+		Candidates:         []CandidateDir{},
+		Problems:           []string{},
+	}
+
+	// Check the other places Steam may be installed to
+	for _, candidate := range candidateBaseDirs() {
+		if info, err := os.Stat(candidate.Path); err == nil && info.IsDir() {
+			candidate.Exists = true
+		}
+		if info, err := os.Stat(filepath.Join(candidate.Path, "userdata")); err == nil && info.IsDir() {
+			candidate.HasUserData = true
+		}
+		diag.Candidates = append(diag.Candidates, candidate)
+	}
+
+	// Check the Steam directory that is used
+	baseDir, err := GetBaseDir()
+	if err != nil {
+		diag.BaseDirError = err.Error()
+		diag.Problems = append(diag.Problems, "unable to detect the Steam directory: "+err.Error())
+		diag.Problems = append(diag.Problems, suggestCandidate(diag.Candidates, "")...)
+		return diag, nil
+	}
+	diag.BaseDir = baseDir
+	diag.UserDataDir = filepath.Join(baseDir, "userdata")
+	if info, err := os.Stat(diag.UserDataDir); err == nil && info.IsDir() {
+		diag.UserDataExists = true
+	} else {
+		diag.Problems = append(diag.Problems, fmt.Sprintf("%s does not exist, so Steam is not installed there or has never been run", diag.UserDataDir))
+		diag.Problems = append(diag.Problems, suggestCandidate(diag.Candidates, baseDir)...)
+		return diag, nil
+	}
+
+	// Check the users
+	users, err := GetUsers()
+	if err != nil {
+		return nil, err
+	}
+	diag.Users = users
+	if len(users) == 0 {
+		diag.Problems = append(diag.Problems, fmt.Sprintf("%s has no users, log into Steam at least once", diag.UserDataDir))
+		diag.Problems = append(diag.Problems, suggestCandidate(diag.Candidates, baseDir)...)
+	}
+	for _, user := range users {
+		if HasShortcuts(user) {
+			diag.UsersWithShortcuts = append(diag.UsersWithShortcuts, user)
+		}
+	}
+
+	return diag, nil
+}
+
+// suggestCandidate will suggest using the first candidate directory with a
+// userdata folder, other than the given one
+func suggestCandidate(candidates []CandidateDir, current string) []string {
+	for _, candidate := range candidates {
+		if !candidate.HasUserData || sameDir(candidate.Path, current) {
+			continue
+		}
+		return []string{fmt.Sprintf("found a %s Steam directory at %s, use it with --steam-root %s or $%s", candidate.Source, candidate.Path, candidate.Path, SteamRootEnv)}
+	}
+	return []string{}
+}
+
+// sameDir will return whether or not the given paths are the same directory,
+// following symlinks such as ~/.steam/root
+func sameDir(a, b string) bool {
+	infoA, err := os.Stat(a)
+	if err != nil {
+		return false
+	}
+	infoB, err := os.Stat(b)
+	if err != nil {
+		return false
+	}
+	return os.SameFile(infoA, infoB)
+}
@@ -0,0 +1,58 @@
+package steam
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"math"
+
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+
+	_ "golang.org/x/image/webp"
+)
+
+// ErrArtworkDimensions indicates that an artwork image does not have the
+// aspect ratio Steam expects for its asset type.
+var ErrArtworkDimensions = errors.New("artwork dimensions do not match asset type")
+
+// artworkDimensions is the size Steam expects for each asset type that has a
+// fixed aspect ratio. Logos and icons may be any size.
+var artworkDimensions = map[AssetType]image.Point{
+	AssetTypeGridPortrait:  {X: 600, Y: 900},
+	AssetTypeGridLandscape: {X: 920, Y: 430},
+	AssetTypeHero:          {X: 1920, Y: 620},
+	AssetTypeGridLegacy:    {X: 460, Y: 215},
+}
+
+// dimensionTolerance is how far an image's aspect ratio may differ from the
+// expected one, as a fraction of the expected ratio.
+const dimensionTolerance = 0.05
+
+// CheckArtworkDimensions will decode the header of the given image data and
+// check that its aspect ratio roughly matches what Steam expects for the
+// given asset type. PNG, JPEG, GIF and WebP images are supported.
+func CheckArtworkDimensions(data []byte, assetType AssetType) error {
+	want, ok := artworkDimensions[assetType]
+	if !ok {
+		return nil
+	}
+
+	config, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrImageCorrupt, err)
+	}
+	if config.Width == 0 || config.Height == 0 {
+		return fmt.Errorf("%w: %s image has no dimensions", ErrImageCorrupt, format)
+	}
+
+	wantRatio := float64(want.X) / float64(want.Y)
+	gotRatio := float64(config.Width) / float64(config.Height)
+	if math.Abs(gotRatio-wantRatio)/wantRatio > dimensionTolerance {
+		return fmt.Errorf("%w: got %dx%d, expected %dx%d", ErrArtworkDimensions, config.Width, config.Height, want.X, want.Y)
+	}
+
+	return nil
+}
@@ -3,13 +3,29 @@ package steam
 import (
 	"errors"
 	"fmt"
+	"image"
+	"io"
 	"os"
 	"path"
+	"strconv"
+	"strings"
+
+	"github.com/shadowblip/steam-shortcut-manager/pkg/shortcut"
+
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+
+	_ "golang.org/x/image/webp"
 )
 
 // ErrImageNotFound indicates that a grid images does not exist.
 var ErrImageNotFound = errors.New("image not found")
 
+// ErrImageCorrupt indicates that a grid image exists but is empty or cannot
+// be decoded.
+var ErrImageCorrupt = errors.New("image is corrupt")
+
 // GetImagesDir will return the steam images directory
 func GetImagesDir(user string) (string, error) {
 	userDir, err := GetUserDir()
@@ -19,60 +35,175 @@ func GetImagesDir(user string) (string, error) {
 	return path.Join(userDir, user, "config", "grid"), nil
 }
 
-// GetImageLandscape will return the landscape grid image
+// GetImageLandscape will return the landscape grid image. If there is none,
+// the legacy Big Picture grid image is returned instead.
 func GetImageLandscape(user, appId string) (string, error) {
-	imagesDir, err := GetImagesDir(user)
-	if err != nil {
-		return "", err
+	imgPath, err := findGridImage(user, appId, "")
+	if errors.Is(err, ErrImageNotFound) {
+		if legacyPath, legacyErr := GetImageGridLegacy(user, appId); !errors.Is(legacyErr, ErrImageNotFound) {
+			return legacyPath, legacyErr
+		}
 	}
+	return imgPath, err
+}
 
-	// Check to see if the file exists with different extensions
-	return checkForImage(path.Join(imagesDir, appId))
+// GetImageGridLegacy will return the legacy Big Picture grid image
+func GetImageGridLegacy(user, appId string) (string, error) {
+	id, ok := shortcut.ParseAppID(appId)
+	if !ok || id == 0 {
+		return "", fmt.Errorf("invalid app id %v", appId)
+	}
+	return findGridImage(user, fmt.Sprintf("%d", LegacyGridID(uint64(id))), "")
 }
 
 // GetImagePortrait will return the portrait grid image
 func GetImagePortrait(user, appId string) (string, error) {
-	imagesDir, err := GetImagesDir(user)
-	if err != nil {
-		return "", err
-	}
-
-	// Check to see if the file exists with different extensions
-	return checkForImage(path.Join(imagesDir, fmt.Sprintf("%sp", appId)))
+	return findGridImage(user, appId, "p")
 }
 
 // GetImageHero will return the hero grid image
 func GetImageHero(user, appId string) (string, error) {
+	return findGridImage(user, appId, "_hero")
+}
+
+// GetImageLogo will return the logo grid image
+func GetImageLogo(user, appId string) (string, error) {
+	return findGridImage(user, appId, "_logo")
+}
+
+// GetImageIcon will return the icon image stored in the grid folder. Icons
+// downloaded by older versions used a "-icon" suffix, which is also checked.
+func GetImageIcon(user, appId string) (string, error) {
+	imgPath, err := findGridImage(user, appId, "_icon")
+	if errors.Is(err, ErrImageNotFound) {
+		return findGridImage(user, appId, "-icon")
+	}
+	return imgPath, err
+}
+
+// findGridImage will return the grid image of the given app ID with the given
+// name suffix. The app ID may be signed, or a 64-bit legacy grid ID, and is
+// looked up by the name Steam gives the file. The image that is found is
+// checked to belong to the app ID, so an invalid ID such as an empty one
+// never matches the artwork of another app.
+func findGridImage(user, appId, suffix string) (string, error) {
 	imagesDir, err := GetImagesDir(user)
 	if err != nil {
 		return "", err
 	}
+	id, ok := parseGridID(appId)
+	if !ok || id == 0 {
+		return "", fmt.Errorf("invalid app id %v", appId)
+	}
 
 	// Check to see if the file exists with different extensions
-	return checkForImage(path.Join(imagesDir, fmt.Sprintf("%s_hero", appId)))
+	imgPath, err := checkForImage(path.Join(imagesDir, fmt.Sprintf("%d%s", id, suffix)))
+	if imgPath == "" {
+		return imgPath, err
+	}
+	if owner, ok := artworkFileAppID(path.Base(imgPath)); !ok || owner != shortcutIDOfGridID(id) {
+		return "", fmt.Errorf("%s does not belong to app id %v", imgPath, appId)
+	}
+	return imgPath, err
 }
 
-// GetImageLogo will return the logo grid image
-func GetImageLogo(user, appId string) (string, error) {
-	imagesDir, err := GetImagesDir(user)
+// parseGridID will parse the given app ID or 64-bit legacy grid ID
+func parseGridID(appId string) (uint64, bool) {
+	if id, ok := shortcut.ParseAppID(appId); ok {
+		return uint64(id), true
+	}
+	id, err := strconv.ParseUint(strings.TrimSpace(appId), 10, 64)
+	return id, err == nil && id == LegacyGridID(id>>32)
+}
+
+// shortcutIDOfGridID will return the app ID the given grid ID belongs to.
+// Legacy grid IDs are made from the app ID in their upper 32 bits.
+func shortcutIDOfGridID(id uint64) uint64 {
+	if id > 0xffffffff {
+		return id >> 32
+	}
+	return id
+}
+
+// ResolveImages will return the paths to the grid images of the given app,
+// along with the path to its icon image. Images that exist but are corrupt are
+// still returned and are listed in Images.Corrupt.
+func ResolveImages(user, appId string) (*shortcut.Images, string, error) {
+	if _, err := GetImagesDir(user); err != nil {
+		return nil, "", err
+	}
+
+	images := &shortcut.Images{}
+	lookup := func(getImage func(user, appId string) (string, error)) string {
+		imgPath, err := getImage(user, appId)
+		if errors.Is(err, ErrImageCorrupt) {
+			images.Corrupt = append(images.Corrupt, imgPath)
+		}
+		return imgPath
+	}
+	images.Portrait = lookup(GetImagePortrait)
+	images.Landscape = lookup(GetImageLandscape)
+	images.Hero = lookup(GetImageHero)
+	images.Logo = lookup(GetImageLogo)
+	images.Icon = lookup(GetImageIcon)
+
+	return images, images.Icon, nil
+}
+
+// ValidateImage will check that the given image file is non-empty and, for
+// formats we know how to decode, that it decodes successfully. Returns an
+// ErrImageCorrupt error if the image is unusable.
+func ValidateImage(fileName string) error {
+	info, err := os.Stat(fileName)
 	if err != nil {
-		return "", err
+		return err
+	}
+	if info.Size() == 0 {
+		return fmt.Errorf("%s: %w: file is empty", fileName, ErrImageCorrupt)
 	}
 
-	// Check to see if the file exists with different extensions
-	return checkForImage(path.Join(imagesDir, fmt.Sprintf("%s_logo", appId)))
+	// Only decode the formats that have a registered decoder. Animated WebP
+	// images cannot be fully decoded, so only their header is checked.
+	decode := func(r io.Reader) error {
+		_, _, err := image.Decode(r)
+		return err
+	}
+	switch strings.ToLower(path.Ext(fileName)) {
+	case ".png", ".jpg", ".jpeg", ".gif":
+	case ".webp":
+		decode = func(r io.Reader) error {
+			_, _, err := image.DecodeConfig(r)
+			return err
+		}
+	default:
+		return nil
+	}
+
+	file, err := os.Open(fileName)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if err := decode(file); err != nil {
+		return fmt.Errorf("%s: %w: %v", fileName, ErrImageCorrupt, err)
+	}
+
+	return nil
 }
 
 // checkForImage will check various image extensions for the given file path
 // without an extension. Returns a ErrImageNotFound error if it does not exist.
+// If the image exists but is corrupt, its path is returned along with an
+// ErrImageCorrupt error.
 func checkForImage(basePath string) (string, error) {
-	knownExtensions := []string{"png", "jpg", "jpeg", "ico"}
+	knownExtensions := []string{"png", "jpg", "jpeg", "webp", "gif", "ico"}
 	for _, ext := range knownExtensions {
 		fileName := fmt.Sprintf("%s.%s", basePath, ext)
 		if _, err := os.Stat(fileName); errors.Is(err, os.ErrNotExist) {
 			continue
 		}
-		return fileName, nil
+		return fileName, ValidateImage(fileName)
 	}
 	return "", ErrImageNotFound
 }
@@ -0,0 +1,223 @@
+package steam
+
+import (
+	"bytes"
+	"image"
+	"image/color/palette"
+	"image/gif"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/shadowblip/steam-shortcut-manager/pkg/shortcut"
+)
+
+// newTestSteamRoot will create a Steam directory with a grid folder for each
+// of the given users and use it for the rest of the test. The grid folder of
+// the first user is returned.
+func newTestSteamRoot(t *testing.T, users ...string) string {
+	t.Helper()
+	root := t.TempDir()
+	for _, user := range users {
+		if err := os.MkdirAll(filepath.Join(root, "userdata", user, "config", "grid"), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := SetBaseDir(root); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { SetBaseDir("") })
+	return filepath.Join(root, "userdata", users[0], "config", "grid")
+}
+
+// testPNG will return a valid PNG image of the given size
+func testPNG(t *testing.T, width, height int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, width, height))); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func writeTestFile(t *testing.T, path string, data []byte) {
+	t.Helper()
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestResolveImagesFlagsTruncatedImage(t *testing.T) {
+	grid := newTestSteamRoot(t, "123")
+	valid := testPNG(t, 60, 90)
+	writeTestFile(t, filepath.Join(grid, "2147483649p.png"), valid[:len(valid)/2])
+	writeTestFile(t, filepath.Join(grid, "2147483649_hero.png"), valid)
+	writeTestFile(t, filepath.Join(grid, "2147483649_logo.png"), []byte{})
+
+	images, _, err := ResolveImages("123", "2147483649")
+	if err != nil {
+		t.Fatal(err)
+	}
+	truncated := filepath.Join(grid, "2147483649p.png")
+	if images.Portrait != truncated {
+		t.Errorf("Portrait = %v, want %v", images.Portrait, truncated)
+	}
+	if !images.IsCorrupt(truncated) {
+		t.Errorf("truncated image is not flagged as corrupt: %v", images.Corrupt)
+	}
+	if !images.IsCorrupt(filepath.Join(grid, "2147483649_logo.png")) {
+		t.Errorf("empty image is not flagged as corrupt: %v", images.Corrupt)
+	}
+	if images.IsCorrupt(images.Hero) {
+		t.Errorf("valid hero image is flagged as corrupt")
+	}
+}
+
+func TestResolveImagesPopulatedGrid(t *testing.T) {
+	grid := newTestSteamRoot(t, "123", "456")
+	data := testPNG(t, 8, 8)
+	files := map[string]string{
+		"portrait":  "3663241086p.png",
+		"landscape": "3663241086.jpg",
+		"hero":      "3663241086_hero.png",
+		"logo":      "3663241086_logo.png",
+		"icon":      "3663241086_icon.ico",
+	}
+	for _, name := range files {
+		writeTestFile(t, filepath.Join(grid, name), data)
+	}
+	// Artwork of other apps and users is ignored
+	writeTestFile(t, filepath.Join(grid, "1234p.png"), data)
+	otherGrid := filepath.Join(filepath.Dir(filepath.Dir(filepath.Dir(grid))), "456", "config", "grid")
+	writeTestFile(t, filepath.Join(otherGrid, "3663241086_hero.jpg"), data)
+
+	images, icon, err := ResolveImages("123", "3663241086")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := map[string]string{
+		"portrait":  images.Portrait,
+		"landscape": images.Landscape,
+		"hero":      images.Hero,
+		"logo":      images.Logo,
+		"icon":      images.Icon,
+	}
+	for kind, name := range files {
+		if want := filepath.Join(grid, name); got[kind] != want {
+			t.Errorf("%s = %v, want %v", kind, got[kind], want)
+		}
+	}
+	if icon != images.Icon {
+		t.Errorf("returned icon = %v, want %v", icon, images.Icon)
+	}
+	if len(images.Corrupt) != 0 {
+		t.Errorf("valid images are flagged as corrupt: %v", images.Corrupt)
+	}
+}
+
+func TestResolveImagesLegacyNames(t *testing.T) {
+	grid := newTestSteamRoot(t, "123")
+	data := testPNG(t, 8, 8)
+	legacyGrid := filepath.Join(grid, "15733500661767077888.png")
+	legacyIcon := filepath.Join(grid, "3663241086-icon.png")
+	writeTestFile(t, legacyGrid, data)
+	writeTestFile(t, legacyIcon, data)
+
+	images, icon, err := ResolveImages("123", "3663241086")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if images.Landscape != legacyGrid {
+		t.Errorf("Landscape = %v, want legacy grid %v", images.Landscape, legacyGrid)
+	}
+	if icon != legacyIcon {
+		t.Errorf("icon = %v, want legacy icon %v", icon, legacyIcon)
+	}
+	if images.Portrait != "" || images.Hero != "" || images.Logo != "" {
+		t.Errorf("missing images resolved to %+v, want empty paths", images)
+	}
+}
+
+func TestGetImageChecksAppID(t *testing.T) {
+	grid := newTestSteamRoot(t, "123")
+	data := testPNG(t, 8, 8)
+	portrait := filepath.Join(grid, "3663241086p.png")
+	writeTestFile(t, portrait, data)
+	// Files without an app ID must not match an empty or invalid ID
+	writeTestFile(t, filepath.Join(grid, "p.png"), data)
+	writeTestFile(t, filepath.Join(grid, "_hero.png"), data)
+
+	// The signed form of the app ID finds the same file
+	for _, appId := range []string{"3663241086", "-631726210"} {
+		got, err := GetImagePortrait("123", appId)
+		if err != nil {
+			t.Errorf("GetImagePortrait(%q) error = %v", appId, err)
+		}
+		if got != portrait {
+			t.Errorf("GetImagePortrait(%q) = %v, want %v", appId, got, portrait)
+		}
+	}
+
+	for _, appId := range []string{"", "0", "abc", "3663241086p"} {
+		if got, err := GetImagePortrait("123", appId); err == nil || got != "" {
+			t.Errorf("GetImagePortrait(%q) = %v, %v, want an error", appId, got, err)
+		}
+		if got, err := GetImageHero("123", appId); err == nil || got != "" {
+			t.Errorf("GetImageHero(%q) = %v, %v, want an error", appId, got, err)
+		}
+	}
+}
+
+// testAnimatedWebP will return the header of an animated WebP image of the
+// given size. It is enough for image.DecodeConfig, which is all that can be
+// decoded of an animated WebP.
+func testAnimatedWebP(width, height int) []byte {
+	vp8x := []byte{
+		'V', 'P', '8', 'X', 10, 0, 0, 0,
+		1 << 1, 0, 0, 0,
+		byte(width - 1), byte((width - 1) >> 8), byte((width - 1) >> 16),
+		byte(height - 1), byte((height - 1) >> 8), byte((height - 1) >> 16),
+	}
+	anim := []byte{'A', 'N', 'I', 'M', 6, 0, 0, 0, 0, 0, 0, 0, 0, 0}
+	size := 4 + len(vp8x) + len(anim)
+	data := []byte{'R', 'I', 'F', 'F', byte(size), byte(size >> 8), byte(size >> 16), byte(size >> 24), 'W', 'E', 'B', 'P'}
+	return append(append(data, vp8x...), anim...)
+}
+
+func TestArtworkStatusFindsWebPAndGIF(t *testing.T) {
+	grid := newTestSteamRoot(t, "123")
+	shortcutsPath, err := GetShortcutsPath("123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	shortcuts := shortcut.NewShortcuts()
+	shortcuts.Add(&shortcut.Shortcut{AppName: "Animated", Exe: "/bin/true", Appid: 3663241086})
+	if err := shortcut.Save(shortcuts, shortcutsPath); err != nil {
+		t.Fatal(err)
+	}
+
+	var gifData bytes.Buffer
+	if err := gif.Encode(&gifData, image.NewPaletted(image.Rect(0, 0, 60, 90), palette.Plan9), nil); err != nil {
+		t.Fatal(err)
+	}
+	writeTestFile(t, filepath.Join(grid, "3663241086_hero.webp"), testAnimatedWebP(1920, 620))
+	writeTestFile(t, filepath.Join(grid, "3663241086p.gif"), gifData.Bytes())
+
+	status, err := ArtworkStatus("123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	presence := status["3663241086"]
+	hero := presence.Hero
+	if !hero.Present || hero.Corrupt || hero.Width != 1920 || hero.Height != 620 {
+		t.Errorf("Hero = %+v, want a present 1920x620 WebP image", hero)
+	}
+	portrait := presence.Portrait
+	if !portrait.Present || portrait.Corrupt || portrait.Width != 60 || portrait.Height != 90 {
+		t.Errorf("Portrait = %+v, want a present 60x90 GIF image", portrait)
+	}
+	if missing := presence.Missing(); len(missing) != 3 {
+		t.Errorf("Missing() = %v, want landscape, logo and icon", missing)
+	}
+}
@@ -0,0 +1,36 @@
+package steam
+
+import (
+	"fmt"
+
+	"github.com/shadowblip/steam-shortcut-manager/pkg/shortcut"
+)
+
+// SetShortcutIcon will update the Icon field of the shortcut with the given
+// app ID in the given user's shortcuts file to point at the given image.
+func SetShortcutIcon(user string, appID uint64, iconPath string) error {
+	shortcutsPath, err := GetShortcutsPath(user)
+	if err != nil {
+		return err
+	}
+	shortcuts, err := shortcut.Load(shortcutsPath)
+	if err != nil {
+		return err
+	}
+
+	// Update every matching shortcut
+	found := false
+	for key, sc := range shortcuts.Shortcuts {
+		if uint64(sc.Appid) != appID {
+			continue
+		}
+		sc.Icon = iconPath
+		shortcuts.Shortcuts[key] = sc
+		found = true
+	}
+	if !found {
+		return fmt.Errorf("no shortcut found with id: %v", appID)
+	}
+
+	return shortcut.Save(shortcuts, shortcutsPath)
+}
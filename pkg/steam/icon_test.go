@@ -0,0 +1,63 @@
+package steam
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/shadowblip/steam-shortcut-manager/pkg/shortcut"
+)
+
+func TestSetArtworkUpdatesShortcutIcon(t *testing.T) {
+	gridDir := newTestSteamRoot(t, "123")
+	shortcutsPath := filepath.Join(filepath.Dir(gridDir), "shortcuts.vdf")
+	const appID = 3663241086
+	shortcuts := shortcut.NewShortcuts()
+	if err := shortcuts.Add(&shortcut.Shortcut{AppName: "Game", Exe: "/bin/sh", Appid: appID}); err != nil {
+		t.Fatal(err)
+	}
+	if err := shortcut.Save(shortcuts, shortcutsPath); err != nil {
+		t.Fatal(err)
+	}
+
+	icon := testPNG(t, 64, 64)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/png")
+		w.Write(icon)
+	}))
+	defer server.Close()
+
+	artwork := &ArtworkConfig{IconImage: server.URL + "/icon.png"}
+	opts := &ArtworkOptions{User: "123", Method: ArtworkMethodFilesystem, UpdateShortcutIcon: true}
+	results, err := SetArtworkDetailed(appID, artwork, opts)
+	if err != nil {
+		t.Fatalf("SetArtworkDetailed() error = %v", err)
+	}
+	if len(results) != 1 || results[0].Err != nil {
+		t.Fatalf("SetArtworkDetailed() results = %+v, want one applied icon", results)
+	}
+
+	// The icon is downloaded to a stable path in the grid folder
+	want := filepath.Join(gridDir, "3663241086_icon.png")
+	if results[0].Path != want {
+		t.Errorf("icon written to %v, want %v", results[0].Path, want)
+	}
+	if _, err := os.Stat(want); err != nil {
+		t.Errorf("downloaded icon is missing: %v", err)
+	}
+
+	// The Icon field of the shortcut points at the downloaded file
+	shortcuts, err = shortcut.Load(shortcutsPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sc, err := shortcuts.LookupByID(appID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sc.Icon != want {
+		t.Errorf("shortcut Icon = %q, want %q", sc.Icon, want)
+	}
+}
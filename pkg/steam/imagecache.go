@@ -0,0 +1,173 @@
+package steam
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/shadowblip/steam-shortcut-manager/pkg/fsutil"
+	"github.com/shadowblip/steam-shortcut-manager/pkg/logger"
+)
+
+// UseImageCache controls whether downloaded artwork is stored in and served
+// from the image cache.
+var UseImageCache = true
+
+// imageCacheEntry is the metadata stored next to a cached image, used to
+// revalidate the image with the server.
+type imageCacheEntry struct {
+	URL          string `json:"url"`
+	Ext          string `json:"ext"`
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"last_modified,omitempty"`
+}
+
+// GetImageCacheDir will return the directory downloaded artwork is cached in
+func GetImageCacheDir() (string, error) {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(cacheDir, "steam-shortcut-manager", "images"), nil
+}
+
+// ClearImageCache will remove all cached artwork. Returns the number of
+// images that were removed.
+func ClearImageCache() (int, error) {
+	cacheDir, err := GetImageCacheDir()
+	if err != nil {
+		return 0, err
+	}
+	matches, err := filepath.Glob(filepath.Join(cacheDir, "*.json"))
+	if err != nil {
+		return 0, err
+	}
+	if err := os.RemoveAll(cacheDir); err != nil {
+		return 0, err
+	}
+	return len(matches), nil
+}
+
+// imageCachePaths will return the image and metadata files for the given URL
+func imageCachePaths(cacheDir, url string) (string, string) {
+	sum := sha256.Sum256([]byte(url))
+	base := filepath.Join(cacheDir, hex.EncodeToString(sum[:]))
+	return base, base + ".json"
+}
+
+// loadCachedImage will return the cached image data and metadata for the
+// given URL, if any.
+func loadCachedImage(cacheDir, url string) ([]byte, *imageCacheEntry, bool) {
+	dataPath, metaPath := imageCachePaths(cacheDir, url)
+	meta, err := os.ReadFile(metaPath)
+	if err != nil {
+		return nil, nil, false
+	}
+	var entry imageCacheEntry
+	if err := json.Unmarshal(meta, &entry); err != nil || entry.URL != url {
+		return nil, nil, false
+	}
+	data, err := os.ReadFile(dataPath)
+	if err != nil {
+		return nil, nil, false
+	}
+	return data, &entry, true
+}
+
+// storeCachedImage will store the given image data and metadata. The image is
+// written before its metadata so a partially stored entry is never used.
+func storeCachedImage(cacheDir string, data []byte, entry *imageCacheEntry) error {
+	if err := os.MkdirAll(cacheDir, 0755); err != nil {
+		return err
+	}
+	meta, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	dataPath, metaPath := imageCachePaths(cacheDir, entry.URL)
+	if err := fsutil.WriteFileAtomic(dataPath, data, 0644); err != nil {
+		return err
+	}
+	return fsutil.WriteFileAtomic(metaPath, meta, 0644)
+}
+
+// imageCacheLocks holds a mutex for each URL being downloaded, so the same
+// image requested by several shortcuts at once is only downloaded once.
+var imageCacheLocks sync.Map
+
+// cachedDownloadArtwork downloads the given image URL through the image
+// cache. Cached images are revalidated with the server using their ETag and
+// Last-Modified headers, so an unchanged image is only downloaded once.
+// Images the server sent without either header are reused as is.
+func cachedDownloadArtwork(ctx context.Context, url string, opts *ArtworkOptions) ([]byte, string, error) {
+	cacheDir, err := GetImageCacheDir()
+	if err != nil || !UseImageCache {
+		return downloadArtwork(ctx, url, opts)
+	}
+
+	lock, _ := imageCacheLocks.LoadOrStore(url, &sync.Mutex{})
+	lock.(*sync.Mutex).Lock()
+	defer lock.(*sync.Mutex).Unlock()
+
+	cached, entry, ok := loadCachedImage(cacheDir, url)
+	if ok && entry.ETag == "" && entry.LastModified == "" {
+		logger.Debugf("Using cached artwork for %s", url)
+		return cached, entry.Ext, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to download artwork: %w", err)
+	}
+	if ok {
+		if entry.ETag != "" {
+			req.Header.Set("If-None-Match", entry.ETag)
+		}
+		if entry.LastModified != "" {
+			req.Header.Set("If-Modified-Since", entry.LastModified)
+		}
+	}
+	resp, err := doArtworkRequest(req, opts)
+	if err != nil {
+		if ok && !errors.Is(err, context.Canceled) {
+			logger.Warningf("Unable to revalidate cached artwork %s, using cached copy: %v", url, err)
+			return cached, entry.Ext, nil
+		}
+		return nil, "", fmt.Errorf("failed to download artwork: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified && ok {
+		logger.Debugf("Using cached artwork for %s", url)
+		return cached, entry.Ext, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("failed to download artwork: HTTP %d", resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read artwork data: %w", err)
+	}
+	ext := getExtensionFromResponse(resp, url)
+
+	entry = &imageCacheEntry{
+		URL:          url,
+		Ext:          ext,
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+	}
+	if err := storeCachedImage(cacheDir, data, entry); err != nil {
+		logger.Debugf("Unable to cache artwork %s: %v", url, err)
+	}
+
+	return data, ext, nil
+}
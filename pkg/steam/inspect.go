@@ -0,0 +1,109 @@
+package steam
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/gif"
+	"os"
+)
+
+// MaxArtworkSize is the largest artwork image in bytes that Steam's CEF API
+// reliably accepts. Larger images are silently rejected.
+const MaxArtworkSize = 10 * 1024 * 1024
+
+// ImageInfo describes an artwork image file
+type ImageInfo struct {
+	Format   string `json:"format"`
+	Width    int    `json:"width"`
+	Height   int    `json:"height"`
+	Animated bool   `json:"animated"`
+	Frames   int    `json:"frames"`
+	Size     int64  `json:"size"`
+
+	// declaredAnimated is whether or not the image is in an animated format
+	// or has its animation flag set, regardless of its frame count
+	declaredAnimated bool
+}
+
+// TooLarge will return whether or not the image is larger than Steam accepts
+func (i *ImageInfo) TooLarge() bool {
+	return i.Size > MaxArtworkSize
+}
+
+// InspectImage will return the format, dimensions, frame count and size of
+// the given image file. PNG (including APNG), JPEG, GIF and WebP images are
+// supported.
+func InspectImage(path string) (*ImageInfo, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return InspectImageData(data)
+}
+
+// InspectImageData will return the format, dimensions, frame count and size
+// of the given image data. See InspectImage.
+func InspectImageData(data []byte) (*ImageInfo, error) {
+	config, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrImageCorrupt, err)
+	}
+	info := &ImageInfo{
+		Format: format,
+		Width:  config.Width,
+		Height: config.Height,
+		Frames: 1,
+		Size:   int64(len(data)),
+	}
+
+	switch format {
+	case "gif":
+		g, err := gif.DecodeAll(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrImageCorrupt, err)
+		}
+		info.Frames = len(g.Image)
+		info.declaredAnimated = true
+	case "webp":
+		info.Frames, info.declaredAnimated = webpFrames(data)
+	case "png":
+		info.Frames, info.declaredAnimated = apngFrames(data)
+	}
+	info.Animated = info.Frames > 1
+
+	return info, nil
+}
+
+// webpFrames will return the number of frames of the given WebP image, and
+// whether or not its animation flag is set. Animated WebP images have an
+// ANMF chunk for every frame.
+func webpFrames(data []byte) (int, bool) {
+	if len(data) <= 20 || string(data[12:16]) != "VP8X" || data[20]&0x02 == 0 {
+		return 1, false
+	}
+
+	frames := 0
+	for offset := 12; offset+8 <= len(data); {
+		fourCC := string(data[offset : offset+4])
+		size := int(binary.LittleEndian.Uint32(data[offset+4 : offset+8]))
+		if fourCC == "ANMF" {
+			frames++
+		}
+		// Chunks are padded to an even size
+		offset += 8 + size + size%2
+	}
+	return frames, true
+}
+
+// apngFrames will return the number of frames of the given PNG image, and
+// whether or not it is an APNG image. APNG images have an animation control
+// chunk holding the frame count before the image data.
+func apngFrames(data []byte) (int, bool) {
+	actl := bytes.Index(data, []byte("acTL"))
+	if actl == -1 || actl > bytes.Index(data, []byte("IDAT")) || actl+8 > len(data) {
+		return 1, false
+	}
+	return int(binary.BigEndian.Uint32(data[actl+4 : actl+8])), true
+}
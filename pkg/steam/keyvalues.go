@@ -0,0 +1,199 @@
+package steam
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// KeyValues is a parsed text VDF (KeyValues) document, as used by Steam's
+// config files such as loginusers.vdf and libraryfolders.vdf. Values are
+// either strings or nested KeyValues.
+type KeyValues map[string]interface{}
+
+// LoadKeyValues will parse the given text VDF file
+func LoadKeyValues(file string) (KeyValues, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	kv, err := ParseKeyValues(data)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse %s: %w", file, err)
+	}
+	return kv, nil
+}
+
+// ParseKeyValues will parse the given text VDF data
+func ParseKeyValues(data []byte) (KeyValues, error) {
+	p := &kvParser{data: data}
+	kv, err := p.parseMap(false)
+	if err != nil {
+		return nil, fmt.Errorf("line %d: %w", p.line+1, err)
+	}
+	return kv, nil
+}
+
+// Get will return the value of the given key. Steam is not consistent about
+// the case of keys, so the lookup is case-insensitive.
+func (kv KeyValues) Get(key string) (interface{}, bool) {
+	if value, ok := kv[key]; ok {
+		return value, true
+	}
+	for k, value := range kv {
+		if strings.EqualFold(k, key) {
+			return value, true
+		}
+	}
+	return nil, false
+}
+
+// GetString will return the string value of the given key
+func (kv KeyValues) GetString(key string) string {
+	value, _ := kv.Get(key)
+	str, _ := value.(string)
+	return str
+}
+
+// GetMap will return the nested KeyValues of the given key
+func (kv KeyValues) GetMap(key string) KeyValues {
+	value, _ := kv.Get(key)
+	m, _ := value.(KeyValues)
+	return m
+}
+
+// kvParser is a small recursive descent parser for text VDF documents
+type kvParser struct {
+	data []byte
+	pos  int
+	line int
+}
+
+// parseMap will parse key/value pairs until the closing brace, or the end of
+// the document if this is the root.
+func (p *kvParser) parseMap(nested bool) (KeyValues, error) {
+	kv := KeyValues{}
+	for {
+		token, quoted, err := p.next()
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case token == "" && !quoted:
+			if nested {
+				return nil, fmt.Errorf("unexpected end of file")
+			}
+			return kv, nil
+		case token == "}" && !quoted:
+			if !nested {
+				return nil, fmt.Errorf("unexpected '}'")
+			}
+			return kv, nil
+		case token == "{" && !quoted:
+			return nil, fmt.Errorf("unexpected '{'")
+		}
+		key := token
+
+		value, quoted, err := p.next()
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case value == "{" && !quoted:
+			child, err := p.parseMap(true)
+			if err != nil {
+				return nil, err
+			}
+			kv[key] = child
+		case (value == "" || value == "}") && !quoted:
+			return nil, fmt.Errorf("missing value for key %q", key)
+		default:
+			kv[key] = value
+		}
+
+		// Skip conditionals like [$WIN32]
+		p.skipSpace()
+		if p.pos < len(p.data) && p.data[p.pos] == '[' {
+			for p.pos < len(p.data) && p.data[p.pos] != ']' {
+				p.pos++
+			}
+			p.pos++
+		}
+	}
+}
+
+// next will return the next token. An empty unquoted token means the end of
+// the document was reached.
+func (p *kvParser) next() (string, bool, error) {
+	p.skipSpace()
+	if p.pos >= len(p.data) {
+		return "", false, nil
+	}
+
+	switch c := p.data[p.pos]; c {
+	case '{', '}':
+		p.pos++
+		return string(c), false, nil
+	case '"':
+		p.pos++
+		var sb strings.Builder
+		for p.pos < len(p.data) {
+			c := p.data[p.pos]
+			p.pos++
+			switch c {
+			case '"':
+				return sb.String(), true, nil
+			case '\\':
+				if p.pos < len(p.data) {
+					escaped := p.data[p.pos]
+					p.pos++
+					switch escaped {
+					case 'n':
+						sb.WriteByte('\n')
+					case 't':
+						sb.WriteByte('\t')
+					default:
+						sb.WriteByte(escaped)
+					}
+				}
+			case '\n':
+				p.line++
+				sb.WriteByte(c)
+			default:
+				sb.WriteByte(c)
+			}
+		}
+		return "", false, fmt.Errorf("unterminated string")
+	}
+
+	// Unquoted token
+	start := p.pos
+	for p.pos < len(p.data) {
+		c := p.data[p.pos]
+		if c == ' ' || c == '\t' || c == '\r' || c == '\n' || c == '{' || c == '}' || c == '"' {
+			break
+		}
+		p.pos++
+	}
+	return string(p.data[start:p.pos]), false, nil
+}
+
+// skipSpace will skip whitespace and // comments
+func (p *kvParser) skipSpace() {
+	for p.pos < len(p.data) {
+		c := p.data[p.pos]
+		switch {
+		case c == '\n':
+			p.line++
+			p.pos++
+		case c == ' ' || c == '\t' || c == '\r':
+			p.pos++
+		case c == '/' && p.pos+1 < len(p.data) && p.data[p.pos+1] == '/':
+			for p.pos < len(p.data) && p.data[p.pos] != '\n' {
+				p.pos++
+			}
+		default:
+			return
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package steam
+
+import (
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+)
+
+// GetLibraryFoldersPath will return the path to Steam's libraryfolders.vdf
+func GetLibraryFoldersPath() (string, error) {
+	steamDir, err := GetBaseDir()
+	if err != nil {
+		return "", err
+	}
+	return path.Join(steamDir, "steamapps", "libraryfolders.vdf"), nil
+}
+
+// GetLibraryFolders will return the Steam library folders that games can be
+// installed to. The primary library in the Steam directory is always first.
+func GetLibraryFolders() ([]string, error) {
+	steamDir, err := GetBaseDir()
+	if err != nil {
+		return nil, err
+	}
+	folders := []string{steamDir}
+
+	libraryFoldersPath, err := GetLibraryFoldersPath()
+	if err != nil {
+		return nil, err
+	}
+	kv, err := LoadKeyValues(libraryFoldersPath)
+	if err != nil {
+		return nil, err
+	}
+
+	// Library entries are keyed by their index. Newer versions of Steam store
+	// each library as a nested block with a "path" key, while older versions
+	// store the path directly.
+	entries := kv.GetMap("libraryfolders")
+	indices := []int{}
+	for key := range entries {
+		if index, err := strconv.Atoi(key); err == nil {
+			indices = append(indices, index)
+		}
+	}
+	sort.Ints(indices)
+
+	for _, index := range indices {
+		var folder string
+		switch entry := entries[strconv.Itoa(index)].(type) {
+		case KeyValues:
+			folder = entry.GetString("path")
+		case string:
+			folder = entry
+		}
+		if folder == "" || containsPath(folders, folder) {
+			continue
+		}
+		folders = append(folders, folder)
+	}
+
+	return folders, nil
+}
+
+// containsPath will return whether or not the given path is in the list
+func containsPath(paths []string, p string) bool {
+	for _, existing := range paths {
+		if filepath.Clean(existing) == filepath.Clean(p) {
+			return true
+		}
+	}
+	return false
+}
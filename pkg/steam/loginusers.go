@@ -0,0 +1,118 @@
+package steam
+
+import (
+	"fmt"
+	"path"
+	"strconv"
+)
+
+// steamID64Base is the offset between a 64-bit Steam ID and the 32-bit
+// account ID used for the userdata directories.
+const steamID64Base = 76561197960265728
+
+// LoginUser is an account listed in Steam's loginusers.vdf
+type LoginUser struct {
+	// ID is the account ID, as used for the userdata directory
+	ID          string
+	SteamID64   string
+	AccountName string
+	PersonaName string
+	MostRecent  bool
+	Timestamp   int64
+}
+
+// GetLoginUsersPath will return the path to Steam's loginusers.vdf
+func GetLoginUsersPath() (string, error) {
+	steamDir, err := GetBaseDir()
+	if err != nil {
+		return "", err
+	}
+	return path.Join(steamDir, "config", "loginusers.vdf"), nil
+}
+
+// GetLoginUsers will return the accounts that have logged into Steam on this
+// machine.
+func GetLoginUsers() ([]LoginUser, error) {
+	loginUsersPath, err := GetLoginUsersPath()
+	if err != nil {
+		return nil, err
+	}
+	kv, err := LoadKeyValues(loginUsersPath)
+	if err != nil {
+		return nil, err
+	}
+
+	users := []LoginUser{}
+	for steamID64, value := range kv.GetMap("users") {
+		entry, ok := value.(KeyValues)
+		if !ok {
+			continue
+		}
+		id, err := strconv.ParseUint(steamID64, 10, 64)
+		if err != nil || id < steamID64Base {
+			continue
+		}
+		timestamp, _ := strconv.ParseInt(entry.GetString("Timestamp"), 10, 64)
+		users = append(users, LoginUser{
+			ID:          fmt.Sprintf("%d", id-steamID64Base),
+			SteamID64:   steamID64,
+			AccountName: entry.GetString("AccountName"),
+			PersonaName: entry.GetString("PersonaName"),
+			MostRecent:  entry.GetString("MostRecent") == "1",
+			Timestamp:   timestamp,
+		})
+	}
+
+	return users, nil
+}
+
+// GetMostRecentUser will return the ID of the account that most recently
+// logged into Steam. The account flagged with MostRecent is preferred, using
+// the login timestamp as a tiebreaker.
+func GetMostRecentUser() (string, error) {
+	users, err := GetLoginUsers()
+	if err != nil {
+		return "", err
+	}
+
+	var best *LoginUser
+	for i := range users {
+		user := &users[i]
+		switch {
+		case best == nil:
+			best = user
+		case user.MostRecent != best.MostRecent:
+			if user.MostRecent {
+				best = user
+			}
+		case user.Timestamp > best.Timestamp:
+			best = user
+		}
+	}
+	if best == nil {
+		return "", fmt.Errorf("no Steam users found in loginusers.vdf")
+	}
+
+	return best.ID, nil
+}
+
+// GetUserPersona will return the persona (display) name of the given user. If
+// the user has no persona name, the account name is returned instead.
+func GetUserPersona(user string) (string, error) {
+	users, err := GetLoginUsers()
+	if err != nil {
+		return "", err
+	}
+	for _, loginUser := range users {
+		if loginUser.ID != user {
+			continue
+		}
+		if loginUser.PersonaName != "" {
+			return loginUser.PersonaName, nil
+		}
+		if loginUser.AccountName != "" {
+			return loginUser.AccountName, nil
+		}
+	}
+	return "", fmt.Errorf("no persona found for user %v", user)
+}
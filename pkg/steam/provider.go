@@ -0,0 +1,161 @@
+package steam
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/shadowblip/steam-shortcut-manager/pkg/httpclient"
+	"github.com/shadowblip/steam-shortcut-manager/pkg/logger"
+)
+
+// ErrNoArtwork is returned by an ArtworkProvider that has no artwork for the
+// requested game
+var ErrNoArtwork = errors.New("no artwork found")
+
+// CDNBaseURL is the base URL of the official Steam CDN that hosts the library
+// artwork of Steam games
+var CDNBaseURL = "https://cdn.cloudflare.steamstatic.com/steam/apps"
+
+// ArtworkQuery describes the game to find artwork for. Providers use the
+// fields they understand and return ErrNoArtwork if none of them apply.
+type ArtworkQuery struct {
+	// Name is the name of the game
+	Name string
+
+	// SteamAppID is the app ID of the real Steam game, if the shortcut wraps
+	// one. Zero if unknown.
+	SteamAppID uint64
+}
+
+// ArtworkProvider finds the artwork URLs of a game
+type ArtworkProvider interface {
+	FetchArtworkConfig(query ArtworkQuery) (*ArtworkConfig, error)
+}
+
+// ChainProvider asks each of its providers for artwork in order. Asset types
+// that are still missing after a provider is asked are filled in by the next
+// one, so the result combines the best artwork every provider has.
+type ChainProvider struct {
+	Providers []ArtworkProvider
+}
+
+// NewChainProvider will return a provider that tries the given providers in
+// order
+func NewChainProvider(providers ...ArtworkProvider) *ChainProvider {
+	return &ChainProvider{Providers: providers}
+}
+
+// FetchArtworkConfig will return the artwork found by the providers of the
+// chain. Returns ErrNoArtwork if none of them found any.
+func (c *ChainProvider) FetchArtworkConfig(query ArtworkQuery) (*ArtworkConfig, error) {
+	config := &ArtworkConfig{}
+	var lastErr error
+	for _, provider := range c.Providers {
+		found, err := provider.FetchArtworkConfig(query)
+		if err != nil {
+			logger.Debugf("Artwork provider %T failed: %v", provider, err)
+			lastErr = err
+			continue
+		}
+		mergeArtworkConfig(config, found)
+		if artworkConfigComplete(config) {
+			break
+		}
+	}
+
+	if *config == (ArtworkConfig{}) {
+		if lastErr != nil && !errors.Is(lastErr, ErrNoArtwork) {
+			return nil, fmt.Errorf("%w: %v", ErrNoArtwork, lastErr)
+		}
+		return nil, ErrNoArtwork
+	}
+	return config, nil
+}
+
+// mergeArtworkConfig will copy the artwork of src into the asset types that
+// are still empty in dst
+func mergeArtworkConfig(dst, src *ArtworkConfig) {
+	fill := func(field *string, value string) {
+		if *field == "" {
+			*field = value
+		}
+	}
+	fill(&dst.GridPortrait, src.GridPortrait)
+	fill(&dst.GridLandscape, src.GridLandscape)
+	fill(&dst.HeroImage, src.HeroImage)
+	fill(&dst.LogoImage, src.LogoImage)
+	fill(&dst.IconImage, src.IconImage)
+	fill(&dst.GridLegacy, src.GridLegacy)
+}
+
+// artworkConfigComplete will return whether or not every main asset type of
+// the given config is set
+func artworkConfigComplete(config *ArtworkConfig) bool {
+	return config.GridPortrait != "" && config.GridLandscape != "" &&
+		config.HeroImage != "" && config.LogoImage != "" && config.IconImage != ""
+}
+
+// CDNProvider finds the library artwork of real Steam games on the official
+// Steam CDN by their Steam app ID. Icons are not available on the CDN.
+type CDNProvider struct {
+	// Context is the context requests are made with. If nil,
+	// context.Background is used.
+	Context context.Context
+}
+
+// FetchArtworkConfig will return the Steam CDN artwork of the game with the
+// query's Steam app ID. Only images that exist on the CDN are returned.
+// Returns ErrNoArtwork if the query has no Steam app ID or the CDN has no
+// artwork for it.
+func (p *CDNProvider) FetchArtworkConfig(query ArtworkQuery) (*ArtworkConfig, error) {
+	if query.SteamAppID == 0 {
+		return nil, ErrNoArtwork
+	}
+	ctx := p.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	base := fmt.Sprintf("%s/%d", CDNBaseURL, query.SteamAppID)
+	config := &ArtworkConfig{}
+	assets := []struct {
+		field *string
+		url   string
+	}{
+		{&config.GridPortrait, base + "/library_600x900.jpg"},
+		{&config.GridLandscape, base + "/header.jpg"},
+		{&config.HeroImage, base + "/library_hero.jpg"},
+		{&config.LogoImage, base + "/logo.png"},
+	}
+	for _, asset := range assets {
+		ok, err := cdnImageExists(ctx, asset.url)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			*asset.field = asset.url
+		}
+	}
+
+	if *config == (ArtworkConfig{}) {
+		return nil, ErrNoArtwork
+	}
+	return config, nil
+}
+
+// cdnImageExists will return whether or not the given Steam CDN image exists
+func cdnImageExists(ctx context.Context, url string) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, "HEAD", url, nil)
+	if err != nil {
+		return false, err
+	}
+	resp, err := httpclient.Do(req)
+	if err != nil {
+		return false, fmt.Errorf("failed to check Steam CDN artwork: %w", err)
+	}
+	resp.Body.Close()
+
+	return resp.StatusCode == http.StatusOK, nil
+}
@@ -0,0 +1,123 @@
+package steam
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"regexp"
+	"strconv"
+
+	"github.com/shadowblip/steam-shortcut-manager/pkg/shortcut"
+)
+
+// shortcutAppIDFlag is set on every non-Steam shortcut app ID. Grid files for
+// app IDs without it belong to real Steam games and are never pruned.
+const shortcutAppIDFlag = 0x80000000
+
+// gridFilePattern matches grid artwork file names, capturing the app ID.
+// Icons downloaded by older versions use a "-icon" suffix.
+var gridFilePattern = regexp.MustCompile(`^(\d+)(p|_hero|_logo|_icon|-icon)?\.[A-Za-z0-9]+$`)
+
+// artworkFileAppID will return the app ID the grid artwork file with the
+// given name belongs to. Legacy grid images, which are named with a 64-bit
+// ID, return the app ID the ID was made from. Returns false if the name is
+// not a grid artwork file name.
+func artworkFileAppID(name string) (uint64, bool) {
+	matches := gridFilePattern.FindStringSubmatch(name)
+	if matches == nil {
+		return 0, false
+	}
+	appID, err := strconv.ParseUint(matches[1], 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	if appID > 0xffffffff {
+		// Legacy grid images are named with a 64-bit ID
+		if appID != LegacyGridID(appID>>32) {
+			return 0, false
+		}
+		appID >>= 32
+	}
+	return appID, true
+}
+
+// FindOrphanedArtwork will return the grid artwork files of the given user
+// that belong to a shortcut app ID which no longer matches any of the user's
+// shortcuts. This happens when a shortcut is renamed or removed, since the
+// app ID is derived from the name and executable.
+func FindOrphanedArtwork(user string) ([]string, error) {
+	gridPath, err := GetImagesDir(user)
+	if err != nil {
+		return nil, err
+	}
+	files, err := os.ReadDir(gridPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return []string{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Collect the app IDs of the user's current shortcuts
+	appIDs := map[uint64]bool{}
+	if HasShortcuts(user) {
+		shortcutsPath, err := GetShortcutsPath(user)
+		if err != nil {
+			return nil, err
+		}
+		shortcuts, err := shortcut.Load(shortcutsPath)
+		if err != nil {
+			return nil, fmt.Errorf("unable to load shortcuts for user %v: %w", user, err)
+		}
+		for _, sc := range shortcuts.Shortcuts {
+			appIDs[uint64(uint32(sc.Appid))] = true
+		}
+	}
+
+	orphaned := []string{}
+	for _, file := range files {
+		if file.IsDir() {
+			continue
+		}
+		appID, ok := artworkFileAppID(file.Name())
+		if !ok {
+			continue
+		}
+		if appID&shortcutAppIDFlag == 0 {
+			continue
+		}
+		if appIDs[appID] {
+			continue
+		}
+		orphaned = append(orphaned, path.Join(gridPath, file.Name()))
+	}
+
+	return orphaned, nil
+}
+
+// PruneOrphanedArtwork will remove the grid artwork files of the given user
+// that no longer belong to any of the user's shortcuts. Returns the list of
+// files that were removed.
+func PruneOrphanedArtwork(user string) ([]string, error) {
+	orphaned, err := FindOrphanedArtwork(user)
+	if err != nil {
+		return nil, err
+	}
+	return RemoveArtworkFiles(orphaned)
+}
+
+// RemoveArtworkFiles will remove exactly the given grid artwork files, such
+// as the ones found by FindOrphanedArtwork and confirmed by the user. Returns
+// the list of files that were removed, up to the first failure.
+func RemoveArtworkFiles(files []string) ([]string, error) {
+	removed := []string{}
+	for _, file := range files {
+		if err := os.Remove(file); err != nil {
+			return removed, fmt.Errorf("failed to remove %s: %w", file, err)
+		}
+		removed = append(removed, file)
+	}
+
+	return removed, nil
+}
@@ -0,0 +1,89 @@
+package steam
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/shadowblip/steam-shortcut-manager/pkg/shortcut"
+)
+
+// newPruneTestGrid will create a grid folder for user 123 with artwork of a
+// current shortcut, a removed shortcut, and a real Steam game. Returns the
+// grid folder and the artwork files of the removed shortcut.
+func newPruneTestGrid(t *testing.T) (string, []string) {
+	t.Helper()
+	grid := newTestSteamRoot(t, "123")
+	shortcuts := shortcut.NewShortcuts()
+	if err := shortcuts.Add(&shortcut.Shortcut{AppName: "Current", Exe: "/bin/sh", Appid: 3663241086}); err != nil {
+		t.Fatal(err)
+	}
+	if err := shortcut.Save(shortcuts, filepath.Join(filepath.Dir(grid), "shortcuts.vdf")); err != nil {
+		t.Fatal(err)
+	}
+
+	data := testPNG(t, 8, 8)
+	for _, name := range []string{
+		"3663241086p.png", "3663241086_hero.png", "3663241086-icon.png",
+		"440p.png", "440_hero.jpg",
+		"notes.txt",
+	} {
+		writeTestFile(t, filepath.Join(grid, name), data)
+	}
+	orphaned := []string{}
+	for _, name := range []string{
+		"2147483649p.png", "2147483649_logo.png", "2147483649_icon.png", "2147483649-icon.ico",
+		"9223372041183297536.jpg",
+	} {
+		writeTestFile(t, filepath.Join(grid, name), data)
+		orphaned = append(orphaned, filepath.Join(grid, name))
+	}
+	sort.Strings(orphaned)
+	return grid, orphaned
+}
+
+func TestFindOrphanedArtwork(t *testing.T) {
+	_, want := newPruneTestGrid(t)
+
+	orphaned, err := FindOrphanedArtwork("123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(orphaned)
+	if len(orphaned) != len(want) {
+		t.Fatalf("FindOrphanedArtwork() = %v, want %v", orphaned, want)
+	}
+	for i := range want {
+		if orphaned[i] != want[i] {
+			t.Errorf("FindOrphanedArtwork()[%d] = %v, want %v", i, orphaned[i], want[i])
+		}
+	}
+}
+
+func TestRemoveArtworkFilesOnlyRemovesGivenFiles(t *testing.T) {
+	grid, orphaned := newPruneTestGrid(t)
+	confirmed := orphaned[:2]
+
+	// Artwork orphaned after the files were confirmed is left alone
+	later := filepath.Join(grid, "2147483650p.png")
+	writeTestFile(t, later, testPNG(t, 8, 8))
+
+	removed, err := RemoveArtworkFiles(confirmed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(removed) != len(confirmed) {
+		t.Errorf("RemoveArtworkFiles() = %v, want %v", removed, confirmed)
+	}
+	for _, file := range confirmed {
+		if _, err := os.Stat(file); !os.IsNotExist(err) {
+			t.Errorf("%v was not removed", file)
+		}
+	}
+	for _, file := range append(orphaned[2:], later) {
+		if _, err := os.Stat(file); err != nil {
+			t.Errorf("%v was removed without being confirmed", file)
+		}
+	}
+}
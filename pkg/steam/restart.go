@@ -0,0 +1,67 @@
+package steam
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"time"
+)
+
+// ErrSteamNotFound indicates that the Steam executable could not be found
+var ErrSteamNotFound = errors.New("steam executable not found")
+
+// ShutdownTimeout is how long to wait for Steam to exit after asking it to
+// shut down.
+var ShutdownTimeout = 30 * time.Second
+
+// Restart will shut down Steam if it is running and start it again, so that
+// it picks up changes to shortcuts and artwork. If Steam is not running, it is
+// just started.
+func Restart() error {
+	steamPath, err := findSteamExecutable()
+	if err != nil {
+		return err
+	}
+
+	// Ask Steam to shut down and wait for it to exit
+	if IsRunning() {
+		if err := exec.Command(steamPath, "-shutdown").Run(); err != nil {
+			return fmt.Errorf("failed to shut down Steam: %w", err)
+		}
+		deadline := time.Now().Add(ShutdownTimeout)
+		for IsRunning() {
+			if time.Now().After(deadline) {
+				return fmt.Errorf("timed out waiting for Steam to shut down")
+			}
+			time.Sleep(500 * time.Millisecond)
+		}
+	}
+
+	return start(steamPath)
+}
+
+// Start will start Steam in the background
+func Start() error {
+	steamPath, err := findSteamExecutable()
+	if err != nil {
+		return err
+	}
+	return start(steamPath)
+}
+
+// start will launch the given Steam executable detached from this process
+func start(steamPath string) error {
+	cmd := exec.Command(steamPath)
+	cmd.SysProcAttr = detachedProcAttr()
+	devNull, err := os.Open(os.DevNull)
+	if err != nil {
+		return err
+	}
+	defer devNull.Close()
+	cmd.Stdin = devNull
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start Steam: %w", err)
+	}
+	return cmd.Process.Release()
+}
@@ -0,0 +1,48 @@
+//go:build !windows
+
+package steam
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// IsRunning will return whether or not Steam is currently running, based on
+// the PID file Steam writes on startup.
+func IsRunning() bool {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return false
+	}
+	data, err := os.ReadFile(path.Join(home, ".steam", "steam.pid"))
+	if err != nil {
+		return false
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil || pid <= 0 {
+		return false
+	}
+
+	// Signal 0 only checks that the process exists
+	return syscall.Kill(pid, 0) == nil
+}
+
+// findSteamExecutable will return the path to the steam executable
+func findSteamExecutable() (string, error) {
+	steamPath, err := exec.LookPath("steam")
+	if err != nil {
+		return "", fmt.Errorf("%w: 'steam' is not in PATH", ErrSteamNotFound)
+	}
+	return steamPath, nil
+}
+
+// detachedProcAttr will return the process attributes used to start Steam in
+// its own session, so it keeps running after we exit.
+func detachedProcAttr() *syscall.SysProcAttr {
+	return &syscall.SysProcAttr{Setsid: true}
+}
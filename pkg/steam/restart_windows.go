@@ -0,0 +1,46 @@
+//go:build windows
+
+package steam
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"syscall"
+
+	"golang.org/x/sys/windows/registry"
+)
+
+// IsRunning will return whether or not Steam is currently running, based on
+// the active process ID Steam stores in the registry.
+func IsRunning() bool {
+	key, err := registry.OpenKey(registry.CURRENT_USER, `Software\Valve\Steam\ActiveProcess`, registry.QUERY_VALUE)
+	if err != nil {
+		return false
+	}
+	defer key.Close()
+
+	pid, _, err := key.GetIntegerValue("pid")
+	return err == nil && pid != 0
+}
+
+// findSteamExecutable will return the path to steam.exe in the Steam install
+// directory.
+func findSteamExecutable() (string, error) {
+	steamDir, err := GetBaseDir()
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrSteamNotFound, err)
+	}
+	steamPath := filepath.Join(steamDir, "steam.exe")
+	if _, err := os.Stat(steamPath); err != nil {
+		return "", fmt.Errorf("%w: %v", ErrSteamNotFound, steamPath)
+	}
+	return steamPath, nil
+}
+
+// detachedProcAttr will return the process attributes used to start Steam
+// detached from our console.
+func detachedProcAttr() *syscall.SysProcAttr {
+	const detachedProcess = 0x00000008
+	return &syscall.SysProcAttr{CreationFlags: detachedProcess}
+}
@@ -0,0 +1,112 @@
+package steam
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"os"
+
+	"github.com/shadowblip/steam-shortcut-manager/pkg/shortcut"
+)
+
+// AssetInfo describes a single artwork image of a shortcut in the grid folder
+type AssetInfo struct {
+	Present bool   `json:"present"`
+	Path    string `json:"path,omitempty"`
+	Width   int    `json:"width,omitempty"`
+	Height  int    `json:"height,omitempty"`
+	Corrupt bool   `json:"corrupt,omitempty"`
+}
+
+// AssetPresence reports which artwork of a shortcut exists in the grid folder
+type AssetPresence struct {
+	AppName   string    `json:"name"`
+	Portrait  AssetInfo `json:"portrait"`
+	Landscape AssetInfo `json:"landscape"`
+	Hero      AssetInfo `json:"hero"`
+	Logo      AssetInfo `json:"logo"`
+	Icon      AssetInfo `json:"icon"`
+}
+
+// Missing will return the names of the artwork types that do not exist or
+// are corrupt
+func (a *AssetPresence) Missing() []string {
+	missing := []string{}
+	assets := []struct {
+		name string
+		info AssetInfo
+	}{
+		{"portrait", a.Portrait},
+		{"landscape", a.Landscape},
+		{"hero", a.Hero},
+		{"logo", a.Logo},
+		{"icon", a.Icon},
+	}
+	for _, asset := range assets {
+		if !asset.info.Present || asset.info.Corrupt {
+			missing = append(missing, asset.name)
+		}
+	}
+	return missing
+}
+
+// ArtworkStatus will report which artwork exists in the grid folder for each
+// shortcut of the given user, keyed by the shortcut's app ID. The pixel
+// dimensions of every image that can be decoded are included. A user without
+// shortcuts has an empty status.
+func ArtworkStatus(user string) (map[string]AssetPresence, error) {
+	status := map[string]AssetPresence{}
+	if !HasShortcuts(user) {
+		return status, nil
+	}
+	shortcutsPath, err := GetShortcutsPath(user)
+	if err != nil {
+		return nil, err
+	}
+	shortcuts, err := shortcut.Load(shortcutsPath)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, key := range shortcuts.Keys() {
+		sc := shortcuts.Shortcuts[key]
+		appID := fmt.Sprintf("%d", uint32(sc.Appid))
+		lookup := func(getImage func(user, appId string) (string, error)) AssetInfo {
+			return assetInfo(getImage(user, appID))
+		}
+		status[appID] = AssetPresence{
+			AppName:   sc.AppName,
+			Portrait:  lookup(GetImagePortrait),
+			Landscape: lookup(GetImageLandscape),
+			Hero:      lookup(GetImageHero),
+			Logo:      lookup(GetImageLogo),
+			Icon:      lookup(GetImageIcon),
+		}
+	}
+
+	return status, nil
+}
+
+// assetInfo will describe the image found by one of the GetImage* functions
+func assetInfo(imgPath string, err error) AssetInfo {
+	if errors.Is(err, ErrImageNotFound) || imgPath == "" {
+		return AssetInfo{}
+	}
+	info := AssetInfo{Present: true, Path: imgPath, Corrupt: errors.Is(err, ErrImageCorrupt)}
+	if info.Corrupt {
+		return info
+	}
+
+	// Only some formats can be decoded, so the size of the others is unknown
+	file, err := os.Open(imgPath)
+	if err != nil {
+		return info
+	}
+	defer file.Close()
+	if config, _, err := image.DecodeConfig(file); err == nil {
+		info.Width = config.Width
+		info.Height = config.Height
+	}
+
+	return info
+}
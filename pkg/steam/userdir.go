@@ -4,11 +4,55 @@ package steam
 
 import (
 	"errors"
+	"fmt"
 	"io/ioutil"
 	"os"
 	"path"
+	"path/filepath"
+
+	"github.com/shadowblip/steam-shortcut-manager/pkg/shortcut"
 )
 
+// ErrNoSteamDir indicates that no Steam installation could be found
+var ErrNoSteamDir = errors.New("Steam installation not found")
+
+// ErrNoUsers indicates that the Steam installation has no users, for example
+// because nobody has logged into Steam yet
+var ErrNoUsers = errors.New("no Steam users found")
+
+// SteamRootEnv is the environment variable that can be used to override the
+// detected Steam directory
+const SteamRootEnv = "STEAM_ROOT"
+
+// baseDirOverride is the Steam directory set with SetBaseDir
+var baseDirOverride string
+
+// SetBaseDir will use the given Steam directory instead of detecting it. The
+// directory must contain a userdata folder. An empty directory restores the
+// detection.
+func SetBaseDir(dir string) error {
+	if dir == "" {
+		baseDirOverride = ""
+		return nil
+	}
+	userDir := filepath.Join(dir, "userdata")
+	if info, err := os.Stat(userDir); err != nil || !info.IsDir() {
+		return fmt.Errorf("%w: %v has no userdata directory", ErrNoSteamDir, dir)
+	}
+	baseDirOverride = filepath.Clean(dir)
+	return nil
+}
+
+// GetBaseDir will return the base steam config directory. The directory set
+// with SetBaseDir is used if there is one, otherwise the default install is
+// detected.
+func GetBaseDir() (string, error) {
+	if baseDirOverride != "" {
+		return baseDirOverride, nil
+	}
+	return detectBaseDir()
+}
+
 // GetSteamUserDir will return the steam userdata directory
 func GetUserDir() (string, error) {
 	steamDir, err := GetBaseDir()
@@ -27,6 +71,9 @@ func GetUsers() ([]string, error) {
 	}
 
 	files, err := ioutil.ReadDir(userDir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %v does not exist", ErrNoSteamDir, userDir)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -53,6 +100,50 @@ func GetShortcutsPath(user string) (string, error) {
 	return path.Join(userDir, user, "config", "shortcuts.vdf"), nil
 }
 
+// GetUsersWithShortcuts will return the IDs of the Steam users that have a
+// shortcuts file
+func GetUsersWithShortcuts() ([]string, error) {
+	users, err := GetUsers()
+	if err != nil {
+		return nil, err
+	}
+
+	withShortcuts := []string{}
+	for _, user := range users {
+		if HasShortcuts(user) {
+			withShortcuts = append(withShortcuts, user)
+		}
+	}
+
+	return withShortcuts, nil
+}
+
+// GetShortcutOwners will return the IDs of the Steam users that have a
+// shortcut with the given app ID
+func GetShortcutOwners(appID uint64) ([]string, error) {
+	users, err := GetUsersWithShortcuts()
+	if err != nil {
+		return nil, err
+	}
+
+	owners := []string{}
+	for _, user := range users {
+		shortcutsPath, _ := GetShortcutsPath(user)
+		shortcuts, err := shortcut.Load(shortcutsPath)
+		if err != nil {
+			return nil, fmt.Errorf("unable to load shortcuts for user %v: %w", user, err)
+		}
+		for _, sc := range shortcuts.Shortcuts {
+			if uint32(sc.Appid) == uint32(appID) {
+				owners = append(owners, user)
+				break
+			}
+		}
+	}
+
+	return owners, nil
+}
+
 // Whether or not the user has a shortcuts file
 func HasShortcuts(user string) bool {
 	shortcutsPath, err := GetShortcutsPath(user)
@@ -9,11 +9,28 @@ import (
 	"path"
 )
 
-// GetBaseDir will return the base steam config directory
-func GetBaseDir() (string, error) {
+// detectBaseDir will return the base steam config directory of the default
+// install
+func detectBaseDir() (string, error) {
 	dirname, err := os.UserHomeDir()
 	if err != nil {
 		return "", err
 	}
 	return path.Join(dirname, ".steam", "steam"), nil
 }
+
+// candidateBaseDirs will return the directories Steam is commonly installed
+// to, starting with the one detectBaseDir uses
+func candidateBaseDirs() []CandidateDir {
+	dirname, err := os.UserHomeDir()
+	if err != nil {
+		return []CandidateDir{}
+	}
+	return []CandidateDir{
+		{Path: path.Join(dirname, ".steam", "steam"), Source: "default"},
+		{Path: path.Join(dirname, ".steam", "root"), Source: "default root link"},
+		{Path: path.Join(dirname, ".local", "share", "Steam"), Source: "native"},
+		{Path: path.Join(dirname, ".var", "app", "com.valvesoftware.Steam", ".local", "share", "Steam"), Source: "Flatpak"},
+		{Path: path.Join(dirname, "snap", "steam", "common", ".local", "share", "Steam"), Source: "Snap"},
+	}
+}
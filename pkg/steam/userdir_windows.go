@@ -5,29 +5,93 @@ package steam
 // Autor: Matias Galarza (Lobinux)
 
 import (
-	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 
 	"golang.org/x/sys/windows/registry"
 )
 
-// GetBaseDir will return the base steam config directory
-func GetBaseDir() (string, error) {
-	// We check in two different locations for the key
-	// The first one is for 64-bit systems
-	key, err := registry.OpenKey(registry.LOCAL_MACHINE, `SOFTWARE\Wow6432Node\Valve\Steam`, registry.QUERY_VALUE)
-	if err != nil {
-		// If it fails, we check the 32-bit key
-		key, err = registry.OpenKey(registry.LOCAL_MACHINE, `SOFTWARE\Valve\Steam`, registry.QUERY_VALUE)
+// steamRegistryKey is a registry location where Steam may record its install
+// directory.
+type steamRegistryKey struct {
+	root  registry.Key
+	name  string
+	path  string
+	value string
+}
+
+// String will return the full name of the registry value
+func (k steamRegistryKey) String() string {
+	return fmt.Sprintf(`%s\%s\%s`, k.name, k.path, k.value)
+}
+
+// steamRegistryKeys are the registry locations checked for the Steam install
+// directory, in order. The machine keys are written by the installer, while
+// the user key is also available for non-admin installs.
+var steamRegistryKeys = []steamRegistryKey{
+	// 64-bit systems
+	{registry.LOCAL_MACHINE, "HKLM", `SOFTWARE\Wow6432Node\Valve\Steam`, "InstallPath"},
+	// 32-bit systems
+	{registry.LOCAL_MACHINE, "HKLM", `SOFTWARE\Valve\Steam`, "InstallPath"},
+	{registry.CURRENT_USER, "HKCU", `Software\Valve\Steam`, "SteamPath"},
+}
+
+// detectBaseDir will return the base steam config directory of the default
+// install
+func detectBaseDir() (string, error) {
+	probed := []string{}
+	for _, k := range steamRegistryKeys {
+		steamPath, err := readRegistryString(k)
 		if err != nil {
-			return "", errors.New("cannot find steam registry key")
+			probed = append(probed, fmt.Sprintf("%v (%v)", k, err))
+			continue
+		}
+
+		// The registry can point at a stale install, so make sure this is
+		// actually a Steam directory.
+		steamPath = filepath.Clean(steamPath)
+		if info, err := os.Stat(filepath.Join(steamPath, "userdata")); err != nil || !info.IsDir() {
+			probed = append(probed, fmt.Sprintf("%v (%v has no userdata directory)", k, steamPath))
+			continue
 		}
+
+		return steamPath, nil
+	}
+
+	return "", fmt.Errorf("%w: cannot find steam registry key, tried %s", ErrNoSteamDir, strings.Join(probed, ", "))
+}
+
+// readRegistryString will read the given string value from the registry
+func readRegistryString(k steamRegistryKey) (string, error) {
+	key, err := registry.OpenKey(k.root, k.path, registry.QUERY_VALUE)
+	if err != nil {
+		return "", err
 	}
 	defer key.Close()
 
-	steamPath, _, err := key.GetStringValue("InstallPath")
+	value, _, err := key.GetStringValue(k.value)
 	if err != nil {
 		return "", err
 	}
 
-	return steamPath, nil
+	return value, nil
+}
+
+// candidateBaseDirs will return the directories recorded in the registry,
+// followed by the default install directory
+func candidateBaseDirs() []CandidateDir {
+	candidates := []CandidateDir{}
+	for _, k := range steamRegistryKeys {
+		steamPath, err := readRegistryString(k)
+		if err != nil {
+			continue
+		}
+		candidates = append(candidates, CandidateDir{Path: filepath.Clean(steamPath), Source: k.String()})
+	}
+	if programFiles := os.Getenv("ProgramFiles(x86)"); programFiles != "" {
+		candidates = append(candidates, CandidateDir{Path: filepath.Join(programFiles, "Steam"), Source: "default"})
+	}
+	return candidates
 }
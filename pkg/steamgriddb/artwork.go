@@ -1,74 +1,527 @@
 package steamgriddb
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"net"
 
+	multierror "github.com/hashicorp/go-multierror"
+	"github.com/shadowblip/steam-shortcut-manager/pkg/httpclient"
 	"github.com/shadowblip/steam-shortcut-manager/pkg/steam"
+	"golang.org/x/sync/errgroup"
 )
 
-// FetchArtworkConfig fetches artwork URLs from SteamGridDB for a given game ID
-// and returns them as a steam.ArtworkConfig ready to apply
-func (c *Client) FetchArtworkConfig(gameID string) (*steam.ArtworkConfig, error) {
+// ArtworkCandidates holds every artwork candidate returned by SteamGridDB for
+// a game, grouped by asset type.
+type ArtworkCandidates struct {
+	GridPortrait  []GridResponseData
+	GridLandscape []GridResponseData
+	Hero          []ImageResponseData
+	Logo          []ImageResponseData
+	Icon          []ImageResponseData
+
+	// Errors holds the error of every lookup that failed, in asset type
+	// order.
+	Errors []*steam.AssetError
+}
+
+// ArtworkSelection selects which candidate to use for each asset type by its
+// index in ArtworkCandidates. The zero value selects the first candidate of
+// every type. A negative index skips that asset type.
+type ArtworkSelection struct {
+	GridPortrait  int
+	GridLandscape int
+	Hero          int
+	Logo          int
+	Icon          int
+}
+
+// Strategy is the way the candidate of each asset type is chosen
+type Strategy string
+
+const (
+	// StrategyFirstResult chooses the first candidate in SteamGridDB's order
+	StrategyFirstResult Strategy = ""
+	// StrategyHighestVotes chooses the candidate with the most upvotes,
+	// using the score as a tiebreaker
+	StrategyHighestVotes Strategy = "votes"
+	// StrategyLargestDimensions chooses the candidate with the most pixels
+	StrategyLargestDimensions Strategy = "largest"
+)
+
+// ParseStrategy will parse a strategy given as "first", "votes" or "largest"
+func ParseStrategy(strategy string) (Strategy, error) {
+	switch strategy {
+	case "", "first":
+		return StrategyFirstResult, nil
+	case string(StrategyHighestVotes):
+		return StrategyHighestVotes, nil
+	case string(StrategyLargestDimensions):
+		return StrategyLargestDimensions, nil
+	}
+	return "", fmt.Errorf("invalid strategy '%s' (valid values: first, votes, largest)", strategy)
+}
+
+// ArtworkPreference controls which candidate is chosen for each asset type
+type ArtworkPreference struct {
+	// PreferAnimated will only choose among the animated candidates of each
+	// asset type if there are any, and among the static ones otherwise.
+	PreferAnimated bool
+
+	// Strategy is the way the candidate is chosen. If empty, the client's
+	// strategy is used by the Client methods.
+	Strategy Strategy
+}
+
+// Candidate is an artwork candidate of any asset type, with the metadata used
+// to choose between candidates
+type Candidate struct {
+	ID        int    `json:"id"`
+	URL       string `json:"url"`
+	Thumb     string `json:"thumb"`
+	Mime      string `json:"mime"`
+	Style     string `json:"style"`
+	Width     int    `json:"width"`
+	Height    int    `json:"height"`
+	Score     int    `json:"score"`
+	Upvotes   int    `json:"upvotes"`
+	Downvotes int    `json:"downvotes"`
+	Animated  bool   `json:"animated"`
+}
+
+// better will return whether or not candidate a is preferred over candidate
+// b with the given strategy
+func (a Candidate) better(b Candidate, strategy Strategy) bool {
+	switch strategy {
+	case StrategyHighestVotes:
+		if a.Upvotes != b.Upvotes {
+			return a.Upvotes > b.Upvotes
+		}
+		return a.Score > b.Score
+	case StrategyLargestDimensions:
+		return a.Width*a.Height > b.Width*b.Height
+	}
+	return false
+}
+
+// FetchArtworkCandidates fetches all artwork candidates from SteamGridDB for
+// the given game ID so a caller can choose which ones to apply. The lookups
+// run in parallel. Lookups that fail are recorded in the Errors field of the
+// result instead of failing the whole fetch, unless SteamGridDB could not be
+// used at all because the API key was rejected or the network is down.
+func (c *Client) FetchArtworkCandidates(gameID string) (*ArtworkCandidates, error) {
+	return c.FetchArtworkCandidatesFor(gameID, steam.AllAssetTypes...)
+}
+
+// FetchArtworkCandidatesFor is like FetchArtworkCandidates, but only looks up
+// the candidates of the given asset types. The candidates of every other type
+// are left empty.
+func (c *Client) FetchArtworkCandidatesFor(gameID string, types ...steam.AssetType) (*ArtworkCandidates, error) {
+	return c.fetchCandidates(gameID, false, types)
+}
+
+// BrowseArtwork fetches every artwork candidate SteamGridDB has for the given
+// game ID, paging through all of the results of each asset type, so a user
+// can pick the artwork to apply. Use ArtworkCandidates.List to get the
+// candidates of an asset type with their thumbnails and metadata. Failed
+// lookups are handled like FetchArtworkCandidates does.
+func (c *Client) BrowseArtwork(gameID string) (*ArtworkCandidates, error) {
+	return c.fetchCandidates(gameID, true, steam.AllAssetTypes)
+}
+
+// fetchCandidates will look up the artwork candidates of the given asset
+// types, from every page of results if all is set and from the first page
+// otherwise
+func (c *Client) fetchCandidates(gameID string, all bool, types []steam.AssetType) (*ArtworkCandidates, error) {
+	if err := c.context().Err(); err != nil {
+		return nil, err
+	}
+	candidates := &ArtworkCandidates{}
+
+	getGrids := c.GetGrids
+	getHeroes := func(gameID string) (*HeroesResponse, error) {
+		return c.GetHeroes(gameID)
+	}
+	getLogos := func(gameID string) (*HeroesResponse, error) {
+		logos, err := c.GetLogos(gameID)
+		return (*HeroesResponse)(logos), err
+	}
+	getIcons := func(gameID string) (*HeroesResponse, error) {
+		icons, err := c.GetIcons(gameID)
+		return (*HeroesResponse)(icons), err
+	}
+	if all {
+		getGrids = c.AllGrids
+		getHeroes = func(gameID string) (*HeroesResponse, error) {
+			return allImagePages(func(query []QueryFilter) (*HeroesResponse, error) {
+				return c.GetHeroesWithQuery(gameID, query)
+			})
+		}
+		getLogos = func(gameID string) (*HeroesResponse, error) {
+			return allImagePages(func(query []QueryFilter) (*HeroesResponse, error) {
+				logos, err := c.GetLogosWithQuery(gameID, query)
+				return (*HeroesResponse)(logos), err
+			})
+		}
+		getIcons = func(gameID string) (*HeroesResponse, error) {
+			return allImagePages(func(query []QueryFilter) (*HeroesResponse, error) {
+				icons, err := c.GetIconsWithQuery(gameID, query)
+				return (*HeroesResponse)(icons), err
+			})
+		}
+	}
+
+	lookups := map[steam.AssetType]func() error{
+		// Fetch portrait grids (600x900)
+		steam.AssetTypeGridPortrait: func() error {
+			grids, err := getGrids(gameID, FilterGridVertical())
+			if err == nil {
+				candidates.GridPortrait = grids.Data
+			}
+			return err
+		},
+		// Fetch landscape grids (920x430)
+		steam.AssetTypeGridLandscape: func() error {
+			grids, err := getGrids(gameID, FilterGridHorizontal())
+			if err == nil {
+				candidates.GridLandscape = grids.Data
+			}
+			return err
+		},
+		// Fetch heroes
+		steam.AssetTypeHero: func() error {
+			heroes, err := getHeroes(gameID)
+			if err == nil {
+				candidates.Hero = heroes.Data
+			}
+			return err
+		},
+		// Fetch logos
+		steam.AssetTypeLogo: func() error {
+			logos, err := getLogos(gameID)
+			if err == nil {
+				candidates.Logo = logos.Data
+			}
+			return err
+		},
+		// Fetch icons
+		steam.AssetTypeIcon: func() error {
+			icons, err := getIcons(gameID)
+			if err == nil {
+				candidates.Icon = icons.Data
+			}
+			return err
+		},
+	}
+
+	// Run the lookups with a bounded number of parallel requests
+	results := make([]error, len(types))
+	group := new(errgroup.Group)
+	group.SetLimit(c.getConcurrency())
+	for i, assetType := range types {
+		i, lookup := i, lookups[assetType]
+		if lookup == nil {
+			continue
+		}
+		group.Go(func() error {
+			results[i] = lookup()
+			return nil
+		})
+	}
+	group.Wait()
+	if err := c.context().Err(); err != nil {
+		return nil, err
+	}
+
+	// Collect the errors in a stable order
+	for i, err := range results {
+		if err == nil {
+			continue
+		}
+		if isFatalError(err) {
+			return nil, err
+		}
+		candidates.Errors = append(candidates.Errors, &steam.AssetError{AssetType: types[i], Err: err})
+	}
+
+	return candidates, nil
+}
+
+// isFatalError will return whether or not the given lookup error means that
+// no lookup can succeed, so the fetch should fail instead of coming back
+// empty
+func isFatalError(err error) bool {
+	if errors.Is(err, ErrInvalidAPIKey) || errors.Is(err, httpclient.ErrOffline) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// Err will return the errors of the lookups that failed as a multierror, or
+// nil if every lookup succeeded
+func (a *ArtworkCandidates) Err() error {
+	var errs error
+	for _, err := range a.Errors {
+		errs = multierror.Append(errs, err)
+	}
+	return errs
+}
+
+// FetchArtworkCandidatesContext is like FetchArtworkCandidates, but stops
+// when the given context is cancelled.
+func (c *Client) FetchArtworkCandidatesContext(ctx context.Context, gameID string) (*ArtworkCandidates, error) {
+	return c.WithContext(ctx).FetchArtworkCandidates(gameID)
+}
+
+// Config will return a steam.ArtworkConfig using the selected candidates.
+// Asset types whose selected index is out of range are left empty.
+func (a *ArtworkCandidates) Config(selection ArtworkSelection) *steam.ArtworkConfig {
 	config := &steam.ArtworkConfig{}
+	if i := selection.GridPortrait; i >= 0 && i < len(a.GridPortrait) {
+		config.GridPortrait = a.GridPortrait[i].URL
+	}
+	if i := selection.GridLandscape; i >= 0 && i < len(a.GridLandscape) {
+		config.GridLandscape = a.GridLandscape[i].URL
+	}
+	if i := selection.Hero; i >= 0 && i < len(a.Hero) {
+		config.HeroImage = a.Hero[i].URL
+	}
+	if i := selection.Logo; i >= 0 && i < len(a.Logo) {
+		config.LogoImage = a.Logo[i].URL
+	}
+	if i := selection.Icon; i >= 0 && i < len(a.Icon) {
+		config.IconImage = a.Icon[i].URL
+	}
+	return config
+}
 
-	// Fetch portrait grid (600x900)
-	gridsPortrait, err := c.GetGrids(gameID, FilterGridVertical())
-	if err == nil && len(gridsPortrait.Data) > 0 {
-		config.GridPortrait = gridsPortrait.Data[0].URL
+// List will return the candidates of the given asset type in SteamGridDB's
+// order. The index of a candidate is its index in an ArtworkSelection.
+func (a *ArtworkCandidates) List(assetType steam.AssetType) []Candidate {
+	fromGrids := func(data []GridResponseData) []Candidate {
+		candidates := make([]Candidate, 0, len(data))
+		for _, item := range data {
+			candidates = append(candidates, Candidate{
+				ID:        item.ID,
+				URL:       item.URL,
+				Thumb:     item.Thumb,
+				Mime:      item.Mime,
+				Style:     item.Style,
+				Width:     item.Width,
+				Height:    item.Height,
+				Score:     item.Score,
+				Upvotes:   item.Upvotes,
+				Downvotes: item.Downvotes,
+				Animated:  isAnimatedMime(item.Mime),
+			})
+		}
+		return candidates
+	}
+	fromImages := func(data []ImageResponseData) []Candidate {
+		candidates := make([]Candidate, 0, len(data))
+		for _, item := range data {
+			candidates = append(candidates, Candidate{
+				ID:        item.ID,
+				URL:       item.URL,
+				Thumb:     item.Thumb,
+				Mime:      item.Mime,
+				Style:     item.Style,
+				Width:     item.Width,
+				Height:    item.Height,
+				Score:     item.Score,
+				Upvotes:   item.Upvotes,
+				Downvotes: item.Downvotes,
+				Animated:  isAnimatedMime(item.Mime),
+			})
+		}
+		return candidates
 	}
 
-	// Fetch landscape grid (920x430)
-	gridsLandscape, err := c.GetGrids(gameID, FilterGridHorizontal())
-	if err == nil && len(gridsLandscape.Data) > 0 {
-		config.GridLandscape = gridsLandscape.Data[0].URL
+	switch assetType {
+	case steam.AssetTypeGridPortrait:
+		return fromGrids(a.GridPortrait)
+	case steam.AssetTypeGridLandscape:
+		return fromGrids(a.GridLandscape)
+	case steam.AssetTypeHero:
+		return fromImages(a.Hero)
+	case steam.AssetTypeLogo:
+		return fromImages(a.Logo)
+	case steam.AssetTypeIcon:
+		return fromImages(a.Icon)
 	}
+	return []Candidate{}
+}
+
+// Select will return the selection of candidates that matches the given
+// preference, along with the asset types for which an animated candidate was
+// selected.
+func (a *ArtworkCandidates) Select(pref ArtworkPreference) (ArtworkSelection, []steam.AssetType) {
+	selection := ArtworkSelection{}
+	animated := []steam.AssetType{}
+
+	choose := func(assetType steam.AssetType) int {
+		candidates := a.List(assetType)
 
-	// Fetch hero
-	heroes, err := c.GetHeroes(gameID)
-	if err == nil && len(heroes.Data) > 0 {
-		config.HeroImage = heroes.Data[0].URL
+		// Only consider the animated candidates if animation is preferred
+		// and there are any
+		onlyAnimated := false
+		if pref.PreferAnimated {
+			for _, c := range candidates {
+				if c.Animated {
+					onlyAnimated = true
+					break
+				}
+			}
+		}
+
+		i := -1
+		for j, c := range candidates {
+			if onlyAnimated && !c.Animated {
+				continue
+			}
+			if i == -1 || c.better(candidates[i], pref.Strategy) {
+				i = j
+			}
+		}
+		if i == -1 {
+			return 0
+		}
+		if candidates[i].Animated {
+			animated = append(animated, assetType)
+		}
+		return i
 	}
 
-	// Fetch logo
-	logos, err := c.GetLogos(gameID)
-	if err == nil && len(logos.Data) > 0 {
-		config.LogoImage = logos.Data[0].URL
+	selection.GridPortrait = choose(steam.AssetTypeGridPortrait)
+	selection.Hero = choose(steam.AssetTypeHero)
+	selection.Logo = choose(steam.AssetTypeLogo)
+	selection.GridLandscape = choose(steam.AssetTypeGridLandscape)
+	selection.Icon = choose(steam.AssetTypeIcon)
+
+	return selection, animated
+}
+
+// isAnimatedMime will return whether or not SteamGridDB uses the given mime
+// type for animated artwork.
+func isAnimatedMime(mime string) bool {
+	return mime == "image/webp" || mime == "image/gif"
+}
+
+// FetchArtworkConfig fetches artwork URLs from SteamGridDB for a given game ID
+// and returns them as a steam.ArtworkConfig ready to apply. The candidate of
+// each asset type is chosen with the client's strategy, which uses the first
+// candidate by default. If the lookups of some asset types fail, the artwork
+// that was found is returned along with the errors of the failed lookups.
+func (c *Client) FetchArtworkConfig(gameID string) (*steam.ArtworkConfig, error) {
+	if c.strategy == StrategyFirstResult {
+		return c.FetchArtworkConfigWithSelection(gameID, ArtworkSelection{})
 	}
+	config, _, err := c.FetchArtworkConfigWithPreference(gameID, ArtworkPreference{})
+	return config, err
+}
 
-	// Fetch icon
-	icons, err := c.GetIcons(gameID)
-	if err == nil && len(icons.Data) > 0 {
-		config.IconImage = icons.Data[0].URL
+// FetchArtworkConfigContext is like FetchArtworkConfig, but stops when the
+// given context is cancelled.
+func (c *Client) FetchArtworkConfigContext(ctx context.Context, gameID string) (*steam.ArtworkConfig, error) {
+	return c.WithContext(ctx).FetchArtworkConfig(gameID)
+}
+
+// FetchArtworkConfigWithSelection fetches artwork from SteamGridDB for a given
+// game ID and returns the selected candidates as a steam.ArtworkConfig. See
+// FetchArtworkConfig for how failed lookups are returned.
+func (c *Client) FetchArtworkConfigWithSelection(gameID string, selection ArtworkSelection) (*steam.ArtworkConfig, error) {
+	candidates, err := c.FetchArtworkCandidates(gameID)
+	if err != nil {
+		return nil, err
 	}
+	return candidates.Config(selection), candidates.Err()
+}
 
-	return config, nil
+// FetchArtworkConfigWithPreference fetches artwork from SteamGridDB for a
+// given game ID and returns the candidates matching the given preference as a
+// steam.ArtworkConfig. The asset types that ended up animated are also
+// returned. See FetchArtworkConfig for how failed lookups are returned.
+func (c *Client) FetchArtworkConfigWithPreference(gameID string, pref ArtworkPreference) (*steam.ArtworkConfig, []steam.AssetType, error) {
+	candidates, err := c.FetchArtworkCandidates(gameID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if pref.Strategy == StrategyFirstResult {
+		pref.Strategy = c.strategy
+	}
+	selection, animated := candidates.Select(pref)
+	return candidates.Config(selection), animated, candidates.Err()
 }
 
 // ApplyArtwork fetches artwork from SteamGridDB and applies it to a Steam shortcut
 func (c *Client) ApplyArtwork(gameID string, appID uint64) error {
+	return c.ApplyArtworkWithOptions(gameID, appID, nil)
+}
+
+// ApplyArtworkWithOptions fetches artwork from SteamGridDB and applies it to a
+// Steam shortcut using the given options. The artwork that was found is
+// applied even if the lookups of some asset types fail, and their errors are
+// returned with the errors of applying it.
+func (c *Client) ApplyArtworkWithOptions(gameID string, appID uint64, opts *steam.ArtworkOptions) error {
 	config, err := c.FetchArtworkConfig(gameID)
+	if config == nil {
+		return fmt.Errorf("failed to fetch artwork config: %w", err)
+	}
+
+	return applyFetched(c.context(), appID, config, opts, err)
+}
+
+// applyFetched will apply the given fetched artwork to a Steam shortcut and
+// return the given fetch errors along with any errors applying it
+func applyFetched(ctx context.Context, appID uint64, config *steam.ArtworkConfig, opts *steam.ArtworkOptions, fetchErr error) error {
+	var errs error
+	if fetchErr != nil {
+		errs = multierror.Append(errs, fmt.Errorf("failed to fetch some artwork: %w", fetchErr))
+	}
+	if err := steam.SetArtworkContext(ctx, appID, config, opts); err != nil {
+		errs = multierror.Append(errs, err)
+	}
+	return errs
+}
+
+// ApplyArtworkSelective fetches artwork from SteamGridDB and applies it to a
+// Steam shortcut, but only for the given asset types. Artwork of every other
+// type, including files in the grid folder, is left untouched. If no types
+// are given, all artwork is applied.
+func (c *Client) ApplyArtworkSelective(gameID string, appID uint64, types ...steam.AssetType) error {
+	if len(types) == 0 {
+		return c.ApplyArtwork(gameID, appID)
+	}
+	candidates, err := c.FetchArtworkCandidatesFor(gameID, types...)
 	if err != nil {
 		return fmt.Errorf("failed to fetch artwork config: %w", err)
 	}
 
-	return steam.SetArtwork(appID, config)
+	return applyFetched(c.context(), appID, candidates.Config(ArtworkSelection{}), nil, candidates.Err())
+}
+
+// ApplyArtworkBySteamAppID fetches artwork for the game with the given Steam
+// app ID and applies it to a Steam shortcut. SteamGridDB is asked first and
+// any asset types it has no artwork for are taken from the Steam CDN.
+func (c *Client) ApplyArtworkBySteamAppID(steamAppID, appID uint64) error {
+	return c.applyFromChain(steam.ArtworkQuery{SteamAppID: steamAppID}, appID)
 }
 
 // SearchAndApplyArtwork searches SteamGridDB for a game by name, then fetches
-// and applies artwork to a Steam shortcut
+// and applies artwork to a Steam shortcut. See ArtworkProvider.
 func (c *Client) SearchAndApplyArtwork(gameName string, appID uint64) error {
-	// Search for the game
-	results, err := c.Search(gameName)
-	if err != nil {
-		return fmt.Errorf("failed to search for game: %w", err)
-	}
+	return c.applyFromChain(steam.ArtworkQuery{Name: gameName}, appID)
+}
 
-	if len(results.Data) == 0 {
-		return fmt.Errorf("no games found for '%s'", gameName)
+// applyFromChain will fetch artwork for the given query from the provider
+// chain of the client and apply it to a Steam shortcut
+func (c *Client) applyFromChain(query steam.ArtworkQuery, appID uint64) error {
+	config, err := c.ArtworkProvider().FetchArtworkConfig(query)
+	if err != nil {
+		return fmt.Errorf("failed to fetch artwork config: %w", err)
 	}
 
-	// Use the first result
-	gameID := fmt.Sprintf("%d", results.Data[0].ID)
-
-	return c.ApplyArtwork(gameID, appID)
+	return steam.SetArtworkContext(c.context(), appID, config, nil)
 }
@@ -0,0 +1,110 @@
+package steamgriddb
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/shadowblip/steam-shortcut-manager/pkg/fsutil"
+)
+
+// DefaultCacheTTL is how long cached SteamGridDB API responses are reused
+// before they are fetched again.
+const DefaultCacheTTL = 24 * time.Hour
+
+// DefaultCacheDir will return the default directory SteamGridDB API responses
+// are cached in.
+func DefaultCacheDir() (string, error) {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(cacheDir, "steam-shortcut-manager", "api"), nil
+}
+
+// WithCache will return an option that caches API responses in the given
+// directory for the given duration. Search and artwork listings are served
+// from the cache until they expire, so repeated runs do not hit the API.
+func WithCache(dir string, ttl time.Duration) Option {
+	return func(c *Client) {
+		c.SetCache(dir, ttl)
+	}
+}
+
+// SetCache will cache API responses in the given directory for the given
+// duration. An empty directory or a duration of zero or less disables the
+// cache.
+func (c *Client) SetCache(dir string, ttl time.Duration) {
+	if dir == "" || ttl <= 0 {
+		c.cache = nil
+		return
+	}
+	c.cache = &responseCache{dir: dir, ttl: ttl}
+}
+
+// responseCache stores API response bodies on disk, keyed by request URL
+type responseCache struct {
+	dir string
+	ttl time.Duration
+}
+
+// path will return the cache file for the given URL
+func (r *responseCache) path(url string) string {
+	sum := sha256.Sum256([]byte(url))
+	return filepath.Join(r.dir, hex.EncodeToString(sum[:])+".json")
+}
+
+// get will return the cached body for the given URL if it has not expired
+func (r *responseCache) get(url string) ([]byte, bool) {
+	file := r.path(url)
+	info, err := os.Stat(file)
+	if err != nil || time.Since(info.ModTime()) > r.ttl {
+		return nil, false
+	}
+	body, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, false
+	}
+	return body, true
+}
+
+// put will store the given body for the given URL
+func (r *responseCache) put(url string, body []byte) error {
+	if err := os.MkdirAll(r.dir, 0755); err != nil {
+		return err
+	}
+	return fsutil.WriteFileAtomic(r.path(url), body, 0644)
+}
+
+// getJSON will return the body of the given SteamGridDB API endpoint, using
+// the response cache if one is configured.
+func (c *Client) getJSON(path string) ([]byte, error) {
+	url := c.getUrl(path)
+	if c.cache != nil {
+		if body, ok := c.cache.get(url); ok {
+			c.debug("Using cached response for " + url)
+			return body, nil
+		}
+	}
+
+	res, err := c.get(url, true)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.cache != nil {
+		if err := c.cache.put(url, body); err != nil {
+			c.debug("Unable to cache response: " + err.Error())
+		}
+	}
+
+	return body, nil
+}
@@ -1,5 +1,11 @@
 package steamgriddb
 
+import (
+	"net/url"
+	"strconv"
+	"strings"
+)
+
 // FilterGrid is a function signature for any function that will filter grid
 // results.
 type FilterGrid func(d *GridResponse) []GridResponseData
@@ -97,3 +103,88 @@ func FilterLogosStyle(style string) FilterLogos {
 		return data
 	}
 }
+
+// QueryFilter is a function that sets a SteamGridDB query parameter, so the
+// API filters the results before they are returned.
+type QueryFilter func(query url.Values)
+
+// FilterStyle will return a query filter that only returns artwork with one
+// of the given styles (e.g. "alternate", "blurred", "white_logo", "material",
+// "no_logo").
+func FilterStyle(styles ...string) QueryFilter {
+	return func(query url.Values) {
+		query.Set("styles", strings.Join(styles, ","))
+	}
+}
+
+// FilterMimes will return a query filter that only returns artwork with one
+// of the given mime types (e.g. "image/png", "image/jpeg", "image/webp").
+func FilterMimes(mimes ...string) QueryFilter {
+	return func(query url.Values) {
+		query.Set("mimes", strings.Join(mimes, ","))
+	}
+}
+
+// FilterDimensions will return a query filter that only returns artwork with
+// one of the given dimensions (e.g. "600x900", "920x430").
+func FilterDimensions(dimensions ...string) QueryFilter {
+	return func(query url.Values) {
+		query.Set("dimensions", strings.Join(dimensions, ","))
+	}
+}
+
+// FilterAnimated will return a query filter that only returns animated
+// artwork, or only static artwork if animated is false.
+func FilterAnimated(animated bool) QueryFilter {
+	return func(query url.Values) {
+		if animated {
+			query.Set("types", "animated")
+			return
+		}
+		query.Set("types", "static")
+	}
+}
+
+// FilterNSFW will return a query filter for adult content. Valid values are
+// "true", "false" and "any".
+func FilterNSFW(nsfw string) QueryFilter {
+	return func(query url.Values) {
+		query.Set("nsfw", nsfw)
+	}
+}
+
+// FilterHumor will return a query filter for humorous artwork. Valid values
+// are "true", "false" and "any".
+func FilterHumor(humor string) QueryFilter {
+	return func(query url.Values) {
+		query.Set("humor", humor)
+	}
+}
+
+// FilterPage will return a query filter that requests the given zero-based
+// page of results.
+func FilterPage(page int) QueryFilter {
+	return func(query url.Values) {
+		query.Set("page", strconv.Itoa(page))
+	}
+}
+
+// FilterLimit will return a query filter that sets the number of results
+// returned per page.
+func FilterLimit(limit int) QueryFilter {
+	return func(query url.Values) {
+		query.Set("limit", strconv.Itoa(limit))
+	}
+}
+
+// withQuery will return the given API path with the query filters applied
+func withQuery(path string, filters []QueryFilter) string {
+	if len(filters) == 0 {
+		return path
+	}
+	query := url.Values{}
+	for _, filter := range filters {
+		filter(query)
+	}
+	return path + "?" + query.Encode()
+}
@@ -0,0 +1,58 @@
+package steamgriddb
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestQueryFilterURLs(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter QueryFilter
+		want   string
+	}{
+		{"style", FilterStyle("alternate"), "/grids/game/1?styles=alternate"},
+		{"styles", FilterStyle("alternate", "blurred"), "/grids/game/1?styles=alternate%2Cblurred"},
+		{"mimes", FilterMimes("image/png"), "/grids/game/1?mimes=image%2Fpng"},
+		{"dimensions", FilterDimensions("600x900", "342x482"), "/grids/game/1?dimensions=600x900%2C342x482"},
+		{"animated", FilterAnimated(true), "/grids/game/1?types=animated"},
+		{"static", FilterAnimated(false), "/grids/game/1?types=static"},
+		{"nsfw", FilterNSFW("false"), "/grids/game/1?nsfw=false"},
+		{"humor", FilterHumor("false"), "/grids/game/1?humor=false"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := withQuery("/grids/game/1", []QueryFilter{test.filter}); got != test.want {
+				t.Errorf("withQuery() = %v, want %v", got, test.want)
+			}
+		})
+	}
+
+	if got := withQuery("/grids/game/1", nil); got != "/grids/game/1" {
+		t.Errorf("withQuery() without filters = %v, want /grids/game/1", got)
+	}
+}
+
+func TestGetGridsWithQueryComposesFilters(t *testing.T) {
+	var requested string
+	client := newTestClient(t, "key", func(w http.ResponseWriter, r *http.Request) {
+		requested = r.URL.RequestURI()
+		w.Write([]byte(`{"success":true,"data":[]}`))
+	})
+
+	query := []QueryFilter{
+		FilterStyle("alternate"),
+		FilterMimes("image/png"),
+		FilterDimensions("600x900"),
+		FilterAnimated(false),
+		FilterNSFW("false"),
+		FilterHumor("false"),
+	}
+	if _, err := client.GetGridsWithQuery("1234", query); err != nil {
+		t.Fatalf("GetGridsWithQuery() error = %v", err)
+	}
+	want := "/grids/game/1234?dimensions=600x900&humor=false&mimes=image%2Fpng&nsfw=false&styles=alternate&types=static"
+	if requested != want {
+		t.Errorf("requested %v, want %v", requested, want)
+	}
+}
@@ -1,6 +1,7 @@
 package steamgriddb
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -10,53 +11,201 @@ import (
 	"net/url"
 	"os"
 	"path/filepath"
+	"strings"
 
+	"github.com/shadowblip/steam-shortcut-manager/pkg/httpclient"
 	"github.com/shadowblip/steam-shortcut-manager/pkg/logger"
+	"github.com/shadowblip/steam-shortcut-manager/pkg/steam"
+	"golang.org/x/time/rate"
 )
 
 const BASE_URL = "https://www.steamgriddb.com/api/v2"
 
-var isDebug = os.Getenv("DEBUG") == "1"
+// APIKeyEnv is the environment variable the API key is read from if no key
+// is given to NewClient.
+const APIKeyEnv = "STEAMGRIDDB_API_KEY"
 
-// NewClient will return a new SteamGridDB Client
-func NewClient(apiKey string) *Client {
-	return &Client{
-		apiKey: apiKey,
+// ErrInvalidAPIKey is returned when SteamGridDB rejects the API key
+var ErrInvalidAPIKey = errors.New("SteamGridDB API key is invalid or expired")
+
+// ErrNotFound is returned when the requested SteamGridDB resource, such as a
+// game, does not exist.
+var ErrNotFound = errors.New("not found on SteamGridDB")
+
+// DefaultRateLimit is the default maximum number of requests per second made
+// to the SteamGridDB API, and DefaultRateBurst the number of requests that
+// may be made at once before the limit kicks in.
+const (
+	DefaultRateLimit = 2.0
+	DefaultRateBurst = 4
+)
+
+// Option is a function that configures a Client
+type Option func(c *Client)
+
+// WithConcurrency will return an option that sets the maximum number of
+// requests the client makes at the same time when fetching artwork.
+func WithConcurrency(concurrency int) Option {
+	return func(c *Client) {
+		c.SetConcurrency(concurrency)
+	}
+}
+
+// WithRateLimit will return an option that limits the client to the given
+// number of requests per second.
+func WithRateLimit(rps float64, burst int) Option {
+	return func(c *Client) {
+		c.SetRateLimit(rps, burst)
+	}
+}
+
+// WithUserAgent will return an option that sends the given User-Agent with
+// every request of the client instead of the one of the shared HTTP client.
+func WithUserAgent(ua string) Option {
+	return func(c *Client) {
+		c.userAgent = ua
+	}
+}
+
+// WithBaseURL will return an option that sends the API requests of the
+// client to the given base URL instead of BASE_URL, such as a mirror or a
+// test server.
+func WithBaseURL(baseURL string) Option {
+	return func(c *Client) {
+		c.baseURL = strings.TrimSuffix(baseURL, "/")
 	}
 }
 
+// WithStrategy will return an option that chooses the artwork candidate of
+// each asset type with the given strategy when fetching artwork
+func WithStrategy(strategy Strategy) Option {
+	return func(c *Client) {
+		c.strategy = strategy
+	}
+}
+
+// NewClient will return a new SteamGridDB Client. If the given API key is
+// empty, it is read from the STEAMGRIDDB_API_KEY environment variable. The
+// key is sent as a bearer token with every API request.
+func NewClient(apiKey string, opts ...Option) *Client {
+	if apiKey == "" {
+		apiKey = os.Getenv(APIKeyEnv)
+	}
+	client := &Client{
+		apiKey:  apiKey,
+		limiter: rate.NewLimiter(rate.Limit(DefaultRateLimit), DefaultRateBurst),
+	}
+	for _, opt := range opts {
+		opt(client)
+	}
+	return client
+}
+
 // Client is a structure for querying the SteamGridDB API
 type Client struct {
-	apiKey string
-	client http.Client
+	apiKey      string
+	concurrency int
+	limiter     *rate.Limiter
+	cache       *responseCache
+	userAgent   string
+	baseURL     string
+	strategy    Strategy
+	ctx         context.Context
 }
 
-func (c *Client) debug(str string) {
-	if !isDebug {
+// SetRateLimit will limit the client to the given number of requests per
+// second, allowing bursts of up to the given size. Every request, including
+// downloads, waits for the limiter. A rate of zero or less disables the
+// limit.
+func (c *Client) SetRateLimit(rps float64, burst int) {
+	if rps <= 0 {
+		c.limiter = nil
 		return
 	}
-	fmt.Printf("%s\n", str)
+	if burst < 1 {
+		burst = 1
+	}
+	c.limiter = rate.NewLimiter(rate.Limit(rps), burst)
+}
+
+// SetConcurrency will set the maximum number of requests the client makes at
+// the same time when fetching artwork. A value of zero or less uses
+// steam.DefaultConcurrency.
+func (c *Client) SetConcurrency(concurrency int) {
+	c.concurrency = concurrency
+}
+
+// HasAPIKey will return whether or not the client has an API key set
+func (c *Client) HasAPIKey() bool {
+	return c.apiKey != ""
+}
+
+func (c *Client) getConcurrency() int {
+	if c.concurrency <= 0 {
+		return steam.DefaultConcurrency
+	}
+	return c.concurrency
+}
+
+// WithContext will return a copy of the client whose requests are aborted when
+// the given context is cancelled
+func (c *Client) WithContext(ctx context.Context) *Client {
+	client := *c
+	client.ctx = ctx
+	return &client
+}
+
+// context will return the context requests are made with
+func (c *Client) context() context.Context {
+	if c.ctx == nil {
+		return context.Background()
+	}
+	return c.ctx
+}
+
+func (c *Client) debug(str string) {
+	logger.Debugf("%s", str)
 }
 
 // Get will perform a GET request to the given SteamGridDB API endpoint.
 func (c *Client) Get(path string) (*http.Response, error) {
-	return c.get(getUrl(path), true)
+	return c.get(c.getUrl(path), true)
 }
 
 func (c *Client) get(url string, authenticated bool) (*http.Response, error) {
 	c.debug("GET " + url)
-	req, err := http.NewRequest("GET", url, nil)
+	if httpclient.Offline {
+		return nil, fmt.Errorf("%w: SteamGridDB cannot be reached", httpclient.ErrOffline)
+	}
+	if c.limiter != nil {
+		if err := c.limiter.Wait(c.context()); err != nil {
+			return nil, err
+		}
+	}
+	req, err := http.NewRequestWithContext(c.context(), "GET", url, nil)
 	if err != nil {
 		return nil, err
 	}
 	if authenticated {
 		req.Header.Set("Authorization", "Bearer "+c.apiKey)
 	}
-	res, err := c.client.Do(req)
+	if c.userAgent != "" {
+		req.Header.Set("User-Agent", c.userAgent)
+	}
+	res, err := httpclient.Do(req)
 	if err != nil {
 		return nil, err
 	}
+	if res.StatusCode == http.StatusUnauthorized && authenticated {
+		res.Body.Close()
+		return nil, ErrInvalidAPIKey
+	}
+	if res.StatusCode == http.StatusNotFound {
+		res.Body.Close()
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, url)
+	}
 	if res.StatusCode != 200 {
+		defer res.Body.Close()
 		body, _ := ioutil.ReadAll(res.Body)
 		logger.DebugPrintln(res.StatusCode)
 		logger.DebugPrintln(string(body))
@@ -65,6 +214,21 @@ func (c *Client) get(url string, authenticated bool) (*http.Response, error) {
 	return res, nil
 }
 
+// ValidateKey will perform a lightweight authenticated request to verify that
+// the client's API key is accepted by SteamGridDB. Returns ErrInvalidAPIKey if
+// the key is invalid or expired.
+func (c *Client) ValidateKey() error {
+	if c.apiKey == "" {
+		return ErrInvalidAPIKey
+	}
+	res, err := c.Get("/search/autocomplete/steam")
+	if err != nil {
+		return err
+	}
+	res.Body.Close()
+	return nil
+}
+
 // Download will download the given file to the provided path
 func (c *Client) Download(url, path string) error {
 	// Fetch the file
@@ -85,9 +249,12 @@ func (c *Client) Download(url, path string) error {
 	}
 	defer file.Close()
 
-	// Write the bytes to the file
+	// Write the bytes to the file. A partial download is removed so it is not
+	// mistaken for a cached file later.
 	_, err = io.Copy(file, res.Body)
 	if err != nil {
+		file.Close()
+		os.Remove(path)
 		return err
 	}
 
@@ -104,14 +271,7 @@ func (c *Client) CachedDownload(url, path string) error {
 
 // Search will return a list of search results for the given term
 func (c *Client) Search(term string) (*SearchResponse, error) {
-	res, err := c.Get("/search/autocomplete/" + url.QueryEscape(term))
-	if err != nil {
-		return nil, err
-	}
-	if res.Body != nil {
-		defer res.Body.Close()
-	}
-	body, err := ioutil.ReadAll(res.Body)
+	body, err := c.getJSON("/search/autocomplete/" + url.QueryEscape(term))
 	if err != nil {
 		return nil, err
 	}
@@ -125,16 +285,39 @@ func (c *Client) Search(term string) (*SearchResponse, error) {
 	return &results, nil
 }
 
-// GetGrids will return the results of the grids for a given game ID
-func (c *Client) GetGrids(gameID string, filters ...FilterGrid) (*GridResponse, error) {
-	res, err := c.Get("/grids/game/" + gameID)
+// GetGameBySteamAppID will return the SteamGridDB game for the given Steam
+// app ID. Returns ErrNotFound if SteamGridDB does not know the app.
+func (c *Client) GetGameBySteamAppID(appID uint64) (*Game, error) {
+	return c.GetGameByPlatformID("steam", fmt.Sprintf("%d", appID))
+}
+
+// GetGameByPlatformID will return the SteamGridDB game for the given platform
+// (e.g. "steam", "gog", "origin", "egs") and platform specific game ID.
+// Returns ErrNotFound if SteamGridDB does not know the game.
+func (c *Client) GetGameByPlatformID(platform, id string) (*Game, error) {
+	body, err := c.getJSON("/games/" + url.PathEscape(platform) + "/" + url.PathEscape(id))
 	if err != nil {
 		return nil, err
 	}
-	if res.Body != nil {
-		defer res.Body.Close()
+
+	var results GameResponse
+	err = json.Unmarshal(body, &results)
+	if err != nil {
+		return nil, err
 	}
-	body, err := ioutil.ReadAll(res.Body)
+
+	return &results.Data, nil
+}
+
+// GetGrids will return the results of the grids for a given game ID
+func (c *Client) GetGrids(gameID string, filters ...FilterGrid) (*GridResponse, error) {
+	return c.GetGridsWithQuery(gameID, nil, filters...)
+}
+
+// GetGridsWithQuery will return the results of the grids for a given game ID,
+// filtered by SteamGridDB using the given query filters.
+func (c *Client) GetGridsWithQuery(gameID string, query []QueryFilter, filters ...FilterGrid) (*GridResponse, error) {
+	body, err := c.getJSON(withQuery("/grids/game/"+gameID, query))
 	if err != nil {
 		return nil, err
 	}
@@ -154,16 +337,68 @@ func (c *Client) GetGrids(gameID string, filters ...FilterGrid) (*GridResponse,
 	return response, nil
 }
 
-// GetHeroes will return the results of heroes for a given game ID
-func (c *Client) GetHeroes(gameID string, filters ...FilterHeroes) (*HeroesResponse, error) {
-	res, err := c.Get("/heroes/game/" + gameID)
-	if err != nil {
-		return nil, err
+// AllGrids will return the grids for a given game ID from every page of
+// results. See AllGridsWithQuery.
+func (c *Client) AllGrids(gameID string, filters ...FilterGrid) (*GridResponse, error) {
+	return c.AllGridsWithQuery(gameID, nil, filters...)
+}
+
+// AllGridsWithQuery will page through all of the grids for a given game ID,
+// filtered by SteamGridDB using the given query filters. The result filters
+// are applied to the combined results of every page.
+func (c *Client) AllGridsWithQuery(gameID string, query []QueryFilter, filters ...FilterGrid) (*GridResponse, error) {
+	all := &GridResponse{Data: []GridResponseData{}}
+	for page := 0; ; page++ {
+		pageQuery := append(append([]QueryFilter{}, query...), FilterPage(page))
+		res, err := c.GetGridsWithQuery(gameID, pageQuery)
+		if err != nil {
+			return nil, err
+		}
+		all.Response = res.Response
+		all.Pagination = res.Pagination
+		all.Data = append(all.Data, res.Data...)
+		if len(res.Data) == 0 || !res.HasMore() {
+			break
+		}
 	}
-	if res.Body != nil {
-		defer res.Body.Close()
+
+	// Filter our results
+	for _, filter := range filters {
+		all.Data = filter(all)
 	}
-	body, err := ioutil.ReadAll(res.Body)
+
+	return all, nil
+}
+
+// allImagePages will page through all of the hero, logo or icon results
+// returned by the given lookup and return them combined
+func allImagePages(get func(query []QueryFilter) (*HeroesResponse, error)) (*HeroesResponse, error) {
+	all := &HeroesResponse{Data: []ImageResponseData{}}
+	for page := 0; ; page++ {
+		res, err := get([]QueryFilter{FilterPage(page)})
+		if err != nil {
+			return nil, err
+		}
+		all.Response = res.Response
+		all.Pagination = res.Pagination
+		all.Data = append(all.Data, res.Data...)
+		if len(res.Data) == 0 || !res.HasMore() {
+			break
+		}
+	}
+
+	return all, nil
+}
+
+// GetHeroes will return the results of heroes for a given game ID
+func (c *Client) GetHeroes(gameID string, filters ...FilterHeroes) (*HeroesResponse, error) {
+	return c.GetHeroesWithQuery(gameID, nil, filters...)
+}
+
+// GetHeroesWithQuery will return the results of the heroes for a given game ID,
+// filtered by SteamGridDB using the given query filters.
+func (c *Client) GetHeroesWithQuery(gameID string, query []QueryFilter, filters ...FilterHeroes) (*HeroesResponse, error) {
+	body, err := c.getJSON(withQuery("/heroes/game/"+gameID, query))
 	if err != nil {
 		return nil, err
 	}
@@ -185,14 +420,13 @@ func (c *Client) GetHeroes(gameID string, filters ...FilterHeroes) (*HeroesRespo
 
 // GetLogos will return the results of logos for a given game ID
 func (c *Client) GetLogos(gameID string, filters ...FilterLogos) (*LogosResponse, error) {
-	res, err := c.Get("/logos/game/" + gameID)
-	if err != nil {
-		return nil, err
-	}
-	if res.Body != nil {
-		defer res.Body.Close()
-	}
-	body, err := ioutil.ReadAll(res.Body)
+	return c.GetLogosWithQuery(gameID, nil, filters...)
+}
+
+// GetLogosWithQuery will return the results of the logos for a given game ID,
+// filtered by SteamGridDB using the given query filters.
+func (c *Client) GetLogosWithQuery(gameID string, query []QueryFilter, filters ...FilterLogos) (*LogosResponse, error) {
+	body, err := c.getJSON(withQuery("/logos/game/"+gameID, query))
 	if err != nil {
 		return nil, err
 	}
@@ -215,14 +449,13 @@ func (c *Client) GetLogos(gameID string, filters ...FilterLogos) (*LogosResponse
 
 // GetIcons will return the results of icons for a given game ID
 func (c *Client) GetIcons(gameID string, filters ...FilterIcons) (*IconsResponse, error) {
-	res, err := c.Get("/icons/game/" + gameID)
-	if err != nil {
-		return nil, err
-	}
-	if res.Body != nil {
-		defer res.Body.Close()
-	}
-	body, err := ioutil.ReadAll(res.Body)
+	return c.GetIconsWithQuery(gameID, nil, filters...)
+}
+
+// GetIconsWithQuery will return the results of the icons for a given game ID,
+// filtered by SteamGridDB using the given query filters.
+func (c *Client) GetIconsWithQuery(gameID string, query []QueryFilter, filters ...FilterIcons) (*IconsResponse, error) {
+	body, err := c.getJSON(withQuery("/icons/game/"+gameID, query))
 	if err != nil {
 		return nil, err
 	}
@@ -242,6 +475,10 @@ func (c *Client) GetIcons(gameID string, filters ...FilterIcons) (*IconsResponse
 	return response, nil
 }
 
-func getUrl(path string) string {
+// getUrl will return the URL of the given API path
+func (c *Client) getUrl(path string) string {
+	if c.baseURL != "" {
+		return c.baseURL + path
+	}
 	return fmt.Sprintf("%s%s", BASE_URL, path)
 }
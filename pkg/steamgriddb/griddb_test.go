@@ -0,0 +1,60 @@
+package steamgriddb
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// newTestClient will return a client that sends its requests to a test
+// server using the given handler
+func newTestClient(t *testing.T, apiKey string, handler http.HandlerFunc) *Client {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	return NewClient(apiKey, WithBaseURL(server.URL), WithRateLimit(0, 0))
+}
+
+func TestValidateKeyUnauthorized(t *testing.T) {
+	requests := 0
+	client := newTestClient(t, "expired", func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if got := r.Header.Get("Authorization"); got != "Bearer expired" {
+			t.Errorf("Authorization header = %q, want %q", got, "Bearer expired")
+		}
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"success":false,"errors":["Unauthorized"]}`))
+	})
+
+	err := client.ValidateKey()
+	if !errors.Is(err, ErrInvalidAPIKey) {
+		t.Fatalf("ValidateKey() = %v, want %v", err, ErrInvalidAPIKey)
+	}
+	if err.Error() != "SteamGridDB API key is invalid or expired" {
+		t.Errorf("ValidateKey() error = %q, want the friendly message", err.Error())
+	}
+	if requests != 1 {
+		t.Errorf("ValidateKey() made %d requests, want 1", requests)
+	}
+}
+
+func TestValidateKeyEmpty(t *testing.T) {
+	client := newTestClient(t, "", func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request to %v", r.URL)
+	})
+
+	if err := client.ValidateKey(); !errors.Is(err, ErrInvalidAPIKey) {
+		t.Fatalf("ValidateKey() = %v, want %v", err, ErrInvalidAPIKey)
+	}
+}
+
+func TestValidateKeyAuthorized(t *testing.T) {
+	client := newTestClient(t, "valid", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"success":true,"data":[]}`))
+	})
+
+	if err := client.ValidateKey(); err != nil {
+		t.Fatalf("ValidateKey() = %v, want nil", err)
+	}
+}
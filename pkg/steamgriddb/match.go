@@ -0,0 +1,70 @@
+package steamgriddb
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+
+	"github.com/shadowblip/steam-shortcut-manager/pkg/fsutil"
+)
+
+// MatchCache persists the SteamGridDB game chosen for each shortcut so that
+// repeated artwork applies can skip the (ambiguous) name search.
+type MatchCache struct {
+	path    string
+	Matches map[string]string `json:"matches"`
+}
+
+// DefaultMatchCachePath will return the default location of the match cache
+func DefaultMatchCachePath() (string, error) {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(cacheDir, "steam-shortcut-manager", "matches.json"), nil
+}
+
+// LoadMatchCache will load the match cache from the given path. If the file
+// does not exist, an empty cache is returned.
+func LoadMatchCache(path string) (*MatchCache, error) {
+	cache := &MatchCache{path: path, Matches: map[string]string{}}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return cache, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, cache); err != nil {
+		return nil, err
+	}
+	if cache.Matches == nil {
+		cache.Matches = map[string]string{}
+	}
+
+	return cache, nil
+}
+
+// Get will return the stored SteamGridDB game ID for the given shortcut key
+func (m *MatchCache) Get(key string) (string, bool) {
+	gameID, ok := m.Matches[key]
+	return gameID, ok
+}
+
+// Set will store the SteamGridDB game ID for the given shortcut key
+func (m *MatchCache) Set(key, gameID string) {
+	m.Matches[key] = gameID
+}
+
+// Save will write the match cache to disk
+func (m *MatchCache) Save() error {
+	if err := os.MkdirAll(filepath.Dir(m.path), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	return fsutil.WriteFileAtomic(m.path, data, 0644)
+}
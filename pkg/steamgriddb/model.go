@@ -6,6 +6,19 @@ type Response struct {
 	Errors  []string `json:"errors"`
 }
 
+// Pagination holds the paging information of a SteamGridDB artwork response.
+// Page is zero-based and Limit is the number of results per page.
+type Pagination struct {
+	Page  int `json:"page"`
+	Total int `json:"total"`
+	Limit int `json:"limit"`
+}
+
+// HasMore will return whether or not there are more pages after this one
+func (p Pagination) HasMore() bool {
+	return p.Limit > 0 && (p.Page+1)*p.Limit < p.Total
+}
+
 // 'https://www.steamgriddb.com/api/v2/search/autocomplete/{term}'
 type SearchResponse struct {
 	Response
@@ -13,15 +26,31 @@ type SearchResponse struct {
 }
 
 type SearchResponseData struct {
-	ID       int      `json:"id"`
-	Name     string   `json:"name"`
-	Types    []string `json:"types"`
-	Verified bool     `json:"verified"`
+	ID          int      `json:"id"`
+	Name        string   `json:"name"`
+	ReleaseDate int64    `json:"release_date,omitempty"`
+	Types       []string `json:"types"`
+	Verified    bool     `json:"verified"`
+}
+
+// https://www.steamgriddb.com/api/v2/games/steam/{steamAppId}
+type GameResponse struct {
+	Response
+	Data Game `json:"data"`
+}
+
+type Game struct {
+	ID          int      `json:"id"`
+	Name        string   `json:"name"`
+	ReleaseDate int64    `json:"release_date"`
+	Types       []string `json:"types"`
+	Verified    bool     `json:"verified"`
 }
 
 // https://www.steamgriddb.com/api/v2/grids/game/{gameId}
 type GridResponse struct {
 	Response
+	Pagination
 	Data []GridResponseData `json:"data"`
 }
 
@@ -52,17 +81,23 @@ type GridResponseData struct {
 // https://www.steamgriddb.com/api/v2/heroes/game/{gameId}
 type HeroesResponse struct {
 	Response
+	Pagination
 	Data []ImageResponseData `json:"data"`
 }
 
 type ImageResponseData struct {
-	ID     int      `json:"id"`
-	Score  int      `json:"score"`
-	Style  string   `json:"style"`
-	URL    string   `json:"url"`
-	Thumb  string   `json:"thumb"`
-	Tags   []string `json:"tags"`
-	Author struct {
+	ID        int      `json:"id"`
+	Score     int      `json:"score"`
+	Style     string   `json:"style"`
+	Width     int      `json:"width"`
+	Height    int      `json:"height"`
+	Mime      string   `json:"mime"`
+	URL       string   `json:"url"`
+	Thumb     string   `json:"thumb"`
+	Tags      []string `json:"tags"`
+	Upvotes   int      `json:"upvotes"`
+	Downvotes int      `json:"downvotes"`
+	Author    struct {
 		Name    string `json:"name"`
 		Steam64 string `json:"steam64"`
 		Avatar  string `json:"avatar"`
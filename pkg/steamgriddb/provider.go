@@ -0,0 +1,82 @@
+package steamgriddb
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/shadowblip/steam-shortcut-manager/pkg/logger"
+	"github.com/shadowblip/steam-shortcut-manager/pkg/steam"
+)
+
+// Provider finds artwork on SteamGridDB. It implements steam.ArtworkProvider.
+type Provider struct {
+	client *Client
+}
+
+// Provider will return a steam.ArtworkProvider that finds artwork on
+// SteamGridDB using this client
+func (c *Client) Provider() *Provider {
+	return &Provider{client: c}
+}
+
+// ArtworkProvider will return the provider chain used to find artwork. It
+// asks SteamGridDB first, then falls back to the Steam CDN for real Steam
+// games.
+func (c *Client) ArtworkProvider() steam.ArtworkProvider {
+	return steam.NewChainProvider(c.Provider(), &steam.CDNProvider{Context: c.context()})
+}
+
+// FetchArtworkConfig will return the SteamGridDB artwork of the game matching
+// the given query. The game is looked up by its Steam app ID if set, and
+// searched for by name otherwise. Returns steam.ErrNoArtwork if no game is
+// found or the game has no artwork, and the lookup errors if none of the
+// artwork could be fetched.
+func (p *Provider) FetchArtworkConfig(query steam.ArtworkQuery) (*steam.ArtworkConfig, error) {
+	gameID, err := p.findGame(query)
+	if err != nil {
+		return nil, err
+	}
+
+	config, err := p.client.FetchArtworkConfig(gameID)
+	if config == nil {
+		return nil, err
+	}
+	if *config == (steam.ArtworkConfig{}) {
+		if err != nil {
+			return nil, err
+		}
+		return nil, steam.ErrNoArtwork
+	}
+	// The asset types whose lookup failed are left for the next provider
+	if err != nil {
+		logger.Warningf("Some SteamGridDB artwork could not be fetched: %v", err)
+	}
+	return config, nil
+}
+
+// findGame will return the SteamGridDB game ID of the game matching the given
+// query
+func (p *Provider) findGame(query steam.ArtworkQuery) (string, error) {
+	if query.SteamAppID != 0 {
+		game, err := p.client.GetGameBySteamAppID(query.SteamAppID)
+		if err == nil {
+			return fmt.Sprintf("%d", game.ID), nil
+		}
+		if !errors.Is(err, ErrNotFound) {
+			return "", err
+		}
+	}
+	if query.Name == "" {
+		return "", steam.ErrNoArtwork
+	}
+
+	results, err := p.client.Search(query.Name)
+	if err != nil {
+		return "", fmt.Errorf("failed to search for game: %w", err)
+	}
+	if len(results.Data) == 0 {
+		return "", fmt.Errorf("%w: no games found for '%s'", steam.ErrNoArtwork, query.Name)
+	}
+
+	return fmt.Sprintf("%d", results.Data[0].ID), nil
+}